@@ -0,0 +1,75 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/ch00z00/kotobalize/evaluation"
+	"github.com/ch00z00/kotobalize/services"
+)
+
+// runEval implements the `eval` subcommand. It reviews the golden set with the reviewer
+// configured by the AI_REVIEWER and OPENAI_* environment variables and prints a report.
+//
+// The exit status is 1 when a review fails or is rejected by validation, or, with -strict,
+// when a score falls outside its expected band. Usage errors exit with 2.
+func runEval(args []string) int {
+	fs := flag.NewFlagSet("eval", flag.ContinueOnError)
+	goldenPath := fs.String("golden", "", "Path to a golden set JSON file. Defaults to the golden set built into the binary.")
+	runs := fs.Int("runs", evaluation.DefaultRuns, "Number of times each fixture is reviewed.")
+	timeout := fs.Duration("timeout", 2*time.Minute, "Timeout of each review call.")
+	asJSON := fs.Bool("json", false, "Print the report as JSON.")
+	strict := fs.Bool("strict", false, "Exit with status 1 when any score is outside its expected band.")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	var set *evaluation.GoldenSet
+	var err error
+	if *goldenPath != "" {
+		set, err = evaluation.LoadGoldenSet(*goldenPath)
+	} else {
+		set, err = evaluation.DefaultGoldenSet()
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to load golden set: %v\n", err)
+		return 2
+	}
+
+	reviewer, err := services.NewReviewer(services.LoadReviewerConfig())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to create reviewer: %v\n", err)
+		return 2
+	}
+
+	// The built-in rubric is used so that the command does not need a database.
+	report, err := evaluation.Run(context.Background(), reviewer, set, evaluation.Options{
+		Runs:    *runs,
+		Rubric:  services.DefaultRubric(),
+		Timeout: *timeout,
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "evaluation failed: %v\n", err)
+		return 2
+	}
+
+	if *asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(report); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to write report: %v\n", err)
+			return 2
+		}
+	} else {
+		fmt.Print(report)
+	}
+
+	if len(report.Failures) > 0 || (*strict && len(report.Violations) > 0) {
+		return 1
+	}
+	return 0
+}
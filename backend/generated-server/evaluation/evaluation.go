@@ -0,0 +1,257 @@
+// Package evaluation measures how a Reviewer scores a golden set of writings,
+// so that changes to the prompt, the rubric or the model can be compared before release.
+package evaluation
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"math"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/ch00z00/kotobalize/services"
+)
+
+// DefaultRuns is how many times each fixture is reviewed when Options.Runs is not set.
+const DefaultRuns = 3
+
+// Options control an evaluation run.
+type Options struct {
+	// Runs is how many times each fixture is reviewed. Variance needs at least two.
+	Runs   int
+	Rubric services.Rubric
+	// Timeout limits each review call. Zero means no limit.
+	Timeout time.Duration
+}
+
+// Run reviews every fixture of the golden set opts.Runs times and compares the scores
+// with the expectations. Each accepted output is parsed and validated again with
+// services.ParseAIReview, so an output the application would reject counts as a failure.
+//
+// Reviewer errors and invalid outputs are recorded in the report; Run only returns an
+// error when the golden set does not fit the rubric or ctx is cancelled.
+func Run(ctx context.Context, reviewer services.Reviewer, set *GoldenSet, opts Options) (*Report, error) {
+	if opts.Runs <= 0 {
+		opts.Runs = DefaultRuns
+	}
+	if err := set.checkRubric(opts.Rubric); err != nil {
+		return nil, err
+	}
+
+	report := &Report{
+		GoldenVersion: set.Version,
+		PromptVersion: opts.Rubric.PromptVersion(),
+		Runs:          opts.Runs,
+		Fixtures:      len(set.Fixtures),
+		Violations:    []BandViolation{},
+		Failures:      []Failure{},
+	}
+	stats := make(map[string]*viewpointStats)
+
+	for _, fixture := range set.Fixtures {
+		input := fixture.reviewInput(opts.Rubric)
+		// scores[key] holds the score of each successful run, for the variance.
+		scores := make(map[string][]int, len(fixture.Expected))
+
+		for run := 1; run <= opts.Runs; run++ {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+			review, model, problems := reviewOnce(ctx, reviewer, input, opts.Timeout)
+			if len(problems) > 0 {
+				report.Failures = append(report.Failures, Failure{FixtureID: fixture.ID, Run: run, Problems: problems})
+				continue
+			}
+			report.Reviews++
+			if report.Model == "" {
+				report.Model = model
+			}
+
+			for _, key := range slices.Sorted(maps.Keys(fixture.Expected)) {
+				expected := fixture.Expected[key]
+				score := review.TotalScore
+				if key != TotalKey {
+					score = review.Scores[key]
+				}
+				scores[key] = append(scores[key], score)
+
+				s := stats[key]
+				if s == nil {
+					s = &viewpointStats{}
+					stats[key] = s
+				}
+				s.absErrorSum += math.Abs(float64(score - expected.Score))
+				s.samples++
+				if score < expected.Min || score > expected.Max {
+					s.violations++
+					report.Violations = append(report.Violations, BandViolation{
+						FixtureID: fixture.ID,
+						Run:       run,
+						Viewpoint: key,
+						Score:     score,
+						Min:       expected.Min,
+						Max:       expected.Max,
+					})
+				}
+			}
+		}
+
+		for key, runs := range scores {
+			if len(runs) < 2 {
+				continue
+			}
+			v := variance(runs)
+			s := stats[key]
+			s.varianceSum += v
+			s.varianceFixtures++
+			s.maxStdDev = max(s.maxStdDev, math.Sqrt(v))
+		}
+	}
+
+	report.Viewpoints = make([]ViewpointReport, 0, len(stats))
+	for _, key := range reportOrder(opts.Rubric, stats) {
+		s := stats[key]
+		vr := ViewpointReport{
+			Key:        key,
+			Samples:    s.samples,
+			MAE:        s.absErrorSum / float64(s.samples),
+			Violations: s.violations,
+			MaxStdDev:  s.maxStdDev,
+		}
+		if s.varianceFixtures > 0 {
+			vr.MeanVariance = s.varianceSum / float64(s.varianceFixtures)
+		}
+		report.Viewpoints = append(report.Viewpoints, vr)
+	}
+	return report, nil
+}
+
+// reviewOnce reviews the input and re-validates the raw output.
+// It returns the problems instead of the review when either step fails.
+func reviewOnce(ctx context.Context, reviewer services.Reviewer, input services.ReviewInput, timeout time.Duration) (*services.AIReviewResponse, string, []string) {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	result, err := reviewer.GetAIReview(ctx, input)
+	if err != nil {
+		return nil, "", []string{fmt.Sprintf("reviewer error: %v", err)}
+	}
+	review, problems := services.ParseAIReview(result.Raw, input)
+	if len(problems) > 0 {
+		return nil, "", problems
+	}
+	return review, result.Model, nil
+}
+
+// viewpointStats accumulates the measurements of one viewpoint across fixtures and runs.
+type viewpointStats struct {
+	samples          int
+	absErrorSum      float64
+	violations       int
+	varianceSum      float64
+	varianceFixtures int
+	maxStdDev        float64
+}
+
+// variance returns the population variance of the scores.
+func variance(scores []int) float64 {
+	var sum float64
+	for _, s := range scores {
+		sum += float64(s)
+	}
+	mean := sum / float64(len(scores))
+	var squares float64
+	for _, s := range scores {
+		squares += (float64(s) - mean) * (float64(s) - mean)
+	}
+	return squares / float64(len(scores))
+}
+
+// reportOrder lists the measured viewpoints in rubric order, followed by the total.
+func reportOrder(rubric services.Rubric, stats map[string]*viewpointStats) []string {
+	keys := make([]string, 0, len(stats))
+	for _, vp := range rubric.Viewpoints {
+		if _, ok := stats[vp.Key]; ok {
+			keys = append(keys, vp.Key)
+		}
+	}
+	if _, ok := stats[TotalKey]; ok {
+		keys = append(keys, TotalKey)
+	}
+	return keys
+}
+
+// Report summarizes an evaluation run.
+type Report struct {
+	GoldenVersion int    `json:"goldenVersion"`
+	PromptVersion string `json:"promptVersion"`
+	// Model is the model reported by the first successful review.
+	Model    string `json:"model"`
+	Runs     int    `json:"runs"`
+	Fixtures int    `json:"fixtures"`
+	// Reviews counts the reviews that succeeded and passed validation.
+	Reviews    int               `json:"reviews"`
+	Viewpoints []ViewpointReport `json:"viewpoints"`
+	Violations []BandViolation   `json:"violations"`
+	Failures   []Failure         `json:"failures"`
+}
+
+// ViewpointReport holds the measurements of one viewpoint, or of the total score.
+type ViewpointReport struct {
+	Key     string `json:"key"`
+	Samples int    `json:"samples"`
+	// MAE is the mean absolute error from the expected score over all runs.
+	MAE        float64 `json:"mae"`
+	Violations int     `json:"violations"`
+	// MeanVariance is the variance of a fixture's score across runs, averaged over fixtures.
+	MeanVariance float64 `json:"meanVariance"`
+	MaxStdDev    float64 `json:"maxStdDev"`
+}
+
+// BandViolation is a score outside the expected band.
+type BandViolation struct {
+	FixtureID string `json:"fixtureId"`
+	Run       int    `json:"run"`
+	Viewpoint string `json:"viewpoint"`
+	Score     int    `json:"score"`
+	Min       int    `json:"min"`
+	Max       int    `json:"max"`
+}
+
+// Failure is a run whose review failed or did not pass validation.
+type Failure struct {
+	FixtureID string   `json:"fixtureId"`
+	Run       int      `json:"run"`
+	Problems  []string `json:"problems"`
+}
+
+// String formats the report as a plain-text summary for the terminal.
+func (r *Report) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "golden set v%d, rubric %s, model %s\n", r.GoldenVersion, r.PromptVersion, r.Model)
+	fmt.Fprintf(&b, "%d fixtures x %d runs: %d reviews, %d failures, %d band violations\n\n",
+		r.Fixtures, r.Runs, r.Reviews, len(r.Failures), len(r.Violations))
+
+	fmt.Fprintf(&b, "%-12s %8s %8s %10s %10s %10s\n", "viewpoint", "samples", "MAE", "violations", "variance", "max sd")
+	for _, vp := range r.Viewpoints {
+		fmt.Fprintf(&b, "%-12s %8d %8.2f %10d %10.2f %10.2f\n", vp.Key, vp.Samples, vp.MAE, vp.Violations, vp.MeanVariance, vp.MaxStdDev)
+	}
+
+	if len(r.Violations) > 0 {
+		b.WriteString("\nband violations:\n")
+		for _, v := range r.Violations {
+			fmt.Fprintf(&b, "  %s run %d %s: %d not in [%d, %d]\n", v.FixtureID, v.Run, v.Viewpoint, v.Score, v.Min, v.Max)
+		}
+	}
+	if len(r.Failures) > 0 {
+		b.WriteString("\nfailures:\n")
+		for _, f := range r.Failures {
+			fmt.Fprintf(&b, "  %s run %d: %s\n", f.FixtureID, f.Run, strings.Join(f.Problems, "; "))
+		}
+	}
+	return b.String()
+}
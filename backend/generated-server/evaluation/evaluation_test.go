@@ -0,0 +1,138 @@
+package evaluation
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/ch00z00/kotobalize/services"
+)
+
+func TestDefaultGoldenSetFitsDefaultRubric(t *testing.T) {
+	set, err := DefaultGoldenSet()
+	if err != nil {
+		t.Fatalf("DefaultGoldenSet: %v", err)
+	}
+	if err := set.checkRubric(services.DefaultRubric()); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRunWithFakeReviewer(t *testing.T) {
+	set, err := DefaultGoldenSet()
+	if err != nil {
+		t.Fatalf("DefaultGoldenSet: %v", err)
+	}
+
+	report, err := Run(context.Background(), &services.FakeReviewer{}, set, Options{Runs: 2, Rubric: services.DefaultRubric()})
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+
+	// Every output of the fake reviewer must survive parsing and validation.
+	if len(report.Failures) > 0 {
+		t.Fatalf("unexpected failures:\n%s", report)
+	}
+	if want := 2 * len(set.Fixtures); report.Reviews != want {
+		t.Errorf("Reviews = %d, want %d", report.Reviews, want)
+	}
+	if report.Model != services.FakeModelName {
+		t.Errorf("Model = %q, want %q", report.Model, services.FakeModelName)
+	}
+
+	var total *ViewpointReport
+	for i, vp := range report.Viewpoints {
+		// The fake reviewer is deterministic, so repeated runs must agree.
+		if vp.MeanVariance != 0 || vp.MaxStdDev != 0 {
+			t.Errorf("%s: variance %.2f, max sd %.2f, want 0", vp.Key, vp.MeanVariance, vp.MaxStdDev)
+		}
+		if vp.Key == TotalKey {
+			total = &report.Viewpoints[i]
+		}
+	}
+	if total == nil {
+		t.Fatal("report has no total score")
+	}
+	if want := 2 * len(set.Fixtures); total.Samples != want {
+		t.Errorf("total samples = %d, want %d", total.Samples, want)
+	}
+	if last := report.Viewpoints[len(report.Viewpoints)-1]; last.Key != TotalKey {
+		t.Errorf("last viewpoint = %q, want %q", last.Key, TotalKey)
+	}
+}
+
+func TestRunMeasuresScores(t *testing.T) {
+	rubric := services.DefaultRubric()
+	fixture := Fixture{
+		ID:         "measured",
+		ThemeTitle: "テーマ",
+		Content:    "キャッシュは読み込みを速くします。",
+	}
+	result, err := (&services.FakeReviewer{}).GetAIReview(context.Background(), fixture.reviewInput(rubric))
+	if err != nil {
+		t.Fatalf("GetAIReview: %v", err)
+	}
+	got := result.Review.Scores["structure"]
+
+	// One band the score misses by 10 and an expectation it meets exactly.
+	fixture.Expected = map[string]Expectation{
+		"structure": {Score: got + 10, Min: got + 5, Max: got + 10},
+		TotalKey:    {Score: result.Review.TotalScore, Min: 0, Max: 100},
+	}
+	report, err := Run(context.Background(), &services.FakeReviewer{}, &GoldenSet{Version: 1, Fixtures: []Fixture{fixture}}, Options{Runs: 3, Rubric: rubric})
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+
+	if len(report.Violations) != 3 {
+		t.Fatalf("violations = %d, want 3:\n%s", len(report.Violations), report)
+	}
+	for _, vp := range report.Viewpoints {
+		want := 0.0
+		if vp.Key == "structure" {
+			want = 10
+		}
+		if vp.MAE != want {
+			t.Errorf("%s: MAE = %.2f, want %.2f", vp.Key, vp.MAE, want)
+		}
+	}
+}
+
+// invalidReviewer returns a review whose structure score is out of range.
+type invalidReviewer struct {
+	services.FakeReviewer
+}
+
+func (r *invalidReviewer) GetAIReview(ctx context.Context, input services.ReviewInput) (*services.ReviewResult, error) {
+	result, err := r.FakeReviewer.GetAIReview(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	result.Raw = strings.Replace(result.Raw, `"structure":`, `"structure":150,"ignored":`, 1)
+	return result, nil
+}
+
+func TestRunReportsInvalidOutput(t *testing.T) {
+	set, err := DefaultGoldenSet()
+	if err != nil {
+		t.Fatalf("DefaultGoldenSet: %v", err)
+	}
+
+	report, err := Run(context.Background(), &invalidReviewer{}, set, Options{Runs: 1, Rubric: services.DefaultRubric()})
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if len(report.Failures) != len(set.Fixtures) || report.Reviews != 0 {
+		t.Fatalf("failures = %d, reviews = %d, want %d failures and no reviews", len(report.Failures), report.Reviews, len(set.Fixtures))
+	}
+	if problems := strings.Join(report.Failures[0].Problems, "; "); !strings.Contains(problems, "scores.structure") {
+		t.Errorf("problems = %q, want a problem with scores.structure", problems)
+	}
+}
+
+func TestParseGoldenSetRejectsInvalidBands(t *testing.T) {
+	_, err := ParseGoldenSet([]byte(`{"version":1,"fixtures":[{"id":"a","themeTitle":"t","content":"c","expected":{"total":{"score":90,"min":0,"max":80}}}]}`))
+	if err == nil {
+		t.Fatal("expected an error for a score outside its band")
+	}
+}
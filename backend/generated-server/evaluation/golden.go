@@ -0,0 +1,124 @@
+package evaluation
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ch00z00/kotobalize/services"
+)
+
+// defaultGoldenSet is the golden set shipped with the repository.
+// Bump its version whenever a fixture or an expectation changes, so that
+// reports made against different sets are not compared by mistake.
+//
+//go:embed testdata/golden_v1.json
+var defaultGoldenSet []byte
+
+// TotalKey is the viewpoint key used for the total score in expectations and reports.
+const TotalKey = "total"
+
+// GoldenSet is a versioned list of writings with the scores a good reviewer should give them.
+type GoldenSet struct {
+	Version  int       `json:"version"`
+	Fixtures []Fixture `json:"fixtures"`
+}
+
+// Fixture is one writing of the golden set.
+type Fixture struct {
+	ID               string   `json:"id"`
+	ThemeTitle       string   `json:"themeTitle"`
+	ThemeDescription string   `json:"themeDescription"`
+	KeyPoints        []string `json:"keyPoints,omitempty"`
+	Misconceptions   []string `json:"misconceptions,omitempty"`
+	Content          string   `json:"content"`
+	// Expected maps a rubric viewpoint key, or TotalKey, to the expected score.
+	// Viewpoints without an expectation are reviewed but not measured.
+	Expected map[string]Expectation `json:"expected"`
+}
+
+// Expectation is the expected score of one viewpoint and the band of scores that are acceptable.
+type Expectation struct {
+	Score int `json:"score"`
+	Min   int `json:"min"`
+	Max   int `json:"max"`
+}
+
+// DefaultGoldenSet parses the golden set shipped with the repository.
+func DefaultGoldenSet() (*GoldenSet, error) {
+	return ParseGoldenSet(defaultGoldenSet)
+}
+
+// LoadGoldenSet reads and parses a golden set file.
+func LoadGoldenSet(path string) (*GoldenSet, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseGoldenSet(data)
+}
+
+// ParseGoldenSet parses a golden set and checks that it is well formed.
+func ParseGoldenSet(data []byte) (*GoldenSet, error) {
+	var set GoldenSet
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("golden set is not valid JSON: %w", err)
+	}
+	if set.Version <= 0 {
+		return nil, fmt.Errorf("golden set version must be positive, got %d", set.Version)
+	}
+	if len(set.Fixtures) == 0 {
+		return nil, fmt.Errorf("golden set has no fixtures")
+	}
+	seen := make(map[string]bool, len(set.Fixtures))
+	for i, f := range set.Fixtures {
+		if f.ID == "" {
+			return nil, fmt.Errorf("fixtures[%d].id is required", i)
+		}
+		if seen[f.ID] {
+			return nil, fmt.Errorf("fixture %q appears more than once", f.ID)
+		}
+		seen[f.ID] = true
+		if strings.TrimSpace(f.ThemeTitle) == "" || strings.TrimSpace(f.Content) == "" {
+			return nil, fmt.Errorf("fixture %q needs a themeTitle and content", f.ID)
+		}
+		if len(f.Expected) == 0 {
+			return nil, fmt.Errorf("fixture %q has no expected scores", f.ID)
+		}
+		for key, e := range f.Expected {
+			if e.Min < 0 || e.Max > 100 || e.Min > e.Score || e.Score > e.Max {
+				return nil, fmt.Errorf("fixture %q: expected.%s must satisfy 0 <= min <= score <= max <= 100", f.ID, key)
+			}
+		}
+	}
+	return &set, nil
+}
+
+// checkRubric reports expectations for viewpoints the rubric does not have.
+func (s *GoldenSet) checkRubric(rubric services.Rubric) error {
+	for _, f := range s.Fixtures {
+		for key := range f.Expected {
+			if key == TotalKey {
+				continue
+			}
+			if vp, ok := rubric.Viewpoint(key); !ok || vp.Key != key {
+				return fmt.Errorf("fixture %q expects a score for %q, which is not a viewpoint of rubric %s", f.ID, key, rubric.VersionName())
+			}
+		}
+	}
+	return nil
+}
+
+// reviewInput builds the reviewer input for a fixture.
+func (f Fixture) reviewInput(rubric services.Rubric) services.ReviewInput {
+	return services.ReviewInput{
+		ThemeTitle:       f.ThemeTitle,
+		ThemeDescription: f.ThemeDescription,
+		Content:          f.Content,
+		Rubric:           rubric,
+		KeyPoints:        f.KeyPoints,
+		Misconceptions:   f.Misconceptions,
+	}
+}
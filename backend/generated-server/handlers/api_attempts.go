@@ -0,0 +1,217 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"unicode/utf8"
+
+	"github.com/ch00z00/kotobalize/models"
+	"github.com/ch00z00/kotobalize/services"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// ListThemeAttempts - List the authenticated user's writings on a theme, oldest first
+func (c *Container) ListThemeAttempts(ctx *gin.Context) {
+	// Get themeId from path parameter
+	themeIDStr := ctx.Param("themeId")
+	themeID, err := strconv.ParseUint(themeIDStr, 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, models.APIError{Code: "INVALID_INPUT", Message: "Invalid theme ID format"})
+		return
+	}
+
+	// Get user ID from the context (set by the auth middleware)
+	userID, exists := ctx.Get("userId")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, models.APIError{Code: "UNAUTHORIZED", Message: "User ID not found in token"})
+		return
+	}
+
+	gormWritings, ok := c.findThemeAttempts(ctx, uint(themeID), userID.(uint))
+	if !ok {
+		return
+	}
+
+	attempts := make([]models.Attempt, len(gormWritings))
+	for i, w := range gormWritings {
+		attempts[i] = mapGormWritingToAttempt(w, i+1)
+	}
+
+	ctx.JSON(http.StatusOK, attempts)
+}
+
+// CompareThemeAttempts - Compare two of the authenticated user's writings on a theme
+//
+// The base and target query parameters are writing IDs. With summary=true, the AI also
+// describes what improved and what regressed, which counts towards the AI quota.
+func (c *Container) CompareThemeAttempts(ctx *gin.Context) {
+	// Get themeId from path parameter
+	themeIDStr := ctx.Param("themeId")
+	themeID, err := strconv.ParseUint(themeIDStr, 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, models.APIError{Code: "INVALID_INPUT", Message: "Invalid theme ID format"})
+		return
+	}
+	baseID, baseErr := strconv.ParseUint(ctx.Query("base"), 10, 64)
+	targetID, targetErr := strconv.ParseUint(ctx.Query("target"), 10, 64)
+	if baseErr != nil || targetErr != nil {
+		ctx.JSON(http.StatusBadRequest, models.APIError{Code: "INVALID_INPUT", Message: "base and target must be writing IDs"})
+		return
+	}
+	if baseID == targetID {
+		ctx.JSON(http.StatusBadRequest, models.APIError{Code: "INVALID_INPUT", Message: "base and target must be different writings"})
+		return
+	}
+	withSummary := ctx.Query("summary") == "true"
+
+	// Get user ID from the context (set by the auth middleware)
+	userID, exists := ctx.Get("userId")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, models.APIError{Code: "UNAUTHORIZED", Message: "User ID not found in token"})
+		return
+	}
+
+	gormWritings, ok := c.findThemeAttempts(ctx, uint(themeID), userID.(uint))
+	if !ok {
+		return
+	}
+
+	// Both writings must be attempts on this theme by the authenticated user.
+	var base, target *models.GormWriting
+	var baseNumber, targetNumber int
+	for i := range gormWritings {
+		switch uint64(gormWritings[i].ID) {
+		case baseID:
+			base, baseNumber = &gormWritings[i], i+1
+		case targetID:
+			target, targetNumber = &gormWritings[i], i+1
+		}
+	}
+	if base == nil || target == nil {
+		ctx.JSON(http.StatusNotFound, models.APIError{Code: "WRITING_NOT_FOUND", Message: "Writing not found on this theme or you don't have permission to view it"})
+		return
+	}
+
+	baseAttempt := services.Attempt{Content: base.Content, Review: decodeWritingReview(*base)}
+	targetAttempt := services.Attempt{Content: target.Content, Review: decodeWritingReview(*target)}
+
+	// Viewpoints are named and ordered by the theme's current rubric.
+	input, err := c.reviewInput(target)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, models.APIError{Code: "DATABASE_ERROR", Message: err.Error()})
+		return
+	}
+
+	comparison := models.AttemptComparison{
+		ThemeID:    int64(themeID),
+		Base:       mapGormWritingToAttempt(*base, baseNumber),
+		Target:     mapGormWritingToAttempt(*target, targetNumber),
+		Viewpoints: []models.ViewpointDelta{},
+		Diff:       []models.DiffSegment{},
+	}
+	if base.AIScore != nil && target.AIScore != nil {
+		delta := *target.AIScore - *base.AIScore
+		comparison.ScoreDelta = &delta
+	}
+	for _, d := range services.ViewpointDeltas(baseAttempt.Review, targetAttempt.Review, input.Rubric) {
+		comparison.Viewpoints = append(comparison.Viewpoints, models.ViewpointDelta{
+			Key:    d.Key,
+			Name:   d.Name,
+			Base:   d.Before,
+			Target: d.After,
+			Delta:  d.Delta,
+		})
+	}
+	for _, segment := range services.DiffSentences(base.Content, target.Content) {
+		comparison.Diff = append(comparison.Diff, models.DiffSegment{Op: segment.Op, Text: segment.Text})
+	}
+
+	if withSummary {
+		if err := c.checkQuota(userID.(uint)); err != nil {
+			status, apiErr := aiErrorResponse(err)
+			ctx.JSON(status, apiErr)
+			return
+		}
+		result, err := c.Reviewer.CompareAttempts(ctx.Request.Context(), input, baseAttempt, targetAttempt)
+		if err != nil {
+			c.recordFailedAIUsage(userID.(uint), models.AIUsageKindCompare, err)
+			status, apiErr := aiErrorResponse(err)
+			ctx.JSON(status, apiErr)
+			return
+		}
+		// The summary is only returned once its tokens are charged, so that it cannot be had for free.
+		err = c.DB.Transaction(func(tx *gorm.DB) error {
+			return recordAIUsage(tx, userID.(uint), models.AIUsageKindCompare, result.Model, result.Usage, nil)
+		})
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, models.APIError{Code: "DATABASE_ERROR", Message: "Failed to record AI usage"})
+			return
+		}
+		comparison.Summary = &models.ComparisonSummary{
+			Summary:   result.Summary.Summary,
+			Improved:  result.Summary.Improved,
+			Regressed: result.Summary.Regressed,
+			Model:     result.Model,
+		}
+	}
+
+	ctx.JSON(http.StatusOK, comparison)
+}
+
+// findThemeAttempts loads the user's submitted writings on a theme visible to them, oldest
+// first, with the Theme preloaded. It writes an error response and returns false if that fails.
+func (c *Container) findThemeAttempts(ctx *gin.Context, themeID, userID uint) ([]models.GormWriting, bool) {
+	var gormTheme models.GormTheme
+	if err := c.DB.Where("id = ? AND (creator_id IS NULL OR creator_id = ?)", themeID, userID).First(&gormTheme).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			ctx.JSON(http.StatusNotFound, models.APIError{Code: "THEME_NOT_FOUND", Message: "Theme not found or you don't have permission to view it"})
+			return nil, false
+		}
+		ctx.JSON(http.StatusInternalServerError, models.APIError{Code: "DATABASE_ERROR", Message: "Failed to fetch theme"})
+		return nil, false
+	}
+
+	var gormWritings []models.GormWriting
+	if err := c.DB.Where("user_id = ? AND theme_id = ? AND status = ?", userID, themeID, models.WritingStatusSubmitted).Order("created_at asc, id asc").Find(&gormWritings).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, models.APIError{Code: "DATABASE_ERROR", Message: "Failed to fetch writings"})
+		return nil, false
+	}
+	for i := range gormWritings {
+		gormWritings[i].Theme = gormTheme
+	}
+	return gormWritings, true
+}
+
+// decodeWritingReview decodes the review stored in a writing's AIFeedback, or returns nil.
+func decodeWritingReview(gormWriting models.GormWriting) *services.AIReviewResponse {
+	if len(gormWriting.AIFeedback) == 0 {
+		return nil
+	}
+	var review services.AIReviewResponse
+	if err := json.Unmarshal(gormWriting.AIFeedback, &review); err != nil {
+		log.Printf("failed to decode feedback of writing %d: %v", gormWriting.ID, err)
+		return nil
+	}
+	return &review
+}
+
+// mapGormWritingToAttempt converts a GORM writing model to an API attempt model.
+func mapGormWritingToAttempt(gormWriting models.GormWriting, number int) models.Attempt {
+	attempt := models.Attempt{
+		WritingID:       int64(gormWriting.ID),
+		AttemptNumber:   number,
+		AiScore:         gormWriting.AIScore,
+		LatestReviewID:  gormWriting.LatestReviewID,
+		DurationSeconds: int32(gormWriting.DurationSeconds),
+		CharacterCount:  utf8.RuneCountInString(gormWriting.Content),
+		CreatedAt:       gormWriting.CreatedAt,
+	}
+	if review := decodeWritingReview(gormWriting); review != nil {
+		attempt.Scores = review.Scores
+	}
+	return attempt
+}
@@ -0,0 +1,184 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/ch00z00/kotobalize/models"
+	"github.com/ch00z00/kotobalize/services"
+	"gorm.io/gorm"
+)
+
+const compareRoute = "/themes/:themeId/attempts/compare"
+
+// createReviewedAttempt inserts a submitted writing with a review of the given scores.
+func createReviewedAttempt(t *testing.T, db *gorm.DB, userID, themeID uint, content string, createdAt time.Time, scores map[string]int) models.GormWriting {
+	t.Helper()
+	review := services.AIReviewResponse{Scores: scores}
+	for _, score := range scores {
+		review.TotalScore += score
+	}
+	review.TotalScore /= len(scores)
+	feedback, err := json.Marshal(review)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writing := models.GormWriting{UserID: userID, ThemeID: themeID, Content: content, Status: models.WritingStatusSubmitted,
+		AIScore: &review.TotalScore, AIFeedback: feedback}
+	writing.CreatedAt = createdAt
+	mustCreate(t, db, &writing)
+	return writing
+}
+
+// comparePath is the path to compare two writings on a theme.
+func comparePath(themeID, base, target uint, query string) string {
+	return fmt.Sprintf("/themes/%d/attempts/compare?base=%d&target=%d%s", themeID, base, target, query)
+}
+
+func TestListThemeAttempts(t *testing.T) {
+	c := newTestContainer(t)
+	alice := createTestUser(t, c.DB, "alice")
+	bob := createTestUser(t, c.DB, "bob")
+	theme := createTestTheme(t, c.DB, 600)
+
+	at := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	second := createReviewedAttempt(t, c.DB, alice.ID, theme.ID, "二回目", at.Add(time.Hour), map[string]int{"structure": 70})
+	first := createReviewedAttempt(t, c.DB, alice.ID, theme.ID, "一回目", at, map[string]int{"structure": 50})
+	// Neither drafts nor other users' writings are attempts.
+	mustCreate(t, c.DB, &models.GormWriting{UserID: alice.ID, ThemeID: theme.ID, Content: "下書き", Status: models.WritingStatusDraft})
+	createTestWriting(t, c.DB, bob.ID, theme.ID, "ボブの文章")
+
+	path := fmt.Sprintf("/themes/%d/attempts", theme.ID)
+	attempts := decodeResponse[[]models.Attempt](t, serve(t, c.ListThemeAttempts, http.MethodGet, "/themes/:themeId/attempts", path, alice.ID, nil), http.StatusOK)
+	if len(attempts) != 2 {
+		t.Fatalf("attempts = %+v, want two", attempts)
+	}
+	for i, want := range []models.GormWriting{first, second} {
+		got := attempts[i]
+		if got.WritingID != int64(want.ID) || got.AttemptNumber != i+1 || *got.AiScore != *want.AIScore || got.CharacterCount != 3 {
+			t.Errorf("attempts[%d] = %+v, want writing %d as attempt %d", i, got, want.ID, i+1)
+		}
+	}
+
+	// A theme another user created is not found, rather than listed empty.
+	private := models.GormTheme{Title: "ボブのテーマ", Category: "database", CreatorID: &bob.ID}
+	mustCreate(t, c.DB, &private)
+	rec := serve(t, c.ListThemeAttempts, http.MethodGet, "/themes/:themeId/attempts", fmt.Sprintf("/themes/%d/attempts", private.ID), alice.ID, nil)
+	expectError(t, rec, http.StatusNotFound, "THEME_NOT_FOUND")
+}
+
+func TestCompareThemeAttempts(t *testing.T) {
+	c := newTestContainer(t)
+	user := createTestUser(t, c.DB, "alice")
+	theme := createTestTheme(t, c.DB, 600)
+	at := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	base := createReviewedAttempt(t, c.DB, user.ID, theme.ID, "結論です。理由です。", at, map[string]int{"structure": 60, "vocabulary": 70})
+	unreviewed := models.GormWriting{UserID: user.ID, ThemeID: theme.ID, Content: "結論です。", Status: models.WritingStatusSubmitted}
+	unreviewed.CreatedAt = at.Add(time.Hour)
+	mustCreate(t, c.DB, &unreviewed)
+	target := createReviewedAttempt(t, c.DB, user.ID, theme.ID, "結論です。具体例です。", at.Add(2*time.Hour), map[string]int{"structure": 80, "vocabulary": 65})
+
+	comparison := decodeResponse[models.AttemptComparison](t,
+		serve(t, c.CompareThemeAttempts, http.MethodGet, compareRoute, comparePath(theme.ID, base.ID, target.ID, ""), user.ID, nil), http.StatusOK)
+	if comparison.Base.AttemptNumber != 1 || comparison.Target.AttemptNumber != 3 {
+		t.Errorf("attempt numbers = %d, %d, want 1, 3", comparison.Base.AttemptNumber, comparison.Target.AttemptNumber)
+	}
+	if want := *target.AIScore - *base.AIScore; comparison.ScoreDelta == nil || *comparison.ScoreDelta != want {
+		t.Errorf("score delta = %v, want %d", comparison.ScoreDelta, want)
+	}
+	// Viewpoints follow the theme's rubric, which is the default one.
+	var deltas []string
+	for _, d := range comparison.Viewpoints {
+		deltas = append(deltas, fmt.Sprintf("%s:%d", d.Name, *d.Delta))
+	}
+	if want := []string{"語彙・用語力:-5", "構造化力:20"}; !slices.Equal(deltas, want) {
+		t.Errorf("viewpoint deltas = %q, want %q", deltas, want)
+	}
+	wantDiff := []models.DiffSegment{{Op: "equal", Text: "結論です。"}, {Op: "delete", Text: "理由です。"}, {Op: "insert", Text: "具体例です。"}}
+	if !slices.Equal(comparison.Diff, wantDiff) {
+		t.Errorf("diff = %+v, want %+v", comparison.Diff, wantDiff)
+	}
+	if comparison.Summary != nil {
+		t.Errorf("summary = %+v, want none unless asked for", comparison.Summary)
+	}
+
+	// Without a review on one side, there is no delta to report, but the diff still is.
+	comparison = decodeResponse[models.AttemptComparison](t,
+		serve(t, c.CompareThemeAttempts, http.MethodGet, compareRoute, comparePath(theme.ID, base.ID, unreviewed.ID, ""), user.ID, nil), http.StatusOK)
+	if comparison.ScoreDelta != nil || len(comparison.Viewpoints) != 2 || comparison.Viewpoints[0].Delta != nil || len(comparison.Diff) != 2 {
+		t.Errorf("comparison with an unreviewed attempt = %+v", comparison)
+	}
+
+	var usages int64
+	if err := c.DB.Model(&models.GormAIUsage{}).Count(&usages).Error; err != nil || usages != 0 {
+		t.Errorf("AI usage = %d, %v, want none without a summary", usages, err)
+	}
+}
+
+func TestCompareThemeAttemptsRejectsOtherWritings(t *testing.T) {
+	c := newTestContainer(t)
+	alice := createTestUser(t, c.DB, "alice")
+	bob := createTestUser(t, c.DB, "bob")
+	theme := createTestTheme(t, c.DB, 600)
+	other := createTestTheme(t, c.DB, 600)
+	mine := createTestWriting(t, c.DB, alice.ID, theme.ID, "一回目")
+	draft := models.GormWriting{UserID: alice.ID, ThemeID: theme.ID, Content: "下書き", Status: models.WritingStatusDraft}
+	mustCreate(t, c.DB, &draft)
+	onOtherTheme := createTestWriting(t, c.DB, alice.ID, other.ID, "別のテーマ")
+	bobs := createTestWriting(t, c.DB, bob.ID, theme.ID, "ボブの文章")
+
+	for name, target := range map[string]string{
+		"same writing":     comparePath(theme.ID, mine.ID, mine.ID, ""),
+		"no target":        fmt.Sprintf("/themes/%d/attempts/compare?base=%d", theme.ID, mine.ID),
+		"target not an id": fmt.Sprintf("/themes/%d/attempts/compare?base=%d&target=latest", theme.ID, mine.ID),
+	} {
+		rec := serve(t, c.CompareThemeAttempts, http.MethodGet, compareRoute, target, alice.ID, nil)
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, want 400: %s", name, rec.Code, rec.Body)
+		}
+	}
+	for name, id := range map[string]uint{"draft": draft.ID, "writing on another theme": onOtherTheme.ID, "another user's writing": bobs.ID} {
+		rec := serve(t, c.CompareThemeAttempts, http.MethodGet, compareRoute, comparePath(theme.ID, mine.ID, id, ""), alice.ID, nil)
+		if rec.Code != http.StatusNotFound {
+			t.Errorf("%s: status = %d, want 404: %s", name, rec.Code, rec.Body)
+		}
+	}
+}
+
+func TestCompareThemeAttemptsSummary(t *testing.T) {
+	c := newTestContainer(t)
+	user := createTestUser(t, c.DB, "alice")
+	theme := createTestTheme(t, c.DB, 600)
+	at := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	base := createReviewedAttempt(t, c.DB, user.ID, theme.ID, "結論です。", at, map[string]int{"structure": 60})
+	target := createReviewedAttempt(t, c.DB, user.ID, theme.ID, "結論です。理由です。", at.Add(time.Hour), map[string]int{"structure": 80})
+	path := comparePath(theme.ID, base.ID, target.ID, "&summary=true")
+
+	comparison := decodeResponse[models.AttemptComparison](t, serve(t, c.CompareThemeAttempts, http.MethodGet, compareRoute, path, user.ID, nil), http.StatusOK)
+	if s := comparison.Summary; s == nil || s.Model != services.FakeModelName || len(s.Improved) != 1 || len(s.Regressed) != 0 {
+		t.Errorf("summary = %+v, want the fake reviewer's, with one improvement", s)
+	}
+	var usages []models.GormAIUsage
+	if err := c.DB.Find(&usages).Error; err != nil {
+		t.Fatal(err)
+	}
+	if len(usages) != 1 || usages[0].Kind != models.AIUsageKindCompare || usages[0].UserID != user.ID {
+		t.Errorf("AI usage = %+v, want one comparison of the user", usages)
+	}
+
+	// A summary whose usage cannot be recorded is not handed out.
+	err := c.DB.Callback().Create().Before("gorm:create").Register("test:fail_ai_usage", func(tx *gorm.DB) {
+		if tx.Statement.Table == "gorm_ai_usages" {
+			tx.AddError(errors.New("disk full"))
+		}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectError(t, serve(t, c.CompareThemeAttempts, http.MethodGet, compareRoute, path, user.ID, nil), http.StatusInternalServerError, "DATABASE_ERROR")
+}
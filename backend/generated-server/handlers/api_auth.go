@@ -137,7 +137,14 @@ func (c *Container) SignupUser(ctx *gin.Context) {
 		Password: string(hashedPassword),
 	}
 
-	if err := c.DB.Create(&newUser).Error; err != nil {
+	// Invitations sent to the email before the account existed now reach the new user.
+	err = c.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&newUser).Error; err != nil {
+			return err
+		}
+		return linkMentorshipInvitations(tx, newUser)
+	})
+	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, models.APIError{Code: "DATABASE_ERROR", Message: "Failed to create user"})
 		return
 	}
@@ -0,0 +1,419 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ch00z00/kotobalize/models"
+	"github.com/ch00z00/kotobalize/services"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// defaultInterviewQuestions is how many follow-up questions an interview has
+// unless INTERVIEW_MAX_QUESTIONS says otherwise.
+const defaultInterviewQuestions = 3
+
+// StartInterview - Start a mock interview about a writing and ask the first question
+//
+// If the writing already has an active interview, it is returned with 200. Concurrent
+// requests start a single interview: the others return it with 200 as well.
+func (c *Container) StartInterview(ctx *gin.Context) {
+	gormWriting, ok := c.findInterviewWriting(ctx)
+	if !ok || !requireSubmitted(ctx, gormWriting) {
+		return
+	}
+
+	interview, err := c.latestInterview(gormWriting.ID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		ctx.JSON(http.StatusInternalServerError, models.APIError{Code: "DATABASE_ERROR", Message: "Failed to fetch interview"})
+		return
+	}
+	if err == nil && interview.Status == models.InterviewStatusActive {
+		ctx.JSON(http.StatusOK, mapGormInterviewToAPI(interview))
+		return
+	}
+
+	if err := c.checkQuota(gormWriting.UserID); err != nil {
+		status, apiErr := aiErrorResponse(err)
+		ctx.JSON(status, apiErr)
+		return
+	}
+	input, err := c.reviewInput(&gormWriting)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, models.APIError{Code: "DATABASE_ERROR", Message: err.Error()})
+		return
+	}
+
+	result, err := c.Reviewer.AskFollowUp(ctx.Request.Context(), input, nil)
+	if err != nil {
+		c.recordFailedAIUsage(gormWriting.UserID, models.AIUsageKindInterview, err)
+		status, apiErr := aiErrorResponse(err)
+		ctx.JSON(status, apiErr)
+		return
+	}
+
+	interview = models.GormInterview{
+		WritingID:    gormWriting.ID,
+		UserID:       gormWriting.UserID,
+		Status:       models.InterviewStatusActive,
+		MaxQuestions: envInt("INTERVIEW_MAX_QUESTIONS", defaultInterviewQuestions),
+		Turns:        []models.GormInterviewTurn{{Seq: 1, Question: result.Question, ModelName: result.Model}},
+	}
+	started := false
+	err = c.DB.Transaction(func(tx *gorm.DB) error {
+		// Lock the writing so that concurrent requests check for an active interview one at a time.
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").First(&models.GormWriting{}, gormWriting.ID).Error; err != nil {
+			return err
+		}
+		var active int64
+		if err := tx.Model(&models.GormInterview{}).Where("writing_id = ? AND status = ?", gormWriting.ID, models.InterviewStatusActive).Count(&active).Error; err != nil {
+			return err
+		}
+		if active == 0 {
+			if err := tx.Create(&interview).Error; err != nil {
+				return err
+			}
+			started = true
+		}
+		// The question was asked either way, so its tokens count towards the quota.
+		return recordAIUsage(tx, interview.UserID, models.AIUsageKindInterview, result.Model, result.Usage, nil)
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, models.APIError{Code: "DATABASE_ERROR", Message: "Failed to save interview"})
+		return
+	}
+	if !started {
+		interview, err = c.latestInterview(gormWriting.ID)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, models.APIError{Code: "DATABASE_ERROR", Message: "Failed to fetch interview"})
+			return
+		}
+		ctx.JSON(http.StatusOK, mapGormInterviewToAPI(interview))
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, mapGormInterviewToAPI(interview))
+}
+
+// GetInterview - Get the latest mock interview of a writing
+//
+// Both the author and their mentors can read the interview.
+func (c *Container) GetInterview(ctx *gin.Context) {
+	gormWriting, _, ok := c.findMentoredWriting(ctx)
+	if !ok {
+		return
+	}
+
+	interview, err := c.latestInterview(gormWriting.ID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			ctx.JSON(http.StatusNotFound, models.APIError{Code: "INTERVIEW_NOT_FOUND", Message: "No interview found for this writing"})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, models.APIError{Code: "DATABASE_ERROR", Message: "Failed to fetch interview"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, mapGormInterviewToAPI(interview))
+}
+
+// AnswerInterview - Answer the open interview question
+//
+// Unless the interview has reached its maximum number of questions, the AI
+// asks the next question, which is included in the response. Of concurrent answers
+// to the same question, only the first is saved; the others get 409.
+func (c *Container) AnswerInterview(ctx *gin.Context) {
+	var req models.InterviewAnswerRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, models.APIError{Code: "INVALID_INPUT", Message: err.Error()})
+		return
+	}
+
+	gormWriting, ok := c.findInterviewWriting(ctx)
+	if !ok {
+		return
+	}
+	interview, ok := c.findActiveInterview(ctx, gormWriting.ID)
+	if !ok {
+		return
+	}
+
+	// Only the last question can be open.
+	openTurn := &interview.Turns[len(interview.Turns)-1]
+	if openTurn.Answer != nil {
+		ctx.JSON(http.StatusConflict, models.APIError{Code: "NO_OPEN_QUESTION", Message: "All questions have been answered. Finish the interview to get the wrap-up review"})
+		return
+	}
+	now := time.Now()
+	openTurn.Answer = &req.Answer
+	openTurn.AnsweredAt = &now
+
+	// Ask the next question before saving anything, so a failed AI call can simply be retried.
+	var next *services.QuestionResult
+	if len(interview.Turns) < interview.MaxQuestions {
+		if err := c.checkQuota(gormWriting.UserID); err != nil {
+			status, apiErr := aiErrorResponse(err)
+			ctx.JSON(status, apiErr)
+			return
+		}
+		input, err := c.reviewInput(&gormWriting)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, models.APIError{Code: "DATABASE_ERROR", Message: err.Error()})
+			return
+		}
+		next, err = c.Reviewer.AskFollowUp(ctx.Request.Context(), input, toServiceTurns(interview.Turns))
+		if err != nil {
+			c.recordFailedAIUsage(gormWriting.UserID, models.AIUsageKindInterview, err)
+			status, apiErr := aiErrorResponse(err)
+			ctx.JSON(status, apiErr)
+			return
+		}
+	}
+
+	answered := true
+	err := c.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(openTurn).Where("answer IS NULL").Updates(map[string]interface{}{
+			"answer":      openTurn.Answer,
+			"answered_at": openTurn.AnsweredAt,
+		})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			// Another request answered the question first. The next question was asked
+			// all the same, so its tokens count towards the quota.
+			answered = false
+			if next == nil {
+				return nil
+			}
+			return recordAIUsage(tx, interview.UserID, models.AIUsageKindInterview, next.Model, next.Usage, nil)
+		}
+		if next == nil {
+			return nil
+		}
+		turn := models.GormInterviewTurn{
+			InterviewID: interview.ID,
+			Seq:         len(interview.Turns) + 1,
+			Question:    next.Question,
+			ModelName:   next.Model,
+		}
+		if err := tx.Create(&turn).Error; err != nil {
+			return err
+		}
+		interview.Turns = append(interview.Turns, turn)
+		return recordAIUsage(tx, interview.UserID, models.AIUsageKindInterview, next.Model, next.Usage, nil)
+	})
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		answered = false
+	} else if err != nil {
+		ctx.JSON(http.StatusInternalServerError, models.APIError{Code: "DATABASE_ERROR", Message: "Failed to save answer"})
+		return
+	}
+	if !answered {
+		ctx.JSON(http.StatusConflict, models.APIError{Code: "NO_OPEN_QUESTION", Message: "The question has already been answered"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, mapGormInterviewToAPI(interview))
+}
+
+// FinishInterview - End the mock interview and get a wrap-up review of the whole exchange
+//
+// A question that is still open is left out of the review.
+func (c *Container) FinishInterview(ctx *gin.Context) {
+	gormWriting, ok := c.findInterviewWriting(ctx)
+	if !ok {
+		return
+	}
+	interview, ok := c.findActiveInterview(ctx, gormWriting.ID)
+	if !ok {
+		return
+	}
+
+	var answered []models.GormInterviewTurn
+	for _, turn := range interview.Turns {
+		if turn.Answer != nil {
+			answered = append(answered, turn)
+		}
+	}
+	if len(answered) == 0 {
+		ctx.JSON(http.StatusConflict, models.APIError{Code: "INTERVIEW_NOT_ANSWERED", Message: "Answer at least one question before finishing the interview"})
+		return
+	}
+
+	if err := c.checkQuota(gormWriting.UserID); err != nil {
+		status, apiErr := aiErrorResponse(err)
+		ctx.JSON(status, apiErr)
+		return
+	}
+	input, err := c.reviewInput(&gormWriting)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, models.APIError{Code: "DATABASE_ERROR", Message: err.Error()})
+		return
+	}
+
+	result, err := c.Reviewer.ReviewInterview(ctx.Request.Context(), input, toServiceTurns(answered))
+	if err != nil {
+		c.recordFailedAIUsage(gormWriting.UserID, models.AIUsageKindInterview, err)
+		status, apiErr := aiErrorResponse(err)
+		ctx.JSON(status, apiErr)
+		return
+	}
+	// Annotations refer to the writing alone and are not part of the wrap-up.
+	result.Review.Annotations = nil
+	// Answers are user content too, so they are checked for prompt injection like the writing.
+	flagged := gormWriting.InjectionFlagged
+	for _, turn := range answered {
+		flagged = flagged || services.DetectInjection(*turn.Answer).Flagged
+	}
+	scoreCapped := false
+	if flagged {
+		scoreCapped = services.CapScores(result.Review, services.InjectionScoreCap)
+	}
+	feedbackJSON, err := json.Marshal(result.Review)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, models.APIError{Code: "INTERNAL_ERROR", Message: "Failed to serialize AI response"})
+		return
+	}
+
+	now := time.Now()
+	promptVersion := input.Rubric.PromptVersion()
+	interview.Status = models.InterviewStatusFinished
+	interview.ModelName = &result.Model
+	interview.PromptVersion = &promptVersion
+	interview.TotalScore = &result.Review.TotalScore
+	interview.ScoreCapped = scoreCapped
+	interview.Feedback = feedbackJSON
+	interview.RawResponse = &result.Raw
+	interview.FinishedAt = &now
+	err = c.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&interview).Updates(map[string]interface{}{
+			"status":         interview.Status,
+			"model_name":     interview.ModelName,
+			"prompt_version": interview.PromptVersion,
+			"total_score":    interview.TotalScore,
+			"score_capped":   interview.ScoreCapped,
+			"feedback":       interview.Feedback,
+			"raw_response":   interview.RawResponse,
+			"finished_at":    interview.FinishedAt,
+		}).Error; err != nil {
+			return err
+		}
+		return recordAIUsage(tx, interview.UserID, models.AIUsageKindInterview, result.Model, result.Usage, nil)
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, models.APIError{Code: "DATABASE_ERROR", Message: "Failed to save interview review"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, mapGormInterviewToAPI(interview))
+}
+
+// findInterviewWriting loads the writing named by the writingId path parameter with its Theme,
+// ensuring it belongs to the authenticated user. Only the author can spend AI calls on an
+// interview, so this is used instead of findMentoredWriting. On failure it writes the error response.
+func (c *Container) findInterviewWriting(ctx *gin.Context) (models.GormWriting, bool) {
+	// Get writingId from path parameter
+	writingIDStr := ctx.Param("writingId")
+	writingID, err := strconv.ParseUint(writingIDStr, 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, models.APIError{Code: "INVALID_INPUT", Message: "Invalid writing ID format"})
+		return models.GormWriting{}, false
+	}
+
+	// Get user ID from the context (set by the auth middleware)
+	userID, exists := ctx.Get("userId")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, models.APIError{Code: "UNAUTHORIZED", Message: "User ID not found in token"})
+		return models.GormWriting{}, false
+	}
+
+	var gormWriting models.GormWriting
+	if err := c.DB.Preload("Theme").Where("id = ? AND user_id = ?", writingID, userID).First(&gormWriting).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			ctx.JSON(http.StatusNotFound, models.APIError{Code: "WRITING_NOT_FOUND", Message: "Writing not found or you don't have permission to view it"})
+			return models.GormWriting{}, false
+		}
+		ctx.JSON(http.StatusInternalServerError, models.APIError{Code: "DATABASE_ERROR", Message: "Failed to fetch writing"})
+		return models.GormWriting{}, false
+	}
+	return gormWriting, true
+}
+
+// findActiveInterview loads the active interview of a writing.
+// On failure it writes the error response.
+func (c *Container) findActiveInterview(ctx *gin.Context, writingID uint) (models.GormInterview, bool) {
+	interview, err := c.latestInterview(writingID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			ctx.JSON(http.StatusNotFound, models.APIError{Code: "INTERVIEW_NOT_FOUND", Message: "No interview found for this writing"})
+			return models.GormInterview{}, false
+		}
+		ctx.JSON(http.StatusInternalServerError, models.APIError{Code: "DATABASE_ERROR", Message: "Failed to fetch interview"})
+		return models.GormInterview{}, false
+	}
+	if interview.Status != models.InterviewStatusActive || len(interview.Turns) == 0 {
+		ctx.JSON(http.StatusConflict, models.APIError{Code: "INTERVIEW_FINISHED", Message: "The interview has already finished. Start a new one"})
+		return models.GormInterview{}, false
+	}
+	return interview, true
+}
+
+// latestInterview loads the most recent interview of a writing with its turns in order.
+func (c *Container) latestInterview(writingID uint) (models.GormInterview, error) {
+	var interview models.GormInterview
+	err := c.DB.Preload("Turns", func(db *gorm.DB) *gorm.DB {
+		return db.Order("seq asc")
+	}).Where("writing_id = ?", writingID).Order("id desc").First(&interview).Error
+	return interview, err
+}
+
+// toServiceTurns converts interview turns for the reviewer.
+func toServiceTurns(turns []models.GormInterviewTurn) []services.InterviewTurn {
+	serviceTurns := make([]services.InterviewTurn, len(turns))
+	for i, turn := range turns {
+		serviceTurns[i] = services.InterviewTurn{Question: turn.Question}
+		if turn.Answer != nil {
+			serviceTurns[i].Answer = *turn.Answer
+		}
+	}
+	return serviceTurns
+}
+
+// mapGormInterviewToAPI converts a GORM interview model to an API interview model.
+func mapGormInterviewToAPI(interview models.GormInterview) models.Interview {
+	turns := make([]models.InterviewTurn, len(interview.Turns))
+	for i, turn := range interview.Turns {
+		turns[i] = models.InterviewTurn{
+			Seq:        turn.Seq,
+			Question:   turn.Question,
+			Answer:     turn.Answer,
+			AskedAt:    turn.CreatedAt,
+			AnsweredAt: turn.AnsweredAt,
+		}
+	}
+	apiInterview := models.Interview{
+		ID:           int64(interview.ID),
+		WritingID:    int64(interview.WritingID),
+		Status:       interview.Status,
+		MaxQuestions: interview.MaxQuestions,
+		Turns:        turns,
+		TotalScore:   interview.TotalScore,
+		ScoreCapped:  interview.ScoreCapped,
+		Feedback:     json.RawMessage(interview.Feedback),
+		CreatedAt:    interview.CreatedAt,
+		FinishedAt:   interview.FinishedAt,
+	}
+	if interview.ModelName != nil {
+		apiInterview.Model = *interview.ModelName
+	}
+	if interview.PromptVersion != nil {
+		apiInterview.PromptVersion = *interview.PromptVersion
+	}
+	return apiInterview
+}
@@ -0,0 +1,441 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ch00z00/kotobalize/models"
+	"github.com/gin-gonic/gin"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// Roles of the authenticated user in a mentorship.
+const (
+	mentorshipRoleMentor = "mentor"
+	mentorshipRoleMentee = "mentee"
+)
+
+// InviteMentee - Invite another user, by email, to be the authenticated user's mentee
+//
+// The response is the same whether or not the email has an account, so that inviting
+// does not reveal who is registered; an invitation to an unknown email waits until an
+// account is created with it. Inviting a user who declined or ended an earlier
+// mentorship reopens it as pending.
+func (c *Container) InviteMentee(ctx *gin.Context) {
+	var req models.NewMentorshipRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, models.APIError{Code: "INVALID_INPUT", Message: err.Error()})
+		return
+	}
+
+	// Get user ID from the context (set by the auth middleware)
+	userID, exists := ctx.Get("userId")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, models.APIError{Code: "UNAUTHORIZED", Message: "User ID not found in token"})
+		return
+	}
+
+	email := strings.TrimSpace(req.MenteeEmail)
+	var mentor models.GormUser
+	if err := c.DB.First(&mentor, userID).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, models.APIError{Code: "DATABASE_ERROR", Message: "Failed to fetch user"})
+		return
+	}
+	if strings.EqualFold(mentor.Email, email) {
+		ctx.JSON(http.StatusBadRequest, models.APIError{Code: "INVALID_INPUT", Message: "You cannot mentor yourself"})
+		return
+	}
+
+	var menteeID *uint
+	var mentee models.GormUser
+	if err := c.DB.Where("email = ?", email).First(&mentee).Error; err == nil {
+		menteeID = &mentee.ID
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		ctx.JSON(http.StatusInternalServerError, models.APIError{Code: "DATABASE_ERROR", Message: "Failed to fetch user"})
+		return
+	}
+
+	var mentorship models.GormMentorship
+	query := c.DB.Where("mentor_id = ? AND mentee_email = ?", userID, email)
+	if menteeID != nil {
+		query = c.DB.Where("mentor_id = ? AND (mentee_id = ? OR mentee_email = ?)", userID, *menteeID, email)
+	}
+	err := query.First(&mentorship).Error
+	switch {
+	case err == nil && (mentorship.Status == models.MentorshipStatusPending || mentorship.Status == models.MentorshipStatusAccepted):
+		ctx.JSON(http.StatusConflict, models.APIError{Code: "MENTORSHIP_EXISTS", Message: "You have already invited this user"})
+		return
+	case err == nil:
+		mentorship.Status = models.MentorshipStatusPending
+		mentorship.AcceptedAt = nil
+		err = c.DB.Model(&mentorship).Select("status", "accepted_at").Updates(&mentorship).Error
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		mentorship = models.GormMentorship{MentorID: userID.(uint), MenteeID: menteeID, MenteeEmail: email, Status: models.MentorshipStatusPending}
+		err = c.DB.Create(&mentorship).Error
+	}
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, models.APIError{Code: "DATABASE_ERROR", Message: "Failed to save mentorship"})
+		return
+	}
+
+	if err := c.DB.Preload("Mentor").Preload("Mentee").First(&mentorship, mentorship.ID).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, models.APIError{Code: "DATABASE_ERROR", Message: "Failed to fetch mentorship"})
+		return
+	}
+	ctx.JSON(http.StatusCreated, mapGormMentorshipToAPI(mentorship, userID.(uint)))
+}
+
+// ListMentorships - List the mentorships the authenticated user is part of, as mentor or mentee
+func (c *Container) ListMentorships(ctx *gin.Context) {
+	// Get user ID from the context (set by the auth middleware)
+	userID, exists := ctx.Get("userId")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, models.APIError{Code: "UNAUTHORIZED", Message: "User ID not found in token"})
+		return
+	}
+
+	var gormMentorships []models.GormMentorship
+	if err := c.DB.Preload("Mentor").Preload("Mentee").
+		Where("mentor_id = ? OR mentee_id = ?", userID, userID).
+		Order("created_at desc").
+		Find(&gormMentorships).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, models.APIError{Code: "DATABASE_ERROR", Message: "Failed to fetch mentorships"})
+		return
+	}
+
+	mentorships := make([]models.Mentorship, len(gormMentorships))
+	for i, m := range gormMentorships {
+		mentorships[i] = mapGormMentorshipToAPI(m, userID.(uint))
+	}
+
+	ctx.JSON(http.StatusOK, mentorships)
+}
+
+// AcceptMentorship - Accept a pending invitation as the mentee
+func (c *Container) AcceptMentorship(ctx *gin.Context) {
+	c.answerMentorship(ctx, models.MentorshipStatusAccepted)
+}
+
+// DeclineMentorship - Decline a pending invitation as the mentee
+func (c *Container) DeclineMentorship(ctx *gin.Context) {
+	c.answerMentorship(ctx, models.MentorshipStatusDeclined)
+}
+
+// answerMentorship moves a pending mentorship of the authenticated mentee to status.
+func (c *Container) answerMentorship(ctx *gin.Context, status string) {
+	mentorship, userID, ok := c.findMentorship(ctx)
+	if !ok {
+		return
+	}
+	if mentorship.MenteeID == nil || *mentorship.MenteeID != userID {
+		ctx.JSON(http.StatusForbidden, models.APIError{Code: "NOT_MENTEE", Message: "Only the invited mentee can answer this invitation"})
+		return
+	}
+	if mentorship.Status != models.MentorshipStatusPending {
+		ctx.JSON(http.StatusConflict, models.APIError{Code: "MENTORSHIP_NOT_PENDING", Message: "This invitation has already been answered"})
+		return
+	}
+
+	mentorship.Status = status
+	if status == models.MentorshipStatusAccepted {
+		now := time.Now()
+		mentorship.AcceptedAt = &now
+	}
+	if err := c.DB.Model(&mentorship).Select("status", "accepted_at").Updates(&mentorship).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, models.APIError{Code: "DATABASE_ERROR", Message: "Failed to update mentorship"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, mapGormMentorshipToAPI(mentorship, userID))
+}
+
+// EndMentorship - End a mentorship or withdraw an invitation; either side can do this
+func (c *Container) EndMentorship(ctx *gin.Context) {
+	mentorship, _, ok := c.findMentorship(ctx)
+	if !ok {
+		return
+	}
+
+	if err := c.DB.Model(&mentorship).Update("status", models.MentorshipStatusEnded).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, models.APIError{Code: "DATABASE_ERROR", Message: "Failed to update mentorship"})
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+// findMentorship loads the mentorship of the request with both users, if the authenticated
+// user is part of it. It writes an error response and returns false otherwise.
+func (c *Container) findMentorship(ctx *gin.Context) (models.GormMentorship, uint, bool) {
+	// Get mentorshipId from path parameter
+	mentorshipIDStr := ctx.Param("mentorshipId")
+	mentorshipID, err := strconv.ParseUint(mentorshipIDStr, 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, models.APIError{Code: "INVALID_INPUT", Message: "Invalid mentorship ID format"})
+		return models.GormMentorship{}, 0, false
+	}
+
+	// Get user ID from the context (set by the auth middleware)
+	userID, exists := ctx.Get("userId")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, models.APIError{Code: "UNAUTHORIZED", Message: "User ID not found in token"})
+		return models.GormMentorship{}, 0, false
+	}
+
+	var mentorship models.GormMentorship
+	if err := c.DB.Preload("Mentor").Preload("Mentee").
+		Where("id = ? AND (mentor_id = ? OR mentee_id = ?)", mentorshipID, userID, userID).
+		First(&mentorship).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			ctx.JSON(http.StatusNotFound, models.APIError{Code: "MENTORSHIP_NOT_FOUND", Message: "Mentorship not found"})
+			return models.GormMentorship{}, 0, false
+		}
+		ctx.JSON(http.StatusInternalServerError, models.APIError{Code: "DATABASE_ERROR", Message: "Failed to fetch mentorship"})
+		return models.GormMentorship{}, 0, false
+	}
+	return mentorship, userID.(uint), true
+}
+
+// ListMentorComments - List the mentor comments on a writing, oldest first
+//
+// Both the author and their mentors can read the comments.
+func (c *Container) ListMentorComments(ctx *gin.Context) {
+	gormWriting, _, ok := c.findMentoredWriting(ctx)
+	if !ok {
+		return
+	}
+
+	comments, err := c.mentorComments(gormWriting.ID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, models.APIError{Code: "DATABASE_ERROR", Message: "Failed to fetch mentor comments"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, comments)
+}
+
+// CreateMentorComment - Comment on a mentee's writing, optionally overriding its scores
+//
+// Only mentors the author has accepted can comment. A comment with a score, or with
+// viewpoint scores, sets the writing's mentorScore.
+func (c *Container) CreateMentorComment(ctx *gin.Context) {
+	var req models.NewMentorCommentRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, models.APIError{Code: "INVALID_INPUT", Message: err.Error()})
+		return
+	}
+	if strings.TrimSpace(req.Body) == "" {
+		ctx.JSON(http.StatusBadRequest, models.APIError{Code: "INVALID_INPUT", Message: "body must not be empty"})
+		return
+	}
+
+	gormWriting, userID, ok := c.findMentoredWriting(ctx)
+	if !ok {
+		return
+	}
+	if gormWriting.UserID == userID {
+		ctx.JSON(http.StatusForbidden, models.APIError{Code: "NOT_MENTOR", Message: "Only the author's mentors can comment on this writing"})
+		return
+	}
+
+	comment := models.GormMentorComment{
+		WritingID: gormWriting.ID,
+		MentorID:  userID,
+		Body:      strings.TrimSpace(req.Body),
+		Score:     req.Score,
+	}
+
+	// Viewpoint overrides must use the theme's rubric. Without an explicit score, the total
+	// is recomputed from the AI's scores with the overrides applied.
+	if len(req.ViewpointScores) > 0 {
+		rubric, err := c.resolveRubric(gormWriting.Theme)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, models.APIError{Code: "DATABASE_ERROR", Message: "Failed to load rubric"})
+			return
+		}
+		scores := map[string]int{}
+		if review := decodeWritingReview(gormWriting); review != nil {
+			for key, score := range review.Scores {
+				scores[key] = score
+			}
+		}
+		for key, score := range req.ViewpointScores {
+			if vp, ok := rubric.Viewpoint(key); !ok || vp.Key != key {
+				ctx.JSON(http.StatusBadRequest, models.APIError{Code: "INVALID_INPUT", Message: fmt.Sprintf("viewpointScores.%s is not a rubric viewpoint", key)})
+				return
+			}
+			if score < 0 || score > 100 {
+				ctx.JSON(http.StatusBadRequest, models.APIError{Code: "INVALID_INPUT", Message: fmt.Sprintf("viewpointScores.%s must be between 0 and 100", key)})
+				return
+			}
+			scores[key] = score
+		}
+		comment.ViewpointScores = datatypes.NewJSONType(req.ViewpointScores)
+		if comment.Score == nil {
+			total := rubric.WeightedTotal(scores)
+			comment.Score = &total
+		}
+	}
+
+	err := c.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&comment).Error; err != nil {
+			return err
+		}
+		if comment.Score == nil {
+			return nil
+		}
+		return tx.Model(&gormWriting).Update("mentor_score", *comment.Score).Error
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, models.APIError{Code: "DATABASE_ERROR", Message: "Failed to save mentor comment"})
+		return
+	}
+	if err := c.DB.First(&comment.Mentor, userID).Error; err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		ctx.JSON(http.StatusInternalServerError, models.APIError{Code: "DATABASE_ERROR", Message: "Failed to fetch mentor"})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, mapGormMentorCommentToAPI(comment))
+}
+
+// findMentoredWriting loads the writing of the request with its Theme, if the authenticated
+// user is its author or one of the author's mentors. It writes an error response and
+// returns false otherwise.
+func (c *Container) findMentoredWriting(ctx *gin.Context) (models.GormWriting, uint, bool) {
+	// Get writingId from path parameter
+	writingIDStr := ctx.Param("writingId")
+	writingID, err := strconv.ParseUint(writingIDStr, 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, models.APIError{Code: "INVALID_INPUT", Message: "Invalid writing ID format"})
+		return models.GormWriting{}, 0, false
+	}
+
+	// Get user ID from the context (set by the auth middleware)
+	userID, exists := ctx.Get("userId")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, models.APIError{Code: "UNAUTHORIZED", Message: "User ID not found in token"})
+		return models.GormWriting{}, 0, false
+	}
+
+	var gormWriting models.GormWriting
+	if err := c.DB.Preload("Theme").Where("id = ?", writingID).Scopes(c.visibleWritings(userID.(uint))).First(&gormWriting).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			ctx.JSON(http.StatusNotFound, models.APIError{Code: "WRITING_NOT_FOUND", Message: "Writing not found or you don't have permission to view it"})
+			return models.GormWriting{}, 0, false
+		}
+		ctx.JSON(http.StatusInternalServerError, models.APIError{Code: "DATABASE_ERROR", Message: "Failed to fetch writing"})
+		return models.GormWriting{}, 0, false
+	}
+	return gormWriting, userID.(uint), true
+}
+
+// visibleWritings limits a writing query to the user's own writings and the submitted
+// writings of the mentees who accepted the user as their mentor. Drafts stay private.
+func (c *Container) visibleWritings(userID uint) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		mentees := c.DB.Model(&models.GormMentorship{}).
+			Select("mentee_id").
+			Where("mentor_id = ? AND status = ?", userID, models.MentorshipStatusAccepted)
+		// Qualified, so that the scope also works on queries joining tables with a user_id or status.
+		return db.Where("(gorm_writings.user_id = ? OR (gorm_writings.user_id IN (?) AND gorm_writings.status = ?))", userID, mentees, models.WritingStatusSubmitted)
+	}
+}
+
+// linkMentorshipInvitations attaches the invitations sent to the email of a new user
+// before the account existed.
+func linkMentorshipInvitations(tx *gorm.DB, user models.GormUser) error {
+	return tx.Model(&models.GormMentorship{}).
+		Where("mentee_id IS NULL AND mentee_email = ?", user.Email).
+		Update("mentee_id", user.ID).Error
+}
+
+// viewedUserID returns the user whose data the request asks for: the user in the userId
+// query parameter, who must be an accepted mentee of the authenticated user, or else the
+// authenticated user. It writes an error response and returns false if that fails.
+func (c *Container) viewedUserID(ctx *gin.Context, userID uint) (uint, bool) {
+	userIDStr := ctx.Query("userId")
+	if userIDStr == "" {
+		return userID, true
+	}
+	menteeID, err := strconv.ParseUint(userIDStr, 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, models.APIError{Code: "INVALID_INPUT", Message: "Invalid user ID format"})
+		return 0, false
+	}
+	if uint(menteeID) == userID {
+		return userID, true
+	}
+
+	var count int64
+	if err := c.DB.Model(&models.GormMentorship{}).
+		Where("mentor_id = ? AND mentee_id = ? AND status = ?", userID, menteeID, models.MentorshipStatusAccepted).
+		Count(&count).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, models.APIError{Code: "DATABASE_ERROR", Message: "Failed to fetch mentorship"})
+		return 0, false
+	}
+	if count == 0 {
+		ctx.JSON(http.StatusForbidden, models.APIError{Code: "NOT_MENTOR", Message: "You can only view the data of mentees who accepted you as their mentor"})
+		return 0, false
+	}
+	return uint(menteeID), true
+}
+
+// mentorComments loads the mentor comments on a writing, oldest first.
+func (c *Container) mentorComments(writingID uint) ([]models.MentorComment, error) {
+	var gormComments []models.GormMentorComment
+	if err := c.DB.Preload("Mentor").Where("writing_id = ?", writingID).Order("created_at asc").Find(&gormComments).Error; err != nil {
+		return nil, err
+	}
+	comments := make([]models.MentorComment, len(gormComments))
+	for i, comment := range gormComments {
+		comments[i] = mapGormMentorCommentToAPI(comment)
+	}
+	return comments, nil
+}
+
+// mapGormMentorshipToAPI converts a GORM mentorship model to an API mentorship model,
+// from the point of view of the given user.
+func mapGormMentorshipToAPI(gormMentorship models.GormMentorship, userID uint) models.Mentorship {
+	role := mentorshipRoleMentee
+	if gormMentorship.MentorID == userID {
+		role = mentorshipRoleMentor
+	}
+	mentorship := models.Mentorship{
+		ID:         int64(gormMentorship.ID),
+		MentorID:   int64(gormMentorship.MentorID),
+		MentorName: gormMentorship.Mentor.Name,
+		Status:     gormMentorship.Status,
+		Role:       role,
+		AcceptedAt: gormMentorship.AcceptedAt,
+		CreatedAt:  gormMentorship.CreatedAt,
+	}
+	if role == mentorshipRoleMentor && gormMentorship.MenteeEmail != "" {
+		mentorship.MenteeEmail = &gormMentorship.MenteeEmail
+	}
+	// A pending invitation must not tell the mentor whether the email has an account.
+	if gormMentorship.MenteeID != nil && (role == mentorshipRoleMentee || gormMentorship.AcceptedAt != nil) {
+		menteeID := int64(*gormMentorship.MenteeID)
+		mentorship.MenteeID = &menteeID
+		mentorship.MenteeName = gormMentorship.Mentee.Name
+	}
+	return mentorship
+}
+
+// mapGormMentorCommentToAPI converts a GORM mentor comment model to an API mentor comment model.
+func mapGormMentorCommentToAPI(gormComment models.GormMentorComment) models.MentorComment {
+	return models.MentorComment{
+		ID:              int64(gormComment.ID),
+		WritingID:       int64(gormComment.WritingID),
+		MentorID:        int64(gormComment.MentorID),
+		MentorName:      gormComment.Mentor.Name,
+		Body:            gormComment.Body,
+		Score:           gormComment.Score,
+		ViewpointScores: gormComment.ViewpointScores.Data(),
+		CreatedAt:       gormComment.CreatedAt,
+	}
+}
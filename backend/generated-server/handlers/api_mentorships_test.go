@@ -0,0 +1,104 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/ch00z00/kotobalize/models"
+)
+
+const writingRoute = "/writings/:writingId"
+
+// inviteMentee invites the email as the mentor's mentee.
+func inviteMentee(t *testing.T, c *Container, mentorID uint, email string) models.Mentorship {
+	t.Helper()
+	rec := serve(t, c.InviteMentee, http.MethodPost, "/mentorships", "/mentorships", mentorID, models.NewMentorshipRequest{MenteeEmail: email})
+	return decodeResponse[models.Mentorship](t, rec, http.StatusCreated)
+}
+
+// acceptMentorship accepts the invitation as the mentee.
+func acceptMentorship(t *testing.T, c *Container, mentorshipID int64, menteeID uint) {
+	t.Helper()
+	rec := serve(t, c.AcceptMentorship, http.MethodPost, "/mentorships/:mentorshipId/accept", fmt.Sprintf("/mentorships/%d/accept", mentorshipID), menteeID, nil)
+	decodeResponse[models.Mentorship](t, rec, http.StatusOK)
+}
+
+func TestMentorSeesSubmittedWritingsOfAcceptedMentees(t *testing.T) {
+	c := newTestContainer(t)
+	mentor := createTestUser(t, c.DB, "mentor")
+	mentee := createTestUser(t, c.DB, "mentee")
+	theme := createTestTheme(t, c.DB, 600)
+	submitted := createTestWriting(t, c.DB, mentee.ID, theme.ID, "提出した文章")
+	draft := models.GormWriting{UserID: mentee.ID, ThemeID: theme.ID, Content: "下書き", Status: models.WritingStatusDraft}
+	mustCreate(t, c.DB, &draft)
+
+	get := func(writingID uint) int {
+		return serve(t, c.GetWritingByID, http.MethodGet, writingRoute, writingPath(writingID, ""), mentor.ID, nil).Code
+	}
+
+	// While the invitation is pending, the mentee's writings stay private.
+	mentorship := inviteMentee(t, c, mentor.ID, mentee.Email)
+	if mentorship.Status != models.MentorshipStatusPending || get(submitted.ID) != http.StatusNotFound {
+		t.Fatalf("pending mentorship: status %q, GET submitted writing = %d, want pending and 404", mentorship.Status, get(submitted.ID))
+	}
+
+	acceptMentorship(t, c, mentorship.ID, mentee.ID)
+	if code := get(submitted.ID); code != http.StatusOK {
+		t.Errorf("accepted mentorship: GET submitted writing = %d, want 200", code)
+	}
+	if code := get(draft.ID); code != http.StatusNotFound {
+		t.Errorf("accepted mentorship: GET draft = %d, want 404", code)
+	}
+	// The mentee's own draft stays visible to the mentee.
+	if code := serve(t, c.GetWritingByID, http.MethodGet, writingRoute, writingPath(draft.ID, ""), mentee.ID, nil).Code; code != http.StatusOK {
+		t.Errorf("GET own draft = %d, want 200", code)
+	}
+}
+
+func TestVisibleWritingsWithJoinedTables(t *testing.T) {
+	c := newTestContainer(t)
+	mentor := createTestUser(t, c.DB, "mentor")
+	mentee := createTestUser(t, c.DB, "mentee")
+	theme := createTestTheme(t, c.DB, 600)
+	writing := createTestWriting(t, c.DB, mentee.ID, theme.ID, "提出した文章")
+	acceptMentorship(t, c, inviteMentee(t, c, mentor.ID, mentee.Email).ID, mentee.ID)
+	mustCreate(t, c.DB, &models.GormReview{WritingID: writing.ID, UserID: mentee.ID, ModelName: "fake", PromptVersion: "default@v1/p2", TotalScore: 70})
+
+	// Reviews have a user_id of their own.
+	var count int64
+	err := c.DB.Model(&models.GormWriting{}).
+		Joins("JOIN gorm_reviews ON gorm_reviews.writing_id = gorm_writings.id").
+		Scopes(c.visibleWritings(mentor.ID)).
+		Count(&count).Error
+	if err != nil || count != 1 {
+		t.Errorf("count = %d, %v, want 1", count, err)
+	}
+}
+
+func TestInvitationReachesAccountCreatedLater(t *testing.T) {
+	c := newTestContainer(t)
+	c.JWTSecret = "secret"
+	mentor := createTestUser(t, c.DB, "mentor")
+	theme := createTestTheme(t, c.DB, 600)
+	invitation := inviteMentee(t, c, mentor.ID, "newcomer@example.com")
+
+	rec := serve(t, c.SignupUser, http.MethodPost, "/auth/signup", "/auth/signup", 0, models.RegisterRequest{Email: "newcomer@example.com", Password: "password123"})
+	decodeResponse[models.AuthResponse](t, rec, http.StatusCreated)
+	var newcomer models.GormUser
+	if err := c.DB.Where("email = ?", "newcomer@example.com").First(&newcomer).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	rec = serve(t, c.ListMentorships, http.MethodGet, "/mentorships", "/mentorships", newcomer.ID, nil)
+	mentorships := decodeResponse[[]models.Mentorship](t, rec, http.StatusOK)
+	if len(mentorships) != 1 || mentorships[0].ID != invitation.ID || mentorships[0].Role != mentorshipRoleMentee {
+		t.Fatalf("mentorships of the new user = %+v, want the invitation as mentee", mentorships)
+	}
+
+	acceptMentorship(t, c, invitation.ID, newcomer.ID)
+	writing := createTestWriting(t, c.DB, newcomer.ID, theme.ID, "初めての文章")
+	if code := serve(t, c.GetWritingByID, http.MethodGet, writingRoute, writingPath(writing.ID, ""), mentor.ID, nil).Code; code != http.StatusOK {
+		t.Errorf("mentor GET writing of the new mentee = %d, want 200", code)
+	}
+}
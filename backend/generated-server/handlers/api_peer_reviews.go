@@ -0,0 +1,390 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ch00z00/kotobalize/models"
+	"github.com/ch00z00/kotobalize/services"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// UpdatePeerReviewSettings - Open or close a writing for peer review
+//
+// Only the author can change the setting. Closing a writing keeps the peer reviews
+// it already received but stops other users from reading and reviewing it.
+func (c *Container) UpdatePeerReviewSettings(ctx *gin.Context) {
+	// Get writingId from path parameter
+	writingIDStr := ctx.Param("writingId")
+	writingID, err := strconv.ParseUint(writingIDStr, 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, models.APIError{Code: "INVALID_INPUT", Message: "Invalid writing ID format"})
+		return
+	}
+
+	var req models.PeerReviewSettingsRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, models.APIError{Code: "INVALID_INPUT", Message: err.Error()})
+		return
+	}
+
+	// Get user ID from the context (set by the auth middleware)
+	userID, exists := ctx.Get("userId")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, models.APIError{Code: "UNAUTHORIZED", Message: "User ID not found in token"})
+		return
+	}
+
+	// Find the writing, ensuring it belongs to the authenticated user.
+	var gormWriting models.GormWriting
+	if err := c.DB.Where("id = ? AND user_id = ?", writingID, userID).First(&gormWriting).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			ctx.JSON(http.StatusNotFound, models.APIError{Code: "WRITING_NOT_FOUND", Message: "Writing not found or you don't have permission to view it"})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, models.APIError{Code: "DATABASE_ERROR", Message: "Failed to fetch writing"})
+		return
+	}
+	if *req.Open && !requireSubmitted(ctx, gormWriting) {
+		return
+	}
+
+	if err := c.DB.Model(&gormWriting).Update("open_for_peer_review", *req.Open).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, models.APIError{Code: "DATABASE_ERROR", Message: "Failed to update writing"})
+		return
+	}
+	gormWriting.OpenForPeerReview = *req.Open
+
+	ctx.JSON(http.StatusOK, mapGormWritingToAPI(gormWriting))
+}
+
+// ListPeerReviewWritings - List other users' writings that are open for peer review, newest first
+//
+// The optional themeId query parameter limits the list to one theme. With limit, the list is
+// paginated: the X-Next-Cursor header carries the cursor of the next page. With view=excerpt,
+// writings are listed with an excerpt of their content instead of the content.
+func (c *Container) ListPeerReviewWritings(ctx *gin.Context) {
+	// Get user ID from the context (set by the auth middleware)
+	userID, exists := ctx.Get("userId")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, models.APIError{Code: "UNAUTHORIZED", Message: "User ID not found in token"})
+		return
+	}
+
+	page, ok := parsePageQuery(ctx)
+	if !ok {
+		return
+	}
+	paginate, err := page.scope([]sortColumn{
+		{expr: "gorm_writings.created_at", desc: true, time: true},
+		{expr: "gorm_writings.id", desc: true},
+	})
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, models.APIError{Code: "INVALID_INPUT", Message: err.Error()})
+		return
+	}
+	view := ctx.DefaultQuery("view", "full")
+	if view != "full" && view != "excerpt" {
+		ctx.JSON(http.StatusBadRequest, models.APIError{Code: "INVALID_INPUT", Message: "view must be full or excerpt"})
+		return
+	}
+
+	query := c.DB.Table("gorm_writings").
+		Joins("LEFT JOIN gorm_themes ON gorm_themes.id = gorm_writings.theme_id").
+		Where("gorm_writings.deleted_at IS NULL AND gorm_writings.open_for_peer_review = ? AND gorm_writings.user_id <> ?", true, userID)
+	if themeIDStr := ctx.Query("themeId"); themeIDStr != "" {
+		themeID, err := strconv.ParseUint(themeIDStr, 10, 64)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, models.APIError{Code: "INVALID_INPUT", Message: "Invalid theme ID format"})
+			return
+		}
+		query = query.Where("gorm_writings.theme_id = ?", themeID)
+	}
+	if view == "excerpt" {
+		query = query.Select("gorm_writings.id, gorm_writings.theme_id, gorm_themes.title AS theme_title, gorm_writings.user_id, "+
+			"SUBSTRING(gorm_writings.content, 1, ?) AS excerpt, CHAR_LENGTH(gorm_writings.content) AS character_count, gorm_writings.created_at", excerptLength)
+	} else {
+		query = query.Select("gorm_writings.id, gorm_writings.theme_id, gorm_themes.title AS theme_title, gorm_writings.user_id, " +
+			"gorm_writings.content, gorm_writings.created_at")
+	}
+
+	var rows []peerReviewWritingRow
+	if err := query.Scopes(paginate).Find(&rows).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, models.APIError{Code: "DATABASE_ERROR", Message: "Failed to fetch writings"})
+		return
+	}
+	rows = setNextCursor(ctx, page, rows, func(r peerReviewWritingRow) []string {
+		return []string{cursorTime(r.CreatedAt), cursorInt(r.ID)}
+	})
+
+	writingIDs := make([]uint, len(rows))
+	authorIDs := make([]uint, len(rows))
+	for i, r := range rows {
+		writingIDs[i] = r.ID
+		authorIDs[i] = r.UserID
+	}
+
+	// Authors, review counts and the caller's own reviews are loaded in one query each.
+	var authors []models.GormUser
+	var counts []struct {
+		WritingID uint
+		Count     int
+	}
+	var reviewedIDs []uint
+	if len(rows) > 0 {
+		if err := c.DB.Select("id", "name").Where("id IN ?", authorIDs).Find(&authors).Error; err != nil {
+			ctx.JSON(http.StatusInternalServerError, models.APIError{Code: "DATABASE_ERROR", Message: "Failed to fetch authors"})
+			return
+		}
+		if err := c.DB.Model(&models.GormPeerReview{}).
+			Select("writing_id, COUNT(*) AS count").
+			Where("writing_id IN ?", writingIDs).
+			Group("writing_id").
+			Scan(&counts).Error; err != nil {
+			ctx.JSON(http.StatusInternalServerError, models.APIError{Code: "DATABASE_ERROR", Message: "Failed to count peer reviews"})
+			return
+		}
+		if err := c.DB.Model(&models.GormPeerReview{}).
+			Where("writing_id IN ? AND reviewer_id = ?", writingIDs, userID).
+			Pluck("writing_id", &reviewedIDs).Error; err != nil {
+			ctx.JSON(http.StatusInternalServerError, models.APIError{Code: "DATABASE_ERROR", Message: "Failed to fetch peer reviews"})
+			return
+		}
+	}
+	authorNames := make(map[uint]*string, len(authors))
+	for _, a := range authors {
+		authorNames[a.ID] = a.Name
+	}
+	reviewCounts := make(map[uint]int, len(counts))
+	for _, row := range counts {
+		reviewCounts[row.WritingID] = row.Count
+	}
+	reviewed := make(map[uint]bool, len(reviewedIDs))
+	for _, id := range reviewedIDs {
+		reviewed[id] = true
+	}
+
+	if view == "excerpt" {
+		summaries := make([]models.PeerReviewWritingSummary, len(rows))
+		for i, r := range rows {
+			summaries[i] = models.PeerReviewWritingSummary{
+				ID:              int64(r.ID),
+				ThemeID:         int64(r.ThemeID),
+				ThemeTitle:      r.ThemeTitle,
+				AuthorID:        int64(r.UserID),
+				AuthorName:      authorNames[r.UserID],
+				Excerpt:         excerptText(r.Excerpt, r.CharacterCount),
+				CharacterCount:  r.CharacterCount,
+				PeerReviewCount: reviewCounts[r.ID],
+				ReviewedByMe:    reviewed[r.ID],
+				CreatedAt:       r.CreatedAt,
+			}
+		}
+		ctx.JSON(http.StatusOK, summaries)
+		return
+	}
+
+	apiWritings := make([]models.PeerReviewWriting, len(rows))
+	for i, r := range rows {
+		apiWritings[i] = models.PeerReviewWriting{
+			ID:              int64(r.ID),
+			ThemeID:         int64(r.ThemeID),
+			ThemeTitle:      r.ThemeTitle,
+			AuthorID:        int64(r.UserID),
+			AuthorName:      authorNames[r.UserID],
+			Content:         r.Content,
+			PeerReviewCount: reviewCounts[r.ID],
+			ReviewedByMe:    reviewed[r.ID],
+			CreatedAt:       r.CreatedAt,
+		}
+	}
+
+	ctx.JSON(http.StatusOK, apiWritings)
+}
+
+// peerReviewWritingRow is a row of ListPeerReviewWritings. Content is only selected in the
+// full view, Excerpt and CharacterCount only in the excerpt view.
+type peerReviewWritingRow struct {
+	ID             uint
+	ThemeID        uint
+	ThemeTitle     string
+	UserID         uint
+	Content        string
+	Excerpt        string
+	CharacterCount int
+	CreatedAt      time.Time
+}
+
+// ListPeerReviews - List the peer reviews of a writing
+//
+// The author sees every peer review. Other users only see their own review,
+// and only while the writing is open for peer review.
+func (c *Container) ListPeerReviews(ctx *gin.Context) {
+	gormWriting, userID, ok := c.findPeerReviewWriting(ctx)
+	if !ok {
+		return
+	}
+
+	query := c.DB.Preload("Reviewer").Where("writing_id = ?", gormWriting.ID)
+	if gormWriting.UserID != userID {
+		query = query.Where("reviewer_id = ?", userID)
+	}
+	var gormPeerReviews []models.GormPeerReview
+	if err := query.Order("created_at asc").Find(&gormPeerReviews).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, models.APIError{Code: "DATABASE_ERROR", Message: "Failed to fetch peer reviews"})
+		return
+	}
+
+	peerReviews := make([]models.PeerReview, len(gormPeerReviews))
+	for i, r := range gormPeerReviews {
+		peerReviews[i] = mapGormPeerReviewToAPI(r)
+	}
+
+	ctx.JSON(http.StatusOK, peerReviews)
+}
+
+// CreatePeerReview - Review another user's writing with the theme's rubric
+//
+// The writing must be open for peer review (otherwise it is reported as not found),
+// authors cannot review their own writing, and each user can review a writing once.
+func (c *Container) CreatePeerReview(ctx *gin.Context) {
+	var req models.NewPeerReviewRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, models.APIError{Code: "INVALID_INPUT", Message: err.Error()})
+		return
+	}
+
+	gormWriting, userID, ok := c.findPeerReviewWriting(ctx)
+	if !ok {
+		return
+	}
+	if gormWriting.UserID == userID {
+		ctx.JSON(http.StatusForbidden, models.APIError{Code: "CANNOT_REVIEW_OWN_WRITING", Message: "You cannot peer review your own writing"})
+		return
+	}
+
+	var count int64
+	if err := c.DB.Model(&models.GormPeerReview{}).Where("writing_id = ? AND reviewer_id = ?", gormWriting.ID, userID).Count(&count).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, models.APIError{Code: "DATABASE_ERROR", Message: "Failed to fetch peer reviews"})
+		return
+	}
+	if count > 0 {
+		ctx.JSON(http.StatusConflict, models.APIError{Code: "PEER_REVIEW_EXISTS", Message: "You have already reviewed this writing"})
+		return
+	}
+
+	// Peer reviews use the same rubric as the AI review of the writing.
+	rubric, err := c.resolveRubric(gormWriting.Theme)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, models.APIError{Code: "DATABASE_ERROR", Message: "Failed to load rubric"})
+		return
+	}
+	feedbacks := make([]services.FeedbackDetail, len(req.Feedbacks))
+	for i, f := range req.Feedbacks {
+		feedbacks[i] = services.FeedbackDetail{Viewpoint: f.Viewpoint, Score: f.Score, GoodPoint: f.GoodPoint, BadPoint: f.BadPoint}
+	}
+	review, problems := services.BuildPeerReview(feedbacks, rubric)
+	if len(problems) > 0 {
+		ctx.JSON(http.StatusBadRequest, models.APIError{Code: "INVALID_PEER_REVIEW", Message: strings.Join(problems, "; ")})
+		return
+	}
+	feedbackJSON, err := json.Marshal(review)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, models.APIError{Code: "INTERNAL_ERROR", Message: "Failed to serialize feedback"})
+		return
+	}
+
+	peerReview := models.GormPeerReview{
+		WritingID:     gormWriting.ID,
+		ReviewerID:    userID,
+		RubricVersion: rubric.VersionName(),
+		TotalScore:    review.TotalScore,
+		Feedback:      feedbackJSON,
+	}
+	if rubric.ID != 0 {
+		peerReview.RubricID = &rubric.ID
+	}
+	if err := c.DB.Create(&peerReview).Error; err != nil {
+		// Another request of the same user saved its review since the check above.
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			ctx.JSON(http.StatusConflict, models.APIError{Code: "PEER_REVIEW_EXISTS", Message: "You have already reviewed this writing"})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, models.APIError{Code: "DATABASE_ERROR", Message: "Failed to save peer review"})
+		return
+	}
+	if err := c.DB.First(&peerReview.Reviewer, userID).Error; err != nil {
+		log.Printf("failed to load reviewer %d of peer review %d: %v", userID, peerReview.ID, err)
+	}
+
+	ctx.JSON(http.StatusCreated, mapGormPeerReviewToAPI(peerReview))
+}
+
+// findPeerReviewWriting loads the writing of the request with its Theme, if the
+// authenticated user is its author or it is open for peer review. It writes an
+// error response and returns false otherwise.
+func (c *Container) findPeerReviewWriting(ctx *gin.Context) (models.GormWriting, uint, bool) {
+	// Get writingId from path parameter
+	writingIDStr := ctx.Param("writingId")
+	writingID, err := strconv.ParseUint(writingIDStr, 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, models.APIError{Code: "INVALID_INPUT", Message: "Invalid writing ID format"})
+		return models.GormWriting{}, 0, false
+	}
+
+	// Get user ID from the context (set by the auth middleware)
+	userID, exists := ctx.Get("userId")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, models.APIError{Code: "UNAUTHORIZED", Message: "User ID not found in token"})
+		return models.GormWriting{}, 0, false
+	}
+
+	// Other users' writings are only visible while they are open for peer review,
+	// so that closed writings cannot be told apart from missing ones.
+	var gormWriting models.GormWriting
+	if err := c.DB.Preload("Theme").
+		Where("id = ? AND (user_id = ? OR open_for_peer_review = ?)", writingID, userID, true).
+		First(&gormWriting).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			ctx.JSON(http.StatusNotFound, models.APIError{Code: "WRITING_NOT_FOUND", Message: "Writing not found or you don't have permission to view it"})
+			return models.GormWriting{}, 0, false
+		}
+		ctx.JSON(http.StatusInternalServerError, models.APIError{Code: "DATABASE_ERROR", Message: "Failed to fetch writing"})
+		return models.GormWriting{}, 0, false
+	}
+	return gormWriting, userID.(uint), true
+}
+
+// mapGormPeerReviewToAPI converts a GORM peer review model to an API peer review model.
+func mapGormPeerReviewToAPI(gormPeerReview models.GormPeerReview) models.PeerReview {
+	peerReview := models.PeerReview{
+		ID:            int64(gormPeerReview.ID),
+		WritingID:     int64(gormPeerReview.WritingID),
+		ReviewerID:    int64(gormPeerReview.ReviewerID),
+		ReviewerName:  gormPeerReview.Reviewer.Name,
+		RubricID:      gormPeerReview.RubricID,
+		RubricVersion: gormPeerReview.RubricVersion,
+		TotalScore:    gormPeerReview.TotalScore,
+		Scores:        map[string]int{},
+		Feedbacks:     []models.FeedbackDetail{},
+		CreatedAt:     gormPeerReview.CreatedAt,
+	}
+	var review services.AIReviewResponse
+	if err := json.Unmarshal(gormPeerReview.Feedback, &review); err != nil {
+		log.Printf("failed to decode feedback of peer review %d: %v", gormPeerReview.ID, err)
+		return peerReview
+	}
+	if review.Scores != nil {
+		peerReview.Scores = review.Scores
+	}
+	for _, f := range review.Feedbacks {
+		peerReview.Feedbacks = append(peerReview.Feedbacks, models.FeedbackDetail{Viewpoint: f.Viewpoint, Score: f.Score, GoodPoint: f.GoodPoint, BadPoint: f.BadPoint})
+	}
+	return peerReview
+}
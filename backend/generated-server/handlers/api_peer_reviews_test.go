@@ -0,0 +1,142 @@
+package handlers
+
+import (
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ch00z00/kotobalize/models"
+	"github.com/ch00z00/kotobalize/services"
+	"gorm.io/gorm"
+)
+
+const peerReviewsRoute = "/writings/:writingId/peer-reviews"
+
+// peerReviewRequest rates every viewpoint of the default rubric.
+func peerReviewRequest() models.NewPeerReviewRequest {
+	var req models.NewPeerReviewRequest
+	for _, vp := range services.DefaultRubric().Viewpoints {
+		req.Feedbacks = append(req.Feedbacks, models.FeedbackDetail{Viewpoint: vp.Key, Score: 70, GoodPoint: "分かりやすい", BadPoint: "例が欲しい"})
+	}
+	return req
+}
+
+// createOpenWriting inserts a writing of the user that is open for peer review.
+func createOpenWriting(t *testing.T, db *gorm.DB, userID, themeID uint, content string, createdAt time.Time) models.GormWriting {
+	t.Helper()
+	writing := models.GormWriting{UserID: userID, ThemeID: themeID, Content: content, Status: models.WritingStatusSubmitted, OpenForPeerReview: true}
+	writing.CreatedAt = createdAt
+	mustCreate(t, db, &writing)
+	return writing
+}
+
+func TestCreatePeerReviewOnce(t *testing.T) {
+	c := newTestContainer(t)
+	author := createTestUser(t, c.DB, "alice")
+	reviewer := createTestUser(t, c.DB, "bob")
+	theme := createTestTheme(t, c.DB, 600)
+	writing := createOpenWriting(t, c.DB, author.ID, theme.ID, "ACIDは性質です。", time.Now())
+
+	created := decodeResponse[models.PeerReview](t, serve(t, c.CreatePeerReview, http.MethodPost, peerReviewsRoute, writingPath(writing.ID, "/peer-reviews"), reviewer.ID, peerReviewRequest()), http.StatusCreated)
+	if created.TotalScore != 70 || created.RubricVersion != "default@v1" {
+		t.Errorf("peer review = %+v, want a total of 70 on default@v1", created)
+	}
+	rec := serve(t, c.CreatePeerReview, http.MethodPost, peerReviewsRoute, writingPath(writing.ID, "/peer-reviews"), reviewer.ID, peerReviewRequest())
+	expectError(t, rec, http.StatusConflict, "PEER_REVIEW_EXISTS")
+}
+
+func TestCreatePeerReviewSavedConcurrently(t *testing.T) {
+	c := newTestContainer(t)
+	author := createTestUser(t, c.DB, "alice")
+	reviewer := createTestUser(t, c.DB, "bob")
+	theme := createTestTheme(t, c.DB, 600)
+	writing := createOpenWriting(t, c.DB, author.ID, theme.ID, "ACIDは性質です。", time.Now())
+
+	// Another request of the reviewer saves its review between the check and the insert.
+	saved := false
+	err := c.DB.Callback().Create().Before("gorm:create").Register("test:concurrent_peer_review", func(db *gorm.DB) {
+		review, ok := db.Statement.Dest.(*models.GormPeerReview)
+		if !ok || saved {
+			return
+		}
+		saved = true
+		first := models.GormPeerReview{WritingID: review.WritingID, ReviewerID: review.ReviewerID, RubricVersion: review.RubricVersion, TotalScore: 50}
+		if err := db.Session(&gorm.Session{NewDB: true}).Create(&first).Error; err != nil {
+			t.Errorf("save the concurrent review: %v", err)
+		}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rec := serve(t, c.CreatePeerReview, http.MethodPost, peerReviewsRoute, writingPath(writing.ID, "/peer-reviews"), reviewer.ID, peerReviewRequest())
+	expectError(t, rec, http.StatusConflict, "PEER_REVIEW_EXISTS")
+}
+
+func TestListPeerReviewWritings(t *testing.T) {
+	c := newTestContainer(t)
+	alice := createTestUser(t, c.DB, "alice")
+	bob := createTestUser(t, c.DB, "bob")
+	carol := createTestUser(t, c.DB, "carol")
+	theme := createTestTheme(t, c.DB, 600)
+
+	at := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	long := strings.Repeat("あ", excerptLength) + "い"
+	older := createOpenWriting(t, c.DB, alice.ID, theme.ID, "短い", at.Add(-time.Hour))
+	tied := createOpenWriting(t, c.DB, carol.ID, theme.ID, long, at)
+	newer := createOpenWriting(t, c.DB, alice.ID, theme.ID, "ACIDは性質です。", at)
+	// Neither the caller's own writings, closed ones nor deleted ones are listed.
+	createOpenWriting(t, c.DB, bob.ID, theme.ID, "自分の文章", at)
+	createTestWriting(t, c.DB, alice.ID, theme.ID, "閉じた文章")
+	deleted := createOpenWriting(t, c.DB, alice.ID, theme.ID, "削除した文章", at)
+	if err := c.DB.Delete(&deleted).Error; err != nil {
+		t.Fatal(err)
+	}
+	mustCreate(t, c.DB, &models.GormPeerReview{WritingID: tied.ID, ReviewerID: bob.ID, RubricVersion: "default@v1", TotalScore: 70})
+	mustCreate(t, c.DB, &models.GormPeerReview{WritingID: tied.ID, ReviewerID: alice.ID, RubricVersion: "default@v1", TotalScore: 60})
+
+	list := func(query url.Values) ([]models.PeerReviewWritingSummary, string) {
+		rec := serve(t, c.ListPeerReviewWritings, http.MethodGet, "/peer-review/writings", "/peer-review/writings?"+query.Encode(), bob.ID, nil)
+		return decodeResponse[[]models.PeerReviewWritingSummary](t, rec, http.StatusOK), rec.Header().Get(nextCursorHeader)
+	}
+
+	// One writing per page, newest first and the later ID first among equal timestamps.
+	var got []models.PeerReviewWritingSummary
+	query := url.Values{"view": {"excerpt"}, "limit": {"1"}}
+	for pages := 0; pages < 5; pages++ {
+		writings, cursor := list(query)
+		got = append(got, writings...)
+		if cursor == "" {
+			break
+		}
+		query.Set("cursor", cursor)
+	}
+	var ids []uint
+	for _, w := range got {
+		ids = append(ids, uint(w.ID))
+	}
+	if want := []uint{newer.ID, tied.ID, older.ID}; !slices.Equal(ids, want) {
+		t.Fatalf("listed %v, want %v", ids, want)
+	}
+
+	summary := got[1]
+	if want := strings.Repeat("あ", excerptLength) + "…"; summary.Excerpt != want || summary.CharacterCount != excerptLength+1 {
+		t.Errorf("excerpt, characters = %q, %d, want the first %d characters with an ellipsis", summary.Excerpt, summary.CharacterCount, excerptLength)
+	}
+	if summary.PeerReviewCount != 2 || !summary.ReviewedByMe || summary.AuthorName == nil || *summary.AuthorName != "carol" {
+		t.Errorf("summary = %+v, want 2 peer reviews including mine, by carol", summary)
+	}
+	if got[2].Excerpt != "短い" || got[2].ReviewedByMe {
+		t.Errorf("summary = %+v, want the whole short content, not reviewed by me", got[2])
+	}
+
+	// The full view has the content instead.
+	rec := serve(t, c.ListPeerReviewWritings, http.MethodGet, "/peer-review/writings", "/peer-review/writings?themeId="+cursorInt(theme.ID), bob.ID, nil)
+	full := decodeResponse[[]models.PeerReviewWriting](t, rec, http.StatusOK)
+	if len(full) != 3 || full[1].Content != long || full[1].ThemeTitle != theme.Title {
+		t.Errorf("full view = %+v, want the content and theme title of all three writings", full)
+	}
+}
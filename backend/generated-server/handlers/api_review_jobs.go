@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/ch00z00/kotobalize/models"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// GetReviewJob - Get the status of an asynchronous review job
+func (c *Container) GetReviewJob(ctx *gin.Context) {
+	// Get jobId from path parameter
+	jobIDStr := ctx.Param("jobId")
+	jobID, err := strconv.ParseUint(jobIDStr, 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, models.APIError{Code: "INVALID_INPUT", Message: "Invalid review job ID format"})
+		return
+	}
+
+	// Get user ID from the context (set by the auth middleware)
+	userID, exists := ctx.Get("userId")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, models.APIError{Code: "UNAUTHORIZED", Message: "User ID not found in token"})
+		return
+	}
+
+	// Find the job, ensuring it belongs to the authenticated user.
+	var job models.GormReviewJob
+	if err := c.DB.Where("id = ? AND user_id = ?", jobID, userID).First(&job).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			ctx.JSON(http.StatusNotFound, models.APIError{Code: "REVIEW_JOB_NOT_FOUND", Message: "Review job not found or you don't have permission to view it"})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, models.APIError{Code: "DATABASE_ERROR", Message: "Failed to fetch review job"})
+		return
+	}
+
+	// Attach the reviewed writing once the job has finished successfully.
+	var apiWriting *models.Writing
+	if job.Status == models.ReviewJobStatusSucceeded {
+		var gormWriting models.GormWriting
+		if err := c.DB.First(&gormWriting, job.WritingID).Error; err == nil {
+			w := mapGormWritingToAPI(gormWriting)
+			apiWriting = &w
+		}
+	}
+
+	apiJob := mapGormReviewJobToAPI(job, apiWriting)
+	if job.FallbackReviewID != nil {
+		var fallback models.GormReview
+		if err := c.DB.First(&fallback, *job.FallbackReviewID).Error; err == nil {
+			apiFallback := mapGormReviewToAPI(fallback)
+			apiJob.FallbackReview = &apiFallback
+		}
+	}
+
+	ctx.JSON(http.StatusOK, apiJob)
+}
+
+// mapGormReviewJobToAPI converts a GORM review job to an API review job.
+func mapGormReviewJobToAPI(job models.GormReviewJob, writing *models.Writing) models.ReviewJob {
+	apiJob := models.ReviewJob{
+		ID:         int64(job.ID),
+		WritingID:  int64(job.WritingID),
+		Status:     job.Status,
+		Attempts:   job.Attempts,
+		Cached:     job.Cached,
+		Writing:    writing,
+		CreatedAt:  job.CreatedAt,
+		UpdatedAt:  job.UpdatedAt,
+		StartedAt:  job.StartedAt,
+		FinishedAt: job.FinishedAt,
+	}
+	if job.Status == models.ReviewJobStatusQueued {
+		apiJob.NextRunAt = job.NextRunAt
+	}
+	if job.LastError != nil {
+		apiJob.Error = *job.LastError
+	}
+	if job.LastErrorCode != nil {
+		apiJob.ErrorCode = *job.LastErrorCode
+	}
+	return apiJob
+}
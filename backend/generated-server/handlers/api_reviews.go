@@ -0,0 +1,108 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/ch00z00/kotobalize/models"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// ListWritingReviews - Get every AI review run of a writing, newest first
+func (c *Container) ListWritingReviews(ctx *gin.Context) {
+	// Get writingId from path parameter
+	writingIDStr := ctx.Param("writingId")
+	writingID, err := strconv.ParseUint(writingIDStr, 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, models.APIError{Code: "INVALID_INPUT", Message: "Invalid writing ID format"})
+		return
+	}
+
+	// Get user ID from the context (set by the auth middleware)
+	userID, exists := ctx.Get("userId")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, models.APIError{Code: "UNAUTHORIZED", Message: "User ID not found in token"})
+		return
+	}
+
+	// Ensure the writing exists and belongs to the authenticated user or one of their mentees.
+	var gormWriting models.GormWriting
+	if err := c.DB.Where("id = ?", writingID).Scopes(c.visibleWritings(userID.(uint))).First(&gormWriting).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			ctx.JSON(http.StatusNotFound, models.APIError{Code: "WRITING_NOT_FOUND", Message: "Writing not found or you don't have permission to view it"})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, models.APIError{Code: "DATABASE_ERROR", Message: "Failed to fetch writing"})
+		return
+	}
+
+	var gormReviews []models.GormReview
+	if err := c.DB.Where("writing_id = ?", gormWriting.ID).Order("created_at desc, id desc").Find(&gormReviews).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, models.APIError{Code: "DATABASE_ERROR", Message: "Failed to fetch reviews"})
+		return
+	}
+
+	apiReviews := make([]models.Review, len(gormReviews))
+	for i, r := range gormReviews {
+		apiReviews[i] = mapGormReviewToAPI(r)
+	}
+
+	ctx.JSON(http.StatusOK, apiReviews)
+}
+
+// mapGormReviewToAPI converts a GORM review model to an API review model.
+func mapGormReviewToAPI(gormReview models.GormReview) models.Review {
+	return models.Review{
+		ID:               int64(gormReview.ID),
+		WritingID:        int64(gormReview.WritingID),
+		Model:            gormReview.ModelName,
+		RubricID:         gormReview.RubricID,
+		PromptVersion:    gormReview.PromptVersion,
+		LatencyMs:        gormReview.LatencyMs,
+		PromptTokens:     gormReview.PromptTokens,
+		CompletionTokens: gormReview.CompletionTokens,
+		TotalTokens:      gormReview.TotalTokens,
+		TotalScore:       gormReview.TotalScore,
+		ScoreCapped:      gormReview.ScoreCapped,
+		Provisional:      gormReview.Provisional,
+		Feedback:         json.RawMessage(gormReview.Feedback),
+		Annotations:      decodeAnnotations(gormReview.Annotations),
+		Coverage:         decodeCoverage(gormReview.Coverage),
+		RawResponse:      gormReview.RawResponse,
+		Cached:           gormReview.CachedFromID != nil,
+		CachedFromID:     gormReview.CachedFromID,
+		RevisionID:       gormReview.RevisionID,
+		CreatedAt:        gormReview.CreatedAt,
+	}
+}
+
+// decodeAnnotations decodes the stored annotations of a review.
+// Reviews recorded before annotations existed have none.
+func decodeAnnotations(data []byte) []models.Annotation {
+	annotations := []models.Annotation{}
+	if len(data) == 0 {
+		return annotations
+	}
+	if err := json.Unmarshal(data, &annotations); err != nil {
+		log.Printf("failed to decode review annotations: %v", err)
+		return []models.Annotation{}
+	}
+	return annotations
+}
+
+// decodeCoverage decodes the stored key point coverage of a review.
+func decodeCoverage(data []byte) []models.KeyPointCoverage {
+	coverage := []models.KeyPointCoverage{}
+	if len(data) == 0 {
+		return coverage
+	}
+	if err := json.Unmarshal(data, &coverage); err != nil {
+		log.Printf("failed to decode review coverage: %v", err)
+		return []models.KeyPointCoverage{}
+	}
+	return coverage
+}
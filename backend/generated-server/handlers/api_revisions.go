@@ -0,0 +1,228 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+	"unicode/utf8"
+
+	"github.com/ch00z00/kotobalize/models"
+	"github.com/ch00z00/kotobalize/services"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ListWritingRevisions - List every saved version of a writing, oldest first
+//
+// Revisions are listed without their content. Each carries the score of its latest AI
+// review, so the effect of an edit on the score can be seen.
+func (c *Container) ListWritingRevisions(ctx *gin.Context) {
+	gormWriting, _, ok := c.findMentoredWriting(ctx)
+	if !ok {
+		return
+	}
+
+	var rows []revisionSummaryRow
+	if err := c.DB.Model(&models.GormWritingRevision{}).
+		Select("id, writing_id, number, duration_seconds, CHAR_LENGTH(content) AS character_count, draft, created_at").
+		Where("writing_id = ?", gormWriting.ID).
+		Order("number asc").
+		Find(&rows).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, models.APIError{Code: "DATABASE_ERROR", Message: "Failed to fetch revisions"})
+		return
+	}
+	latestReviews, err := c.latestRevisionReviews(gormWriting.ID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, models.APIError{Code: "DATABASE_ERROR", Message: "Failed to fetch reviews"})
+		return
+	}
+
+	revisions := make([]models.WritingRevisionSummary, len(rows))
+	for i, r := range rows {
+		revisions[i] = models.WritingRevisionSummary{
+			ID:              int64(r.ID),
+			WritingID:       int64(r.WritingID),
+			Number:          r.Number,
+			DurationSeconds: int32(r.DurationSeconds),
+			CharacterCount:  r.CharacterCount,
+			Draft:           r.Draft,
+			CreatedAt:       r.CreatedAt,
+		}
+		if review, ok := latestReviews[r.ID]; ok {
+			score := review.TotalScore
+			revisions[i].ReviewID = &review.ID
+			revisions[i].AiScore = &score
+		}
+	}
+
+	ctx.JSON(http.StatusOK, revisions)
+}
+
+// DiffWritingRevisions - Compare two revisions of a writing
+//
+// The base and target query parameters are revision numbers.
+func (c *Container) DiffWritingRevisions(ctx *gin.Context) {
+	baseNumber, baseErr := strconv.Atoi(ctx.Query("base"))
+	targetNumber, targetErr := strconv.Atoi(ctx.Query("target"))
+	if baseErr != nil || targetErr != nil {
+		ctx.JSON(http.StatusBadRequest, models.APIError{Code: "INVALID_INPUT", Message: "base and target must be revision numbers"})
+		return
+	}
+	if baseNumber == targetNumber {
+		ctx.JSON(http.StatusBadRequest, models.APIError{Code: "INVALID_INPUT", Message: "base and target must be different revisions"})
+		return
+	}
+
+	gormWriting, _, ok := c.findMentoredWriting(ctx)
+	if !ok {
+		return
+	}
+
+	// Only the two compared revisions are loaded with their content.
+	var gormRevisions []models.GormWritingRevision
+	if err := c.DB.Where("writing_id = ? AND number IN ?", gormWriting.ID, []int{baseNumber, targetNumber}).
+		Find(&gormRevisions).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, models.APIError{Code: "DATABASE_ERROR", Message: "Failed to fetch revisions"})
+		return
+	}
+	latestReviews, err := c.latestRevisionReviews(gormWriting.ID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, models.APIError{Code: "DATABASE_ERROR", Message: "Failed to fetch reviews"})
+		return
+	}
+	var base, target *models.WritingRevision
+	for _, r := range gormRevisions {
+		revision := mapGormRevisionToAPI(r)
+		if review, ok := latestReviews[r.ID]; ok {
+			score := review.TotalScore
+			revision.ReviewID = &review.ID
+			revision.AiScore = &score
+		}
+		switch r.Number {
+		case baseNumber:
+			base = &revision
+		case targetNumber:
+			target = &revision
+		}
+	}
+	if base == nil || target == nil {
+		ctx.JSON(http.StatusNotFound, models.APIError{Code: "REVISION_NOT_FOUND", Message: "Revision not found"})
+		return
+	}
+
+	diff := models.RevisionDiff{
+		WritingID: int64(gormWriting.ID),
+		Base:      *base,
+		Target:    *target,
+		Diff:      []models.DiffSegment{},
+	}
+	if base.AiScore != nil && target.AiScore != nil {
+		delta := *target.AiScore - *base.AiScore
+		diff.ScoreDelta = &delta
+	}
+	for _, segment := range services.DiffSentences(base.Content, target.Content) {
+		diff.Diff = append(diff.Diff, models.DiffSegment{Op: segment.Op, Text: segment.Text})
+	}
+
+	ctx.JSON(http.StatusOK, diff)
+}
+
+// revisionSummaryRow is a row of ListWritingRevisions.
+type revisionSummaryRow struct {
+	ID              uint
+	WritingID       uint
+	Number          int
+	DurationSeconds int
+	CharacterCount  int
+	Draft           bool
+	CreatedAt       time.Time
+}
+
+// latestRevisionReviews loads the latest AI review of each revision of a writing, keyed
+// by revision ID. Only the ID and total score of the reviews are loaded.
+func (c *Container) latestRevisionReviews(writingID uint) (map[uint]models.GormReview, error) {
+	// Provisional reviews are left out, as they do not change the writing's score either.
+	var gormReviews []models.GormReview
+	if err := c.DB.Select("id", "revision_id", "total_score").
+		Where("writing_id = ? AND revision_id IS NOT NULL AND provisional = ?", writingID, false).
+		Order("id asc").
+		Find(&gormReviews).Error; err != nil {
+		return nil, err
+	}
+	latestReviews := make(map[uint]models.GormReview, len(gormReviews))
+	for _, r := range gormReviews {
+		latestReviews[*r.RevisionID] = r
+	}
+	return latestReviews, nil
+}
+
+// saveRevision snapshots the content of a writing as its next revision and makes it the
+// writing's latest revision. Call it within the transaction that saves the content.
+//
+// While the latest revision is a draft revision, it is updated in place instead, so that
+// autosaving a draft keeps a single revision of it; saving the submitted content closes it.
+func saveRevision(tx *gorm.DB, gormWriting *models.GormWriting) error {
+	if err := lockWriting(tx, gormWriting); err != nil {
+		return err
+	}
+	draft := gormWriting.Status == models.WritingStatusDraft
+	if gormWriting.LatestRevisionID != nil {
+		var latest models.GormWritingRevision
+		if err := tx.Select("id", "draft").First(&latest, *gormWriting.LatestRevisionID).Error; err != nil {
+			return err
+		}
+		if latest.Draft {
+			return tx.Model(&latest).
+				Select("content", "duration_seconds", "draft").
+				Updates(models.GormWritingRevision{Content: gormWriting.Content, DurationSeconds: gormWriting.DurationSeconds, Draft: draft}).Error
+		}
+	}
+
+	var number int
+	if err := tx.Model(&models.GormWritingRevision{}).
+		Where("writing_id = ?", gormWriting.ID).
+		Select("COALESCE(MAX(number), 0)").
+		Scan(&number).Error; err != nil {
+		return err
+	}
+
+	revision := models.GormWritingRevision{
+		WritingID:       gormWriting.ID,
+		Number:          number + 1,
+		Content:         gormWriting.Content,
+		DurationSeconds: gormWriting.DurationSeconds,
+		Draft:           draft,
+	}
+	if err := tx.Create(&revision).Error; err != nil {
+		return err
+	}
+	gormWriting.LatestRevisionID = &revision.ID
+	return tx.Model(gormWriting).Update("latest_revision_id", revision.ID).Error
+}
+
+// lockWriting locks the row of a writing until the end of the transaction and reloads its
+// latest revision, so that concurrent saves number their revisions one after another and
+// each sees the revision saved by the one before.
+func lockWriting(tx *gorm.DB, gormWriting *models.GormWriting) error {
+	var locked models.GormWriting
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id", "latest_revision_id").First(&locked, gormWriting.ID).Error; err != nil {
+		return err
+	}
+	gormWriting.LatestRevisionID = locked.LatestRevisionID
+	return nil
+}
+
+// mapGormRevisionToAPI converts a GORM writing revision model to an API writing revision model.
+func mapGormRevisionToAPI(gormRevision models.GormWritingRevision) models.WritingRevision {
+	return models.WritingRevision{
+		ID:              int64(gormRevision.ID),
+		WritingID:       int64(gormRevision.WritingID),
+		Number:          gormRevision.Number,
+		Content:         gormRevision.Content,
+		DurationSeconds: int32(gormRevision.DurationSeconds),
+		CharacterCount:  utf8.RuneCountInString(gormRevision.Content),
+		Draft:           gormRevision.Draft,
+		CreatedAt:       gormRevision.CreatedAt,
+	}
+}
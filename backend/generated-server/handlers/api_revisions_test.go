@@ -0,0 +1,108 @@
+package handlers
+
+import (
+	"testing"
+
+	"github.com/ch00z00/kotobalize/models"
+	"gorm.io/gorm"
+)
+
+// revisionsOf returns the revisions of a writing by number.
+func revisionsOf(t *testing.T, db *gorm.DB, writingID uint) []models.GormWritingRevision {
+	t.Helper()
+	var revisions []models.GormWritingRevision
+	if err := db.Where("writing_id = ?", writingID).Order("number").Find(&revisions).Error; err != nil {
+		t.Fatal(err)
+	}
+	return revisions
+}
+
+func TestSaveRevisionUpdatesDraftInPlace(t *testing.T) {
+	c := newTestContainer(t)
+	user := createTestUser(t, c.DB, "alice")
+	theme := createTestTheme(t, c.DB, 600)
+	writing := models.GormWriting{UserID: user.ID, ThemeID: theme.ID, Content: "書き", Status: models.WritingStatusDraft}
+	mustCreate(t, c.DB, &writing)
+
+	// Autosaves of a draft keep one revision, and submitting closes it.
+	for _, content := range []string{"書きかけ", "書きかけの文章", "書き上げた文章"} {
+		writing.Content = content
+		if content == "書き上げた文章" {
+			writing.Status = models.WritingStatusSubmitted
+		}
+		if err := c.saveWritingChanges(&writing, true); err != nil {
+			t.Fatal(err)
+		}
+	}
+	revisions := revisionsOf(t, c.DB, writing.ID)
+	if len(revisions) != 1 || revisions[0].Number != 1 || revisions[0].Draft || revisions[0].Content != "書き上げた文章" {
+		t.Fatalf("revisions = %+v, want one closed revision with the submitted content", revisions)
+	}
+
+	// An edit after submitting is a new revision.
+	writing.Content = "直した文章"
+	if err := c.saveWritingChanges(&writing, true); err != nil {
+		t.Fatal(err)
+	}
+	revisions = revisionsOf(t, c.DB, writing.ID)
+	if len(revisions) != 2 || revisions[1].Number != 2 || revisions[1].Content != "直した文章" || *writing.LatestRevisionID != revisions[1].ID {
+		t.Errorf("revisions = %+v with latest %d, want a second revision as the latest", revisions, *writing.LatestRevisionID)
+	}
+}
+
+func TestSaveRevisionSeesConcurrentSaves(t *testing.T) {
+	c := newTestContainer(t)
+	user := createTestUser(t, c.DB, "alice")
+	theme := createTestTheme(t, c.DB, 600)
+	writing := models.GormWriting{UserID: user.ID, ThemeID: theme.ID, Content: "下書き", Status: models.WritingStatusDraft}
+	mustCreate(t, c.DB, &writing)
+
+	// Two requests load the draft before either saves it.
+	first, second := writing, writing
+	first.Content = "一つ目の保存"
+	second.Content = "二つ目の保存"
+	if err := c.saveWritingChanges(&first, true); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.saveWritingChanges(&second, true); err != nil {
+		t.Fatal(err)
+	}
+	revisions := revisionsOf(t, c.DB, writing.ID)
+	if len(revisions) != 1 || revisions[0].Content != "二つ目の保存" {
+		t.Errorf("revisions = %+v, want the second save to update the draft revision of the first", revisions)
+	}
+}
+
+func TestEnsureRevisionOfLegacyWriting(t *testing.T) {
+	c := newTestContainer(t)
+	user := createTestUser(t, c.DB, "alice")
+	theme := createTestTheme(t, c.DB, 600)
+	// Writings created before revisions were kept have none.
+	writing := models.GormWriting{UserID: user.ID, ThemeID: theme.ID, Content: "昔の文章", DurationSeconds: 300, Status: models.WritingStatusSubmitted}
+	mustCreate(t, c.DB, &writing)
+	stale := writing
+
+	ensure := func(w *models.GormWriting) {
+		t.Helper()
+		if err := c.DB.Transaction(func(tx *gorm.DB) error { return ensureRevision(tx, w) }); err != nil {
+			t.Fatal(err)
+		}
+	}
+	ensure(&writing)
+	revisions := revisionsOf(t, c.DB, writing.ID)
+	if len(revisions) != 1 || revisions[0].Number != 1 || revisions[0].Content != "昔の文章" || revisions[0].DurationSeconds != 300 || revisions[0].Draft {
+		t.Fatalf("revisions = %+v, want a closed snapshot of the writing", revisions)
+	}
+	if writing.LatestRevisionID == nil || *writing.LatestRevisionID != revisions[0].ID {
+		t.Errorf("latest revision = %v, want %d", writing.LatestRevisionID, revisions[0].ID)
+	}
+
+	// A review of the same writing that was loaded before the snapshot reuses it.
+	ensure(&stale)
+	if revisions := revisionsOf(t, c.DB, writing.ID); len(revisions) != 1 {
+		t.Errorf("revisions = %+v, want the one snapshot", revisions)
+	}
+	if stale.LatestRevisionID == nil || *stale.LatestRevisionID != revisions[0].ID {
+		t.Errorf("latest revision of the stale copy = %v, want %d", stale.LatestRevisionID, revisions[0].ID)
+	}
+}
@@ -0,0 +1,185 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ch00z00/kotobalize/models"
+	"github.com/ch00z00/kotobalize/services"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// RewriteWriting - Generate an improved version of a writing and a sentence-level diff
+//
+// If the writing already has a rewrite for its current content and latest review,
+// that rewrite is returned with 200 instead of calling the AI again.
+func (c *Container) RewriteWriting(ctx *gin.Context) {
+	// Get writingId from path parameter
+	writingIDStr := ctx.Param("writingId")
+	writingID, err := strconv.ParseUint(writingIDStr, 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, models.APIError{Code: "INVALID_INPUT", Message: "Invalid writing ID format"})
+		return
+	}
+
+	// Get user ID from the context (set by the auth middleware)
+	userID, exists := ctx.Get("userId")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, models.APIError{Code: "UNAUTHORIZED", Message: "User ID not found in token"})
+		return
+	}
+
+	// Find the writing, ensuring it belongs to the authenticated user.
+	var gormWriting models.GormWriting
+	if err := c.DB.Preload("Theme").Where("id = ? AND user_id = ?", writingID, userID).First(&gormWriting).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			ctx.JSON(http.StatusNotFound, models.APIError{Code: "WRITING_NOT_FOUND", Message: "Writing not found or you don't have permission to view it"})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, models.APIError{Code: "DATABASE_ERROR", Message: "Failed to fetch writing"})
+		return
+	}
+	if !requireSubmitted(ctx, gormWriting) {
+		return
+	}
+
+	// Reuse the stored rewrite when neither the content nor the review has changed since.
+	var existing models.GormRewrite
+	err = c.DB.Where("writing_id = ?", gormWriting.ID).Order("id desc").First(&existing).Error
+	if err == nil && existing.OriginalContent == gormWriting.Content && sameID(existing.ReviewID, gormWriting.LatestReviewID) {
+		ctx.JSON(http.StatusOK, mapGormRewriteToAPI(existing))
+		return
+	}
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		ctx.JSON(http.StatusInternalServerError, models.APIError{Code: "DATABASE_ERROR", Message: "Failed to fetch rewrite"})
+		return
+	}
+
+	// The rewrite addresses the latest review, if there is one.
+	var review *services.AIReviewResponse
+	if gormWriting.LatestReviewID != nil {
+		var latestReview models.GormReview
+		if err := c.DB.First(&latestReview, *gormWriting.LatestReviewID).Error; err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			ctx.JSON(http.StatusInternalServerError, models.APIError{Code: "DATABASE_ERROR", Message: "Failed to fetch review"})
+			return
+		} else if err == nil {
+			review = &services.AIReviewResponse{}
+			if err := json.Unmarshal(latestReview.Feedback, review); err != nil {
+				log.Printf("failed to decode review %d for rewrite: %v", latestReview.ID, err)
+				review = nil
+			}
+		}
+	}
+
+	if err := c.checkQuota(gormWriting.UserID); err != nil {
+		status, apiErr := aiErrorResponse(err)
+		ctx.JSON(status, apiErr)
+		return
+	}
+
+	input, err := c.reviewInput(&gormWriting)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, models.APIError{Code: "DATABASE_ERROR", Message: err.Error()})
+		return
+	}
+
+	startedAt := time.Now()
+	result, err := c.Reviewer.Rewrite(ctx.Request.Context(), input, review)
+	if err != nil {
+		c.recordFailedAIUsage(gormWriting.UserID, models.AIUsageKindRewrite, err)
+		status, apiErr := aiErrorResponse(err)
+		ctx.JSON(status, apiErr)
+		return
+	}
+	latency := time.Since(startedAt)
+
+	diffJSON, err := json.Marshal(services.DiffSentences(gormWriting.Content, result.Content))
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, models.APIError{Code: "INTERNAL_ERROR", Message: "Failed to serialize diff"})
+		return
+	}
+
+	rewrite := models.GormRewrite{
+		WritingID:        gormWriting.ID,
+		UserID:           gormWriting.UserID,
+		ReviewID:         gormWriting.LatestReviewID,
+		ModelName:        result.Model,
+		LatencyMs:        latency.Milliseconds(),
+		PromptTokens:     result.Usage.PromptTokens,
+		CompletionTokens: result.Usage.CompletionTokens,
+		TotalTokens:      result.Usage.TotalTokens,
+		OriginalContent:  gormWriting.Content,
+		Content:          result.Content,
+		RawResponse:      result.Raw,
+		Diff:             diffJSON,
+	}
+	err = c.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&rewrite).Error; err != nil {
+			return err
+		}
+		return recordAIUsage(tx, rewrite.UserID, models.AIUsageKindRewrite, rewrite.ModelName, result.Usage, nil)
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, models.APIError{Code: "DATABASE_ERROR", Message: "Failed to save rewrite"})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, mapGormRewriteToAPI(rewrite))
+}
+
+// GetWritingRewrite - Get the latest stored rewrite of a writing
+//
+// Both the author and their mentors can read the rewrite.
+func (c *Container) GetWritingRewrite(ctx *gin.Context) {
+	gormWriting, _, ok := c.findMentoredWriting(ctx)
+	if !ok {
+		return
+	}
+
+	var rewrite models.GormRewrite
+	if err := c.DB.Where("writing_id = ?", gormWriting.ID).Order("id desc").First(&rewrite).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			ctx.JSON(http.StatusNotFound, models.APIError{Code: "REWRITE_NOT_FOUND", Message: "No rewrite found for this writing"})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, models.APIError{Code: "DATABASE_ERROR", Message: "Failed to fetch rewrite"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, mapGormRewriteToAPI(rewrite))
+}
+
+// mapGormRewriteToAPI converts a GORM rewrite model to an API rewrite model.
+func mapGormRewriteToAPI(gormRewrite models.GormRewrite) models.Rewrite {
+	diff := []models.DiffSegment{}
+	if len(gormRewrite.Diff) > 0 {
+		if err := json.Unmarshal(gormRewrite.Diff, &diff); err != nil {
+			log.Printf("failed to decode diff of rewrite %d: %v", gormRewrite.ID, err)
+		}
+	}
+	return models.Rewrite{
+		ID:              int64(gormRewrite.ID),
+		WritingID:       int64(gormRewrite.WritingID),
+		ReviewID:        gormRewrite.ReviewID,
+		Model:           gormRewrite.ModelName,
+		LatencyMs:       gormRewrite.LatencyMs,
+		TotalTokens:     gormRewrite.TotalTokens,
+		OriginalContent: gormRewrite.OriginalContent,
+		Content:         gormRewrite.Content,
+		Diff:            diff,
+		CreatedAt:       gormRewrite.CreatedAt,
+	}
+}
+
+// sameID reports whether two optional IDs are equal.
+func sameID(a, b *uint) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
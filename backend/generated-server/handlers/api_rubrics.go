@@ -0,0 +1,208 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/ch00z00/kotobalize/models"
+	"github.com/ch00z00/kotobalize/services"
+	"github.com/gin-gonic/gin"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// ListRubrics - Get a list of the rubrics available to the user
+func (c *Container) ListRubrics(ctx *gin.Context) {
+	// Get user ID from the context (set by the auth middleware)
+	userID, exists := ctx.Get("userId")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, models.APIError{Code: "UNAUTHORIZED", Message: "User ID not found in token"})
+		return
+	}
+
+	// Official rubrics and the user's own rubrics, newest versions first.
+	var gormRubrics []models.GormRubric
+	if err := c.DB.Where("creator_id IS NULL OR creator_id = ?", userID).
+		Order("slug asc, version desc").
+		Find(&gormRubrics).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, models.APIError{Code: "DATABASE_ERROR", Message: "Failed to fetch rubrics"})
+		return
+	}
+
+	apiRubrics := make([]models.Rubric, len(gormRubrics))
+	for i, r := range gormRubrics {
+		apiRubrics[i] = mapGormRubricToAPI(r)
+	}
+
+	ctx.JSON(http.StatusOK, apiRubrics)
+}
+
+// GetRubricByID - Get details of a specific rubric by ID
+func (c *Container) GetRubricByID(ctx *gin.Context) {
+	// Get rubricId from path parameter
+	rubricIDStr := ctx.Param("rubricId")
+	rubricID, err := strconv.ParseUint(rubricIDStr, 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, models.APIError{Code: "INVALID_INPUT", Message: "Invalid rubric ID format"})
+		return
+	}
+
+	// Get user ID from the context (set by the auth middleware)
+	userID, exists := ctx.Get("userId")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, models.APIError{Code: "UNAUTHORIZED", Message: "User ID not found in token"})
+		return
+	}
+
+	var gormRubric models.GormRubric
+	if err := c.DB.Where("id = ? AND (creator_id IS NULL OR creator_id = ?)", rubricID, userID).First(&gormRubric).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			ctx.JSON(http.StatusNotFound, models.APIError{Code: "RUBRIC_NOT_FOUND", Message: "Rubric not found or you don't have permission to view it"})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, models.APIError{Code: "DATABASE_ERROR", Message: "Failed to fetch rubric"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, mapGormRubricToAPI(gormRubric))
+}
+
+// CreateRubric - Create a rubric, or a new version of an existing rubric with the same slug
+func (c *Container) CreateRubric(ctx *gin.Context) {
+	// Get user ID from the context, which is set by the authentication middleware.
+	userIDVal, exists := ctx.Get("userId")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, models.APIError{Code: "UNAUTHORIZED", Message: "User not authenticated"})
+		return
+	}
+	userID, ok := userIDVal.(uint)
+	if !ok {
+		ctx.JSON(http.StatusInternalServerError, models.APIError{Code: "INTERNAL_ERROR", Message: "Invalid user ID type in context"})
+		return
+	}
+
+	var req models.NewRubricRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, models.APIError{Code: "BAD_REQUEST", Message: "Invalid request body: " + err.Error()})
+		return
+	}
+
+	// Viewpoints without a weight count once.
+	for i := range req.Viewpoints {
+		if req.Viewpoints[i].Weight == 0 {
+			req.Viewpoints[i].Weight = 1
+		}
+	}
+
+	gormRubric := models.GormRubric{
+		Slug:        req.Slug,
+		Name:        req.Name,
+		Description: req.Description,
+		Viewpoints:  datatypes.NewJSONSlice(req.Viewpoints),
+		CreatorID:   &userID,
+	}
+	if err := toServiceRubric(gormRubric).Validate(); err != nil {
+		ctx.JSON(http.StatusBadRequest, models.APIError{Code: "INVALID_RUBRIC", Message: err.Error()})
+		return
+	}
+
+	err := c.DB.Transaction(func(tx *gorm.DB) error {
+		// A slug belongs to whoever created it first; official slugs cannot be versioned by users.
+		var latest models.GormRubric
+		err := tx.Unscoped().Where("slug = ?", req.Slug).Order("version desc").First(&latest).Error
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			gormRubric.Version = 1
+		case err != nil:
+			return err
+		case latest.CreatorID == nil || *latest.CreatorID != userID:
+			return errRubricSlugTaken
+		default:
+			gormRubric.Version = latest.Version + 1
+		}
+		return tx.Create(&gormRubric).Error
+	})
+	if errors.Is(err, errRubricSlugTaken) {
+		ctx.JSON(http.StatusConflict, models.APIError{Code: "RUBRIC_SLUG_TAKEN", Message: "A rubric with this slug is owned by someone else"})
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, models.APIError{Code: "DATABASE_ERROR", Message: "Failed to save rubric"})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, mapGormRubricToAPI(gormRubric))
+}
+
+// errRubricSlugTaken is returned when a user tries to version someone else's rubric.
+var errRubricSlugTaken = errors.New("rubric slug is owned by another user")
+
+// checkRubricAccess reports whether the user may attach the rubric to a theme.
+func (c *Container) checkRubricAccess(rubricID, userID uint) error {
+	var count int64
+	if err := c.DB.Model(&models.GormRubric{}).
+		Where("id = ? AND (creator_id IS NULL OR creator_id = ?)", rubricID, userID).
+		Count(&count).Error; err != nil {
+		return err
+	}
+	if count == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// resolveRubric returns the rubric used to review writings on a theme:
+// the theme's own rubric, or else the latest default rubric, or else the built-in one.
+func (c *Container) resolveRubric(theme models.GormTheme) (services.Rubric, error) {
+	var gormRubric models.GormRubric
+	var err error
+	if theme.RubricID != nil {
+		// Unscoped so that reviews keep working if the rubric is soft-deleted later.
+		err = c.DB.Unscoped().First(&gormRubric, *theme.RubricID).Error
+	} else {
+		err = c.DB.Where("is_default = ?", true).Order("version desc").First(&gormRubric).Error
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return services.DefaultRubric(), nil
+	}
+	if err != nil {
+		return services.Rubric{}, err
+	}
+	return toServiceRubric(gormRubric), nil
+}
+
+// toServiceRubric converts a GORM rubric to the rubric used by the reviewer.
+func toServiceRubric(gormRubric models.GormRubric) services.Rubric {
+	viewpoints := make([]services.RubricViewpoint, len(gormRubric.Viewpoints))
+	for i, vp := range gormRubric.Viewpoints {
+		viewpoints[i] = services.RubricViewpoint{
+			Key:         vp.Key,
+			Name:        vp.Name,
+			Description: vp.Description,
+			Weight:      vp.Weight,
+		}
+	}
+	return services.Rubric{
+		ID:         gormRubric.ID,
+		Slug:       gormRubric.Slug,
+		Version:    gormRubric.Version,
+		Name:       gormRubric.Name,
+		Viewpoints: viewpoints,
+	}
+}
+
+// mapGormRubricToAPI converts a GORM rubric model to an API rubric model.
+func mapGormRubricToAPI(gormRubric models.GormRubric) models.Rubric {
+	return models.Rubric{
+		ID:          int64(gormRubric.ID),
+		Slug:        gormRubric.Slug,
+		Version:     gormRubric.Version,
+		Name:        gormRubric.Name,
+		Description: gormRubric.Description,
+		Viewpoints:  gormRubric.Viewpoints,
+		IsDefault:   gormRubric.IsDefault,
+		CreatorID:   gormRubric.CreatorID,
+		CreatedAt:   gormRubric.CreatedAt,
+	}
+}
@@ -0,0 +1,175 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/ch00z00/kotobalize/models"
+	"github.com/ch00z00/kotobalize/services"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const (
+	// defaultSearchLimit and maxSearchLimit bound the limit query parameter of SearchWritings.
+	defaultSearchLimit = 20
+	maxSearchLimit     = 50
+	// minSearchLength matches the default ngram_token_size of MySQL: shorter queries find nothing.
+	minSearchLength = 2
+	maxSearchLength = 100
+	// snippetRadius is the number of characters shown on each side of the first match.
+	snippetRadius = 40
+	// themeTitleWeight ranks matches in the theme title above matches in the content.
+	themeTitleWeight = 2
+)
+
+// searchIndexes are the ngram full-text indexes used by SearchWritings. MATCH needs an
+// index on exactly the columns it searches, so each searched column has its own.
+var searchIndexes = []struct {
+	model  any
+	table  string
+	name   string
+	column string
+}{
+	{&models.GormWriting{}, "gorm_writings", "idx_writings_content_fulltext", "content"},
+	{&models.GormWriting{}, "gorm_writings", "idx_writings_feedback_fulltext", "feedback_text"},
+	{&models.GormTheme{}, "gorm_themes", "idx_themes_title_fulltext", "title"},
+}
+
+// MigrateSearchIndexes creates the full-text indexes used by the search, which GORM
+// cannot declare, and fills FeedbackText for writings reviewed before it existed.
+// It must run after AutoMigrate.
+func MigrateSearchIndexes(db *gorm.DB) error {
+	for _, index := range searchIndexes {
+		if db.Migrator().HasIndex(index.model, index.name) {
+			continue
+		}
+		// The ngram parser splits text into two-character tokens, so Japanese text without
+		// spaces can be searched.
+		sql := fmt.Sprintf("CREATE FULLTEXT INDEX %s ON %s (%s) WITH PARSER ngram", index.name, index.table, index.column)
+		if err := db.Exec(sql).Error; err != nil {
+			return fmt.Errorf("failed to create full-text index %s: %w", index.name, err)
+		}
+	}
+
+	var batch []models.GormWriting
+	return db.Select("id", "ai_feedback").
+		Where("feedback_text IS NULL AND ai_feedback IS NOT NULL").
+		FindInBatches(&batch, 100, func(_ *gorm.DB, _ int) error {
+			for _, w := range batch {
+				if err := db.Model(&w).UpdateColumn("feedback_text", feedbackSearchText(w.AIFeedback)).Error; err != nil {
+					return fmt.Errorf("failed to index feedback of writing %d: %w", w.ID, err)
+				}
+			}
+			return nil
+		}).Error
+}
+
+// feedbackSearchText returns a pointer to the searchable text of a stored review. It is
+// never nil: a review that cannot be decoded or has no points gets an empty text, which
+// marks the writing as indexed so that MigrateSearchIndexes does not fill it again.
+func feedbackSearchText(feedback []byte) *string {
+	text := ""
+	var review services.AIReviewResponse
+	if err := json.Unmarshal(feedback, &review); err == nil {
+		text = services.FeedbackSearchText(&review)
+	} else {
+		log.Printf("failed to decode feedback for search: %v", err)
+	}
+	return &text
+}
+
+// searchRow is a row of the search query.
+type searchRow struct {
+	ID           uint
+	ThemeID      uint
+	ThemeTitle   string
+	Content      string
+	FeedbackText *string
+	Status       string
+	AIScore      *int
+	CreatedAt    time.Time
+	Relevance    float64
+}
+
+// SearchWritings - Search the authenticated user's writings, their theme titles and AI feedback
+//
+// Results are ranked by full-text relevance, with theme titles weighted above content and
+// feedback, and include highlighted snippets of every field that matched.
+func (c *Container) SearchWritings(ctx *gin.Context) {
+	query := strings.TrimSpace(ctx.Query("q"))
+	if n := utf8.RuneCountInString(query); n < minSearchLength || n > maxSearchLength {
+		ctx.JSON(http.StatusBadRequest, models.APIError{Code: "INVALID_INPUT", Message: fmt.Sprintf("q must be between %d and %d characters", minSearchLength, maxSearchLength)})
+		return
+	}
+	limit := defaultSearchLimit
+	if limitStr := ctx.Query("limit"); limitStr != "" {
+		var err error
+		limit, err = strconv.Atoi(limitStr)
+		if err != nil || limit < 1 || limit > maxSearchLimit {
+			ctx.JSON(http.StatusBadRequest, models.APIError{Code: "INVALID_INPUT", Message: fmt.Sprintf("limit must be between 1 and %d", maxSearchLimit)})
+			return
+		}
+	}
+
+	// Get user ID from the context (set by the auth middleware)
+	userID, exists := ctx.Get("userId")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, models.APIError{Code: "UNAUTHORIZED", Message: "User ID not found in token"})
+		return
+	}
+
+	var rows []searchRow
+	if err := c.DB.Table("gorm_writings").
+		Select("gorm_writings.id, gorm_writings.theme_id, gorm_themes.title AS theme_title, "+
+			"gorm_writings.content, gorm_writings.feedback_text, gorm_writings.status, gorm_writings.ai_score, gorm_writings.created_at, "+
+			"MATCH(gorm_writings.content) AGAINST (?) + MATCH(gorm_writings.feedback_text) AGAINST (?) + ? * MATCH(gorm_themes.title) AGAINST (?) AS relevance",
+			query, query, themeTitleWeight, query).
+		Joins("LEFT JOIN gorm_themes ON gorm_themes.id = gorm_writings.theme_id").
+		Where("gorm_writings.user_id = ? AND gorm_writings.deleted_at IS NULL", userID).
+		Where("(MATCH(gorm_writings.content) AGAINST (?) OR MATCH(gorm_writings.feedback_text) AGAINST (?) OR MATCH(gorm_themes.title) AGAINST (?))",
+			query, query, query).
+		Order("relevance desc, gorm_writings.id desc").
+		Limit(limit).
+		Scan(&rows).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, models.APIError{Code: "DATABASE_ERROR", Message: "Failed to search writings"})
+		return
+	}
+
+	results := make([]models.SearchResult, len(rows))
+	for i, r := range rows {
+		results[i] = models.SearchResult{
+			WritingID:  int64(r.ID),
+			ThemeID:    int64(r.ThemeID),
+			ThemeTitle: r.ThemeTitle,
+			Status:     r.Status,
+			AiScore:    r.AIScore,
+			Relevance:  r.Relevance,
+			Matches:    []models.SearchMatch{},
+			CreatedAt:  r.CreatedAt,
+		}
+		fields := []struct{ name, text string }{{"themeTitle", r.ThemeTitle}, {"content", r.Content}}
+		if r.FeedbackText != nil {
+			fields = append(fields, struct{ name, text string }{"feedback", *r.FeedbackText})
+		}
+		for _, field := range fields {
+			snippet, ok := services.HighlightSnippet(field.text, query, snippetRadius)
+			if !ok {
+				continue
+			}
+			match := models.SearchMatch{Field: field.name, Snippet: snippet.Text, Highlights: []models.TextRange{}}
+			for _, h := range snippet.Highlights {
+				match.Highlights = append(match.Highlights, models.TextRange{Start: h.Start, End: h.End})
+			}
+			results[i].Matches = append(results[i].Matches, match)
+		}
+	}
+
+	ctx.JSON(http.StatusOK, results)
+}
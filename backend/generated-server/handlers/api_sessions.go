@@ -0,0 +1,153 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ch00z00/kotobalize/models"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// defaultSessionGraceMinutes is how long after the time limit a session can still be used
+// unless WRITING_SESSION_GRACE_MINUTES says otherwise.
+const defaultSessionGraceMinutes = 10
+
+// untimedSessionLifetime is how long a session on a theme without a time limit can be used.
+const untimedSessionLifetime = 24 * time.Hour
+
+var (
+	errSessionNotFound = errors.New("session not found")
+	errSessionUsed     = errors.New("session has already been used for a writing")
+	errSessionExpired  = errors.New("session has expired")
+)
+
+// StartWritingSession - Start a timed writing session on a theme
+//
+// The server records the start time; the writing created with the session is timed from it.
+func (c *Container) StartWritingSession(ctx *gin.Context) {
+	// Get themeId from path parameter
+	themeIDStr := ctx.Param("themeId")
+	themeID, err := strconv.ParseUint(themeIDStr, 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, models.APIError{Code: "INVALID_INPUT", Message: "Invalid theme ID format"})
+		return
+	}
+
+	// Get user ID from the context (set by the auth middleware)
+	userID, exists := ctx.Get("userId")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, models.APIError{Code: "UNAUTHORIZED", Message: "User ID not found in token"})
+		return
+	}
+
+	var gormTheme models.GormTheme
+	if err := c.DB.Where("id = ? AND (creator_id IS NULL OR creator_id = ?)", themeID, userID).First(&gormTheme).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			ctx.JSON(http.StatusNotFound, models.APIError{Code: "THEME_NOT_FOUND", Message: "Theme not found or you don't have permission to view it"})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, models.APIError{Code: "DATABASE_ERROR", Message: "Failed to fetch theme"})
+		return
+	}
+
+	now := time.Now()
+	session := models.GormWritingSession{
+		UserID:           userID.(uint),
+		ThemeID:          gormTheme.ID,
+		StartedAt:        now,
+		TimeLimitSeconds: gormTheme.TimeLimitInSeconds,
+		ExpiresAt:        now.Add(c.sessionLifetime(gormTheme.TimeLimitInSeconds)),
+	}
+	if err := c.DB.Create(&session).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, models.APIError{Code: "DATABASE_ERROR", Message: "Failed to start session"})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, mapGormWritingSessionToAPI(session))
+}
+
+// sessionLifetime is how long a session can be used: the time limit plus the grace period,
+// or untimedSessionLifetime for themes without a time limit.
+func (c *Container) sessionLifetime(timeLimitSeconds int) time.Duration {
+	if timeLimitSeconds <= 0 {
+		return untimedSessionLifetime
+	}
+	return time.Duration(timeLimitSeconds)*time.Second + c.SessionGrace
+}
+
+// usableSession loads a session of the user on the theme that can still be used for a new
+// writing. It fails with errSessionNotFound, errSessionUsed or errSessionExpired.
+func usableSession(tx *gorm.DB, sessionID uint, userID uint, themeID uint, now time.Time) (models.GormWritingSession, error) {
+	var session models.GormWritingSession
+	if err := tx.Where("id = ? AND user_id = ? AND theme_id = ?", sessionID, userID, themeID).First(&session).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return session, errSessionNotFound
+		}
+		return session, err
+	}
+	if session.WritingID != nil {
+		return session, errSessionUsed
+	}
+	if now.After(session.ExpiresAt) {
+		return session, errSessionExpired
+	}
+	return session, nil
+}
+
+// claimSession marks a session as used by a writing created in tx. Concurrent requests
+// with the same session race here: only one of them claims it, the others get errSessionUsed.
+func claimSession(tx *gorm.DB, session *models.GormWritingSession, writingID uint) error {
+	result := tx.Model(session).Where("writing_id IS NULL").Update("writing_id", writingID)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errSessionUsed
+	}
+	return nil
+}
+
+// timeSubmission sets the duration of a writing being submitted from its session's start
+// time, and marks it as overtime if it exceeds the session's time limit.
+func timeSubmission(gormWriting *models.GormWriting, session models.GormWritingSession, now time.Time) {
+	gormWriting.DurationSeconds = int(now.Sub(session.StartedAt) / time.Second)
+	gormWriting.Overtime = session.TimeLimitSeconds > 0 && gormWriting.DurationSeconds > session.TimeLimitSeconds
+}
+
+// timeSessionSubmission times a writing created in a session that is submitted at now.
+func (c *Container) timeSessionSubmission(gormWriting *models.GormWriting, now time.Time) error {
+	var session models.GormWritingSession
+	if err := c.DB.First(&session, *gormWriting.SessionID).Error; err != nil {
+		return err
+	}
+	timeSubmission(gormWriting, session, now)
+	return nil
+}
+
+// sessionErrorResponse maps an error from usableSession or claimSession to an HTTP status and API error.
+func sessionErrorResponse(err error) (int, models.APIError) {
+	switch {
+	case errors.Is(err, errSessionNotFound):
+		return http.StatusNotFound, models.APIError{Code: "SESSION_NOT_FOUND", Message: "Session not found for this theme"}
+	case errors.Is(err, errSessionUsed):
+		return http.StatusConflict, models.APIError{Code: "SESSION_USED", Message: "This session has already been used for a writing; start a new session"}
+	case errors.Is(err, errSessionExpired):
+		return http.StatusConflict, models.APIError{Code: "SESSION_EXPIRED", Message: "This session has expired; start a new session"}
+	}
+	return http.StatusInternalServerError, models.APIError{Code: "DATABASE_ERROR", Message: "Failed to create writing"}
+}
+
+// mapGormWritingSessionToAPI converts a GORM writing session model to an API writing session model.
+func mapGormWritingSessionToAPI(session models.GormWritingSession) models.WritingSession {
+	return models.WritingSession{
+		ID:               int64(session.ID),
+		ThemeID:          int64(session.ThemeID),
+		StartedAt:        session.StartedAt,
+		TimeLimitSeconds: session.TimeLimitSeconds,
+		ExpiresAt:        session.ExpiresAt,
+		WritingID:        session.WritingID,
+	}
+}
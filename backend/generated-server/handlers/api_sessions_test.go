@@ -0,0 +1,156 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/ch00z00/kotobalize/models"
+)
+
+func TestUsableSession(t *testing.T) {
+	c := newTestContainer(t)
+	alice := createTestUser(t, c.DB, "alice")
+	bob := createTestUser(t, c.DB, "bob")
+	theme := createTestTheme(t, c.DB, 600)
+	otherTheme := createTestTheme(t, c.DB, 600)
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+
+	newSession := func(expiresAt time.Time, writingID *uint) models.GormWritingSession {
+		session := models.GormWritingSession{UserID: alice.ID, ThemeID: theme.ID, StartedAt: expiresAt.Add(-20 * time.Minute), TimeLimitSeconds: 600, ExpiresAt: expiresAt, WritingID: writingID}
+		mustCreate(t, c.DB, &session)
+		return session
+	}
+	writing := createTestWriting(t, c.DB, alice.ID, theme.ID, "書いた")
+	oldWriting := createTestWriting(t, c.DB, alice.ID, theme.ID, "前に書いた")
+	fresh := newSession(now.Add(time.Minute), nil)
+	expiring := newSession(now, nil)
+	expired := newSession(now.Add(-time.Second), nil)
+	used := newSession(now.Add(time.Minute), &writing.ID)
+	usedAndExpired := newSession(now.Add(-time.Hour), &oldWriting.ID)
+
+	tests := []struct {
+		name      string
+		sessionID uint
+		userID    uint
+		themeID   uint
+		want      error
+	}{
+		{"fresh", fresh.ID, alice.ID, theme.ID, nil},
+		{"expires right now", expiring.ID, alice.ID, theme.ID, nil},
+		{"expired a second ago", expired.ID, alice.ID, theme.ID, errSessionExpired},
+		{"reused", used.ID, alice.ID, theme.ID, errSessionUsed},
+		{"reuse is reported before expiry", usedAndExpired.ID, alice.ID, theme.ID, errSessionUsed},
+		{"wrong theme", fresh.ID, alice.ID, otherTheme.ID, errSessionNotFound},
+		{"another user's session", fresh.ID, bob.ID, theme.ID, errSessionNotFound},
+		{"unknown session", fresh.ID + 100, alice.ID, theme.ID, errSessionNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			session, err := usableSession(c.DB, tt.sessionID, tt.userID, tt.themeID, now)
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("err = %v, want %v", err, tt.want)
+			}
+			if err == nil && session.ID != tt.sessionID {
+				t.Errorf("session = %d, want %d", session.ID, tt.sessionID)
+			}
+		})
+	}
+}
+
+func TestClaimSession(t *testing.T) {
+	c := newTestContainer(t)
+	user := createTestUser(t, c.DB, "alice")
+	theme := createTestTheme(t, c.DB, 600)
+	session := models.GormWritingSession{UserID: user.ID, ThemeID: theme.ID, StartedAt: time.Now(), ExpiresAt: time.Now().Add(time.Hour)}
+	mustCreate(t, c.DB, &session)
+	first := createTestWriting(t, c.DB, user.ID, theme.ID, "一つ目")
+	second := createTestWriting(t, c.DB, user.ID, theme.ID, "二つ目")
+
+	// Both requests loaded the session before either claimed it.
+	stale := session
+	if err := claimSession(c.DB, &session, first.ID); err != nil {
+		t.Fatalf("first claim: %v", err)
+	}
+	if err := claimSession(c.DB, &stale, second.ID); !errors.Is(err, errSessionUsed) {
+		t.Fatalf("second claim: err = %v, want %v", err, errSessionUsed)
+	}
+
+	var stored models.GormWritingSession
+	c.DB.First(&stored, session.ID)
+	if stored.WritingID == nil || *stored.WritingID != first.ID {
+		t.Errorf("writing_id = %v, want %d", stored.WritingID, first.ID)
+	}
+}
+
+func TestTimeSubmission(t *testing.T) {
+	started := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name         string
+		timeLimit    int
+		elapsed      time.Duration
+		wantDuration int
+		wantOvertime bool
+	}{
+		{"within the limit", 600, 5 * time.Minute, 300, false},
+		{"exactly at the limit", 600, 10 * time.Minute, 600, false},
+		{"partial seconds are dropped", 600, 10*time.Minute + 900*time.Millisecond, 600, false},
+		{"one second over", 600, 10*time.Minute + time.Second, 601, true},
+		{"no time limit", 0, 3 * time.Hour, 10800, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// A stale overtime flag must not survive a submission within the limit.
+			writing := models.GormWriting{DurationSeconds: 42, Overtime: true}
+			session := models.GormWritingSession{StartedAt: started, TimeLimitSeconds: tt.timeLimit}
+			timeSubmission(&writing, session, started.Add(tt.elapsed))
+			if writing.DurationSeconds != tt.wantDuration || writing.Overtime != tt.wantOvertime {
+				t.Errorf("duration, overtime = %d, %v, want %d, %v", writing.DurationSeconds, writing.Overtime, tt.wantDuration, tt.wantOvertime)
+			}
+		})
+	}
+}
+
+func TestCreateWritingWithReusedSession(t *testing.T) {
+	c := newTestContainer(t)
+	user := createTestUser(t, c.DB, "alice")
+	theme := createTestTheme(t, c.DB, 600)
+	session := models.GormWritingSession{UserID: user.ID, ThemeID: theme.ID, StartedAt: time.Now().Add(-time.Minute), TimeLimitSeconds: 600, ExpiresAt: time.Now().Add(time.Hour)}
+	mustCreate(t, c.DB, &session)
+
+	body := map[string]any{"themeId": theme.ID, "content": "ACIDは…", "durationSeconds": 5, "sessionId": session.ID}
+	created := decodeResponse[models.Writing](t, serve(t, c.CreateWriting, http.MethodPost, "/writings", "/writings", user.ID, body), http.StatusCreated)
+	// The duration sent by the client is ignored.
+	if created.DurationSeconds < 60 || created.Overtime {
+		t.Errorf("duration, overtime = %d, %v, want about 60, false", created.DurationSeconds, created.Overtime)
+	}
+
+	expectError(t, serve(t, c.CreateWriting, http.MethodPost, "/writings", "/writings", user.ID, body), http.StatusConflict, "SESSION_USED")
+}
+
+func TestUpdateSubmittedSessionWritingKeepsDuration(t *testing.T) {
+	c := newTestContainer(t)
+	user := createTestUser(t, c.DB, "alice")
+	theme := createTestTheme(t, c.DB, 600)
+	weekAgo := time.Now().Add(-7 * 24 * time.Hour)
+	session := models.GormWritingSession{UserID: user.ID, ThemeID: theme.ID, StartedAt: weekAgo, TimeLimitSeconds: 600, ExpiresAt: weekAgo.Add(20 * time.Minute)}
+	mustCreate(t, c.DB, &session)
+	writing := models.GormWriting{UserID: user.ID, ThemeID: theme.ID, Content: "ACIDはトランザクションの性質", SessionID: &session.ID, DurationSeconds: 300, Status: models.WritingStatusSubmitted}
+	mustCreate(t, c.DB, &writing)
+
+	body := map[string]any{"content": "ACIDはトランザクションの性質です", "durationSeconds": 10}
+	updated := decodeResponse[models.Writing](t, serve(t, c.UpdateWriting, http.MethodPut, "/writings/:writingId", writingPath(writing.ID, ""), user.ID, body), http.StatusOK)
+	if updated.DurationSeconds != 300 || updated.Overtime {
+		t.Errorf("duration, overtime = %d, %v, want 300, false", updated.DurationSeconds, updated.Overtime)
+	}
+	if updated.EditedAfterSubmitAt == nil {
+		t.Error("editedAfterSubmitAt is not set")
+	}
+
+	var stored models.GormWriting
+	c.DB.First(&stored, writing.ID)
+	if stored.DurationSeconds != 300 || stored.Overtime || stored.EditedAfterSubmitAt == nil {
+		t.Errorf("stored duration, overtime, editedAfterSubmitAt = %d, %v, %v, want 300, false, set", stored.DurationSeconds, stored.Overtime, stored.EditedAfterSubmitAt)
+	}
+}
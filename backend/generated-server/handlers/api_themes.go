@@ -4,9 +4,11 @@ import (
 	"errors"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/ch00z00/kotobalize/models"
 	"github.com/gin-gonic/gin"
+	"gorm.io/datatypes"
 	"gorm.io/gorm"
 )
 
@@ -17,6 +19,9 @@ type ThemeWithFavorite struct {
 }
 
 // ListThemes - Get a list of all available themes
+//
+// Official themes come first. With limit, the list is paginated: the X-Next-Cursor
+// header carries the cursor of the next page.
 func (c *Container) ListThemes(ctx *gin.Context) {
 	// Get user ID from the context (set by the auth middleware)
 	userIDVal, exists := ctx.Get("userId")
@@ -29,13 +34,26 @@ func (c *Container) ListThemes(ctx *gin.Context) {
 	userID := userIDVal.(uint)
 
 	sortOrder := ctx.DefaultQuery("sort", "newest")
-	orderClause := "gorm_themes.creator_id asc, "
+	columns := []sortColumn{{expr: "COALESCE(gorm_themes.creator_id, 0)"}}
 
 	switch sortOrder {
 	case "popular":
-		orderClause += "gorm_themes.favorites_count desc, gorm_themes.created_at desc"
+		columns = append(columns, sortColumn{expr: "gorm_themes.favorites_count", desc: true})
 	default: // "newest"
-		orderClause += "gorm_themes.created_at desc"
+		sortOrder = "newest"
+	}
+	columns = append(columns,
+		sortColumn{expr: "gorm_themes.created_at", desc: true, time: true},
+		sortColumn{expr: "gorm_themes.id", desc: true})
+
+	page, ok := parsePageQuery(ctx)
+	if !ok {
+		return
+	}
+	paginate, err := page.scope(columns)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, models.APIError{Code: "INVALID_INPUT", Message: err.Error()})
+		return
 	}
 
 	var results []ThemeWithFavorite
@@ -44,11 +62,21 @@ func (c *Container) ListThemes(ctx *gin.Context) {
 		Select("gorm_themes.*, user_favorite_themes.user_id IS NOT NULL as is_favorited").
 		Joins("LEFT JOIN user_favorite_themes ON gorm_themes.id = user_favorite_themes.theme_id AND user_favorite_themes.user_id = ?", userID).
 		Where("gorm_themes.deleted_at IS NULL AND (gorm_themes.creator_id IS NULL OR gorm_themes.creator_id = ?)", userID).
-		Order(orderClause).
+		Scopes(paginate).
 		Find(&results).Error; err != nil {
 		ctx.JSON(http.StatusInternalServerError, models.APIError{Code: "DATABASE_ERROR", Message: "Failed to fetch themes"})
 		return
 	}
+	results = setNextCursor(ctx, page, results, func(r ThemeWithFavorite) []string {
+		values := []string{"0"}
+		if r.CreatorID != nil {
+			values[0] = cursorInt(*r.CreatorID)
+		}
+		if sortOrder == "popular" {
+			values = append(values, cursorInt(r.FavoritesCount))
+		}
+		return append(values, cursorTime(r.CreatedAt), cursorInt(r.ID))
+	})
 
 	// Map GORM themes to API themes
 	apiThemes := make([]models.Theme, len(results))
@@ -118,6 +146,14 @@ func (c *Container) CreateTheme(ctx *gin.Context) {
 		return
 	}
 
+	// The referenced rubric must be official or owned by the user.
+	if req.RubricID != nil {
+		if err := c.checkRubricAccess(*req.RubricID, userID); err != nil {
+			ctx.JSON(http.StatusBadRequest, models.APIError{Code: "RUBRIC_NOT_FOUND", Message: "Rubric not found or you don't have permission to use it"})
+			return
+		}
+	}
+
 	// Create a new GORM theme record (DB Model).
 	gormTheme := models.GormTheme{
 		Title:              req.Title,
@@ -125,6 +161,9 @@ func (c *Container) CreateTheme(ctx *gin.Context) {
 		Category:           req.Category,
 		TimeLimitInSeconds: req.TimeLimitInSeconds,
 		CreatorID:          &userID,
+		RubricID:           req.RubricID,
+		KeyPoints:          cleanThemeList(req.KeyPoints),
+		Misconceptions:     cleanThemeList(req.Misconceptions),
 	}
 
 	// Save the new theme to the database.
@@ -190,12 +229,37 @@ func (c *Container) UpdateTheme(ctx *gin.Context) {
 		return
 	}
 
+	// The referenced rubric must be official or owned by the user.
+	if req.RubricID != nil {
+		if err := c.checkRubricAccess(*req.RubricID, userID); err != nil {
+			ctx.JSON(http.StatusBadRequest, models.APIError{Code: "RUBRIC_NOT_FOUND", Message: "Rubric not found or you don't have permission to use it"})
+			return
+		}
+	}
+
 	// Use GORM's Updates to perform a partial update (only non-zero fields from req are updated)
 	if err := c.DB.Model(&gormTheme).Updates(req).Error; err != nil {
 		ctx.JSON(http.StatusInternalServerError, models.APIError{Code: "DATABASE_ERROR", Message: "Failed to update theme"})
 		return
 	}
 
+	// The lists are replaced as a whole, so an empty list can clear them.
+	listUpdates := map[string]interface{}{}
+	if req.KeyPoints != nil {
+		gormTheme.KeyPoints = cleanThemeList(*req.KeyPoints)
+		listUpdates["key_points"] = gormTheme.KeyPoints
+	}
+	if req.Misconceptions != nil {
+		gormTheme.Misconceptions = cleanThemeList(*req.Misconceptions)
+		listUpdates["misconceptions"] = gormTheme.Misconceptions
+	}
+	if len(listUpdates) > 0 {
+		if err := c.DB.Model(&gormTheme).Updates(listUpdates).Error; err != nil {
+			ctx.JSON(http.StatusInternalServerError, models.APIError{Code: "DATABASE_ERROR", Message: "Failed to update theme"})
+			return
+		}
+	}
+
 	// 更新後のテーマのお気に入り状態を確認します
 	var favorite models.UserFavoriteTheme
 	isFavorited := c.DB.Where("user_id = ? AND theme_id = ?", userID, themeID).First(&favorite).Error == nil
@@ -363,5 +427,27 @@ func mapGormThemeToAPI(gormTheme models.GormTheme, isFavorited bool) models.Them
 		CreatedAt:          gormTheme.CreatedAt,
 		UpdatedAt:          gormTheme.UpdatedAt,
 		CreatorID:          gormTheme.CreatorID,
+		RubricID:           gormTheme.RubricID,
+		KeyPoints:          nonNilStrings(gormTheme.KeyPoints),
+		Misconceptions:     nonNilStrings(gormTheme.Misconceptions),
+	}
+}
+
+// cleanThemeList trims the entries of a key point or misconception list and drops empty ones.
+func cleanThemeList(items []string) datatypes.JSONSlice[string] {
+	cleaned := datatypes.JSONSlice[string]{}
+	for _, item := range items {
+		if item = strings.TrimSpace(item); item != "" {
+			cleaned = append(cleaned, item)
+		}
+	}
+	return cleaned
+}
+
+// nonNilStrings returns items, or an empty slice if it is nil, so that JSON shows [] instead of null.
+func nonNilStrings(items []string) []string {
+	if items == nil {
+		return []string{}
 	}
+	return items
 }
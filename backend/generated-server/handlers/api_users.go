@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
@@ -15,6 +16,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
 )
 
 // GetAvatarUploadURL generates a presigned URL for uploading a file to S3.
@@ -177,7 +179,7 @@ func (c *Container) UpdateUserProfile(ctx *gin.Context) {
 	user.Name = &req.Name
 	if err := c.DB.Save(&user).Error; err != nil {
 		// Handle potential unique constraint violation for the name
-		if strings.Contains(err.Error(), "Duplicate entry") {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
 			ctx.JSON(http.StatusConflict, models.APIError{Code: "NAME_TAKEN", Message: "このユーザー名は既に使用されています。"})
 			return
 		}
@@ -207,17 +209,22 @@ type dailyActivity struct {
 }
 
 // GetUserActivity retrieves the user's writing activity for the contribution graph.
+// A mentor can pass the userId of a mentee to see the mentee's activity instead.
 func (c *Container) GetUserActivity(ctx *gin.Context) {
 	userID, exists := ctx.Get("userId")
 	if !exists {
 		ctx.JSON(http.StatusUnauthorized, models.APIError{Code: "UNAUTHORIZED", Message: "User not authenticated"})
 		return
 	}
+	ownerID, ok := c.viewedUserID(ctx, userID.(uint))
+	if !ok {
+		return
+	}
 
 	var activities []dailyActivity
 	if err := c.DB.Model(&models.GormWriting{}).
 		Select("DATE(created_at) as date, COUNT(*) as count").
-		Where("user_id = ? AND deleted_at IS NULL", userID).
+		Where("user_id = ? AND status = ? AND deleted_at IS NULL", ownerID, models.WritingStatusSubmitted).
 		Group("DATE(created_at)").
 		Find(&activities).Error; err != nil {
 		ctx.JSON(http.StatusInternalServerError, models.APIError{Code: "DATABASE_ERROR", Message: "Failed to fetch activity data"})
@@ -303,3 +310,51 @@ func (c *Container) DeleteUserAvatar(ctx *gin.Context) {
 	}
 	ctx.JSON(http.StatusOK, apiUser)
 }
+
+// GetUserUsage returns the authenticated user's AI usage and remaining quota.
+func (c *Container) GetUserUsage(ctx *gin.Context) {
+	userIDVal, exists := ctx.Get("userId")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, models.APIError{Code: "UNAUTHORIZED", Message: "User not authenticated"})
+		return
+	}
+	userID := userIDVal.(uint)
+
+	dayStart, dayEnd, monthStart, monthEnd := quotaPeriods(time.Now())
+
+	daily, err := c.usageSince(userID, dayStart)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, models.APIError{Code: "DATABASE_ERROR", Message: "Failed to fetch usage data"})
+		return
+	}
+	monthly, err := c.usageSince(userID, monthStart)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, models.APIError{Code: "DATABASE_ERROR", Message: "Failed to fetch usage data"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, models.AIUsage{
+		Daily:   newAIUsagePeriod(daily, c.Quota.DailyRequests, c.Quota.DailyTokens, dayEnd),
+		Monthly: newAIUsagePeriod(monthly, c.Quota.MonthlyRequests, c.Quota.MonthlyTokens, monthEnd),
+	})
+}
+
+// newAIUsagePeriod builds the API usage for one period. Remaining values are only set for finite limits.
+func newAIUsagePeriod(totals usageTotals, requestLimit, tokenLimit int64, resetsAt time.Time) models.AIUsagePeriod {
+	period := models.AIUsagePeriod{
+		RequestsUsed: totals.Requests,
+		RequestLimit: requestLimit,
+		TokensUsed:   totals.Tokens,
+		TokenLimit:   tokenLimit,
+		ResetsAt:     resetsAt,
+	}
+	if requestLimit > 0 {
+		remaining := max(requestLimit-totals.Requests, 0)
+		period.RequestsRemaining = &remaining
+	}
+	if tokenLimit > 0 {
+		remaining := max(tokenLimit-totals.Tokens, 0)
+		period.TokensRemaining = &remaining
+	}
+	return period
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/ch00z00/kotobalize/models"
 	"github.com/ch00z00/kotobalize/services"
@@ -13,6 +14,11 @@ import (
 )
 
 // CreateWriting - Create a new writing record and trigger AI review
+//
+// The writing must be created within a session started by StartWritingSession: its
+// duration is measured by the server from the session start, and a writing submitted
+// after the theme's time limit is marked as overtime. Each session can be used once.
+// With status "draft", the writing is saved as a draft to be autosaved by AutosaveWriting.
 func (c *Container) CreateWriting(ctx *gin.Context) {
 	// Get user ID from the context (set by the auth middleware)
 	userID, exists := ctx.Get("userId")
@@ -35,16 +41,42 @@ func (c *Container) CreateWriting(ctx *gin.Context) {
 		return
 	}
 
-	// Create the new writing record
+	// Create the new writing record. The duration sent by the client is ignored.
+	now := time.Now()
 	newWriting := models.GormWriting{
-		UserID:          userID.(uint),
-		ThemeID:         uint(req.ThemeID),
-		Content:         req.Content,
-		DurationSeconds: int(req.DurationSeconds),
+		UserID:    userID.(uint),
+		ThemeID:   uint(req.ThemeID),
+		Content:   req.Content,
+		SessionID: req.SessionID,
+		Status:    models.WritingStatusSubmitted,
 	}
+	if req.Status == models.WritingStatusDraft {
+		newWriting.Status = models.WritingStatusDraft
+		newWriting.LastSavedAt = &now
+	}
+	flagInjection(&newWriting)
 
-	if err := c.DB.Create(&newWriting).Error; err != nil {
-		ctx.JSON(http.StatusInternalServerError, models.APIError{Code: "DATABASE_ERROR", Message: "Failed to create writing"})
+	// The first revision keeps the content as created, before any edit. For a draft, it
+	// follows the autosaves until the draft is submitted.
+	err := c.DB.Transaction(func(tx *gorm.DB) error {
+		session, err := usableSession(tx, *req.SessionID, newWriting.UserID, newWriting.ThemeID, now)
+		if err != nil {
+			return err
+		}
+		// Drafts are timed when they are submitted.
+		if newWriting.Status == models.WritingStatusSubmitted {
+			timeSubmission(&newWriting, session, now)
+		}
+		if err := tx.Create(&newWriting).Error; err != nil {
+			return err
+		}
+		if err := claimSession(tx, &session, newWriting.ID); err != nil {
+			return err
+		}
+		return saveRevision(tx, &newWriting)
+	})
+	if err != nil {
+		ctx.JSON(sessionErrorResponse(err))
 		return
 	}
 
@@ -54,7 +86,290 @@ func (c *Container) CreateWriting(ctx *gin.Context) {
 	ctx.JSON(http.StatusCreated, apiWriting)
 }
 
-// ReviewWriting - Trigger AI review for a writing
+// AutosaveWriting - Save the latest content of a draft, optionally submitting it
+//
+// Only the fields present in the request are changed. Submitted writings are final
+// and cannot be autosaved. For writings created in a session, the duration sent by the
+// client is ignored and measured by the server on submission instead. Autosaves update a
+// single revision of the draft rather than adding one each, until the draft is submitted.
+func (c *Container) AutosaveWriting(ctx *gin.Context) {
+	// Get writingId from path parameter
+	writingIDStr := ctx.Param("writingId")
+	writingID, err := strconv.ParseUint(writingIDStr, 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, models.APIError{Code: "INVALID_INPUT", Message: "Invalid writing ID format"})
+		return
+	}
+
+	var req models.AutosaveWritingRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, models.APIError{Code: "INVALID_INPUT", Message: err.Error()})
+		return
+	}
+
+	// Get user ID from the context (set by the auth middleware)
+	userID, exists := ctx.Get("userId")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, models.APIError{Code: "UNAUTHORIZED", Message: "User ID not found in token"})
+		return
+	}
+
+	// Find the writing, ensuring it belongs to the authenticated user.
+	var gormWriting models.GormWriting
+	if err := c.DB.Where("id = ? AND user_id = ?", writingID, userID).First(&gormWriting).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			ctx.JSON(http.StatusNotFound, models.APIError{Code: "WRITING_NOT_FOUND", Message: "Writing not found or you don't have permission to view it"})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, models.APIError{Code: "DATABASE_ERROR", Message: "Failed to fetch writing"})
+		return
+	}
+	if gormWriting.Status != models.WritingStatusDraft {
+		ctx.JSON(http.StatusConflict, models.APIError{Code: "WRITING_SUBMITTED", Message: "Only drafts can be autosaved"})
+		return
+	}
+
+	changed := false
+	if req.Content != nil && *req.Content != gormWriting.Content {
+		gormWriting.Content = *req.Content
+		changed = true
+	}
+	if gormWriting.SessionID == nil && req.DurationSeconds != nil && int(*req.DurationSeconds) != gormWriting.DurationSeconds {
+		gormWriting.DurationSeconds = int(*req.DurationSeconds)
+		changed = true
+	}
+	now := time.Now()
+	if req.Status == models.WritingStatusSubmitted {
+		gormWriting.Status = models.WritingStatusSubmitted
+		// Saving the submitted content closes the draft's revision.
+		changed = true
+		// The session was claimed when the draft was created, so a late submission is
+		// marked as overtime rather than rejected.
+		if gormWriting.SessionID != nil {
+			if err := c.timeSessionSubmission(&gormWriting, now); err != nil {
+				ctx.JSON(http.StatusInternalServerError, models.APIError{Code: "DATABASE_ERROR", Message: "Failed to fetch session"})
+				return
+			}
+		}
+	}
+	gormWriting.LastSavedAt = &now
+	flagInjection(&gormWriting)
+
+	// Saves without changes only touch lastSavedAt.
+	if err := c.saveWritingChanges(&gormWriting, changed); err != nil {
+		ctx.JSON(http.StatusInternalServerError, models.APIError{Code: "DATABASE_ERROR", Message: "Failed to save writing"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, mapGormWritingToAPI(gormWriting))
+}
+
+// UpdateWriting - Edit the content of a writing, such as to fix a typo
+//
+// Drafts and submitted writings can both be edited; the previous content is kept as a
+// revision. Existing reviews stay attached to the revision they scored. The duration of
+// a writing created in a session is measured by the server and cannot be edited; editing
+// a submitted writing keeps the duration and overtime measured at submission and records
+// when it was edited.
+func (c *Container) UpdateWriting(ctx *gin.Context) {
+	// Get writingId from path parameter
+	writingIDStr := ctx.Param("writingId")
+	writingID, err := strconv.ParseUint(writingIDStr, 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, models.APIError{Code: "INVALID_INPUT", Message: "Invalid writing ID format"})
+		return
+	}
+
+	var req models.UpdateWritingRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, models.APIError{Code: "INVALID_INPUT", Message: err.Error()})
+		return
+	}
+
+	// Get user ID from the context (set by the auth middleware)
+	userID, exists := ctx.Get("userId")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, models.APIError{Code: "UNAUTHORIZED", Message: "User ID not found in token"})
+		return
+	}
+
+	// Find the writing, ensuring it belongs to the authenticated user.
+	var gormWriting models.GormWriting
+	if err := c.DB.Where("id = ? AND user_id = ?", writingID, userID).First(&gormWriting).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			ctx.JSON(http.StatusNotFound, models.APIError{Code: "WRITING_NOT_FOUND", Message: "Writing not found or you don't have permission to view it"})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, models.APIError{Code: "DATABASE_ERROR", Message: "Failed to fetch writing"})
+		return
+	}
+
+	changed := *req.Content != gormWriting.Content
+	gormWriting.Content = *req.Content
+	if gormWriting.SessionID == nil && req.DurationSeconds != nil && int(*req.DurationSeconds) != gormWriting.DurationSeconds {
+		gormWriting.DurationSeconds = int(*req.DurationSeconds)
+		changed = true
+	}
+	if !changed {
+		ctx.JSON(http.StatusOK, mapGormWritingToAPI(gormWriting))
+		return
+	}
+	now := time.Now()
+	if gormWriting.Status == models.WritingStatusDraft {
+		gormWriting.LastSavedAt = &now
+	} else {
+		gormWriting.EditedAfterSubmitAt = &now
+	}
+	flagInjection(&gormWriting)
+
+	if err := c.saveWritingChanges(&gormWriting, true); err != nil {
+		ctx.JSON(http.StatusInternalServerError, models.APIError{Code: "DATABASE_ERROR", Message: "Failed to save writing"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, mapGormWritingToAPI(gormWriting))
+}
+
+// saveWritingChanges stores the editable fields of a writing. When the content or the
+// duration changed, the new values are also kept as a revision.
+func (c *Container) saveWritingChanges(gormWriting *models.GormWriting, changed bool) error {
+	return c.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(gormWriting).
+			Select("content", "duration_seconds", "overtime", "status", "last_saved_at", "edited_after_submit_at", "injection_flagged", "injection_signals").
+			Updates(gormWriting).Error; err != nil {
+			return err
+		}
+		if !changed {
+			return nil
+		}
+		return saveRevision(tx, gormWriting)
+	})
+}
+
+// DeleteWriting - Move a writing to the trash
+//
+// The writing is soft-deleted, so it disappears from lists and activity but can be
+// restored. Deleting also closes the writing for peer review.
+func (c *Container) DeleteWriting(ctx *gin.Context) {
+	// Get writingId from path parameter
+	writingIDStr := ctx.Param("writingId")
+	writingID, err := strconv.ParseUint(writingIDStr, 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, models.APIError{Code: "INVALID_INPUT", Message: "Invalid writing ID format"})
+		return
+	}
+
+	// Get user ID from the context (set by the auth middleware)
+	userID, exists := ctx.Get("userId")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, models.APIError{Code: "UNAUTHORIZED", Message: "User ID not found in token"})
+		return
+	}
+
+	// Find the writing, ensuring it belongs to the authenticated user.
+	var gormWriting models.GormWriting
+	if err := c.DB.Where("id = ? AND user_id = ?", writingID, userID).First(&gormWriting).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			ctx.JSON(http.StatusNotFound, models.APIError{Code: "WRITING_NOT_FOUND", Message: "Writing not found or you don't have permission to view it"})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, models.APIError{Code: "DATABASE_ERROR", Message: "Failed to fetch writing"})
+		return
+	}
+
+	err = c.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&gormWriting).Update("open_for_peer_review", false).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&gormWriting).Error
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, models.APIError{Code: "DATABASE_ERROR", Message: "Failed to delete writing"})
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+// ListDeletedWritings - List the authenticated user's writings in the trash, most recently deleted first
+//
+// With limit, the list is paginated: the X-Next-Cursor header carries the cursor of the next page.
+func (c *Container) ListDeletedWritings(ctx *gin.Context) {
+	// Get user ID from the context (set by the auth middleware)
+	userID, exists := ctx.Get("userId")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, models.APIError{Code: "UNAUTHORIZED", Message: "User ID not found in token"})
+		return
+	}
+
+	page, ok := parsePageQuery(ctx)
+	if !ok {
+		return
+	}
+	paginate, err := page.scope([]sortColumn{
+		{expr: "deleted_at", desc: true, time: true},
+		{expr: "id", desc: true},
+	})
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, models.APIError{Code: "INVALID_INPUT", Message: err.Error()})
+		return
+	}
+
+	var gormWritings []models.GormWriting
+	if err := c.DB.Unscoped().Where("user_id = ? AND deleted_at IS NOT NULL", userID).Scopes(paginate).Find(&gormWritings).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, models.APIError{Code: "DATABASE_ERROR", Message: "Failed to fetch writings"})
+		return
+	}
+	gormWritings = setNextCursor(ctx, page, gormWritings, func(w models.GormWriting) []string {
+		return []string{cursorTime(w.DeletedAt.Time), cursorInt(w.ID)}
+	})
+
+	apiWritings := make([]models.Writing, len(gormWritings))
+	for i, w := range gormWritings {
+		apiWritings[i] = mapGormWritingToAPI(w)
+	}
+
+	ctx.JSON(http.StatusOK, apiWritings)
+}
+
+// RestoreWriting - Restore a writing from the trash
+func (c *Container) RestoreWriting(ctx *gin.Context) {
+	// Get writingId from path parameter
+	writingIDStr := ctx.Param("writingId")
+	writingID, err := strconv.ParseUint(writingIDStr, 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, models.APIError{Code: "INVALID_INPUT", Message: "Invalid writing ID format"})
+		return
+	}
+
+	// Get user ID from the context (set by the auth middleware)
+	userID, exists := ctx.Get("userId")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, models.APIError{Code: "UNAUTHORIZED", Message: "User ID not found in token"})
+		return
+	}
+
+	// Only writings in the trash can be restored.
+	var gormWriting models.GormWriting
+	if err := c.DB.Unscoped().Where("id = ? AND user_id = ? AND deleted_at IS NOT NULL", writingID, userID).First(&gormWriting).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			ctx.JSON(http.StatusNotFound, models.APIError{Code: "WRITING_NOT_FOUND", Message: "Writing not found in the trash"})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, models.APIError{Code: "DATABASE_ERROR", Message: "Failed to fetch writing"})
+		return
+	}
+
+	if err := c.DB.Unscoped().Model(&gormWriting).Update("deleted_at", nil).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, models.APIError{Code: "DATABASE_ERROR", Message: "Failed to restore writing"})
+		return
+	}
+	gormWriting.DeletedAt = gorm.DeletedAt{}
+
+	ctx.JSON(http.StatusOK, mapGormWritingToAPI(gormWriting))
+}
+
+// ReviewWriting - Enqueue an AI review job for a writing, or answer from the review cache
 func (c *Container) ReviewWriting(ctx *gin.Context) {
 	// Get user ID from the context (set by the auth middleware)
 	userID, exists := ctx.Get("userId")
@@ -86,34 +401,202 @@ func (c *Container) ReviewWriting(ctx *gin.Context) {
 		ctx.JSON(http.StatusForbidden, models.APIError{Code: "FORBIDDEN", Message: "You do not have permission to review this writing"})
 		return
 	}
+	if !requireSubmitted(ctx, gormWriting) {
+		return
+	}
 
-	// --- Call OpenAI API for review ---
-	openAIService := services.OpenAIService{Client: c.OpenAIClient}
-	aiResponse, err := openAIService.GetAIReview(ctx.Request.Context(), gormWriting.Theme.Title, gormWriting.Content)
+	// Answer from the cache without queueing a job when the same input was reviewed recently.
+	// Cache hits do not use any AI quota.
+	input, err := c.reviewInput(&gormWriting)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, models.APIError{Code: "AI_SERVICE_ERROR", Message: "Failed to get AI review: " + err.Error()})
+		ctx.JSON(http.StatusInternalServerError, models.APIError{Code: "DATABASE_ERROR", Message: err.Error()})
 		return
 	}
-
-	// Update the writing record with the AI's feedback
-	feedbackJSON, err := json.Marshal(aiResponse)
+	cached, err := c.reuseCachedReview(&gormWriting, services.ReviewCacheKey(input), input.Rubric)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, models.APIError{Code: "INTERNAL_ERROR", Message: "Failed to serialize AI response"})
+		ctx.JSON(http.StatusInternalServerError, models.APIError{Code: "DATABASE_ERROR", Message: err.Error()})
+		return
+	}
+	if cached != nil {
+		// Record a finished job so that clients polling /review-jobs see the same result.
+		now := time.Now()
+		job := models.GormReviewJob{
+			WritingID:  gormWriting.ID,
+			UserID:     gormWriting.UserID,
+			Status:     models.ReviewJobStatusSucceeded,
+			Cached:     true,
+			StartedAt:  &now,
+			FinishedAt: &now,
+		}
+		if err := c.DB.Create(&job).Error; err != nil {
+			ctx.JSON(http.StatusInternalServerError, models.APIError{Code: "DATABASE_ERROR", Message: "Failed to create review job"})
+			return
+		}
+		apiWriting := mapGormWritingToAPI(gormWriting)
+		ctx.JSON(http.StatusOK, mapGormReviewJobToAPI(job, &apiWriting))
 		return
 	}
 
-	// Update the GORM model with the new score and the full JSON feedback
-	gormWriting.AIScore = &aiResponse.TotalScore
-	gormWriting.AIFeedback = feedbackJSON
+	// Reject the request early if the user has no AI quota left.
+	// With the heuristic fallback, the request instead ends as a failed job with a provisional review.
+	if err := c.checkQuota(gormWriting.UserID); err != nil {
+		status, apiErr := aiErrorResponse(err)
+		if !c.HeuristicFallback {
+			ctx.JSON(status, apiErr)
+			return
+		}
+		fallback, ferr := c.saveFallbackReview(&gormWriting)
+		if ferr != nil {
+			ctx.JSON(status, apiErr)
+			return
+		}
+		now := time.Now()
+		job := models.GormReviewJob{
+			WritingID:        gormWriting.ID,
+			UserID:           gormWriting.UserID,
+			Status:           models.ReviewJobStatusFailed,
+			LastError:        &apiErr.Message,
+			LastErrorCode:    &apiErr.Code,
+			FallbackReviewID: &fallback.ID,
+			StartedAt:        &now,
+			FinishedAt:       &now,
+		}
+		if err := c.DB.Create(&job).Error; err != nil {
+			ctx.JSON(http.StatusInternalServerError, models.APIError{Code: "DATABASE_ERROR", Message: "Failed to create review job"})
+			return
+		}
+		apiJob := mapGormReviewJobToAPI(job, nil)
+		apiFallback := mapGormReviewToAPI(*fallback)
+		apiJob.FallbackReview = &apiFallback
+		ctx.JSON(http.StatusOK, apiJob)
+		return
+	}
 
-	// Save the updated writing record to the database
-	if err := c.DB.Save(&gormWriting).Error; err != nil {
-		ctx.JSON(http.StatusInternalServerError, models.APIError{Code: "DATABASE_ERROR", Message: "Failed to save AI review"})
+	// If a review of this writing is already pending, return that job instead of creating another one.
+	var pendingJob models.GormReviewJob
+	err = c.DB.Where("writing_id = ? AND status IN ?", gormWriting.ID,
+		[]string{models.ReviewJobStatusQueued, models.ReviewJobStatusRunning}).
+		Order("id desc").First(&pendingJob).Error
+	if err == nil {
+		ctx.JSON(http.StatusAccepted, mapGormReviewJobToAPI(pendingJob, nil))
+		return
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		ctx.JSON(http.StatusInternalServerError, models.APIError{Code: "DATABASE_ERROR", Message: "Failed to check pending reviews"})
 		return
 	}
 
-	// Return the updated writing record
-	ctx.JSON(http.StatusOK, mapGormWritingToAPI(gormWriting))
+	// Persist the job first so that it survives a restart, then hand it to the workers.
+	job := models.GormReviewJob{
+		WritingID: gormWriting.ID,
+		UserID:    gormWriting.UserID,
+		Status:    models.ReviewJobStatusQueued,
+	}
+	if err := c.DB.Create(&job).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, models.APIError{Code: "DATABASE_ERROR", Message: "Failed to create review job"})
+		return
+	}
+	c.ReviewWorkers.Enqueue(job.ID)
+
+	ctx.JSON(http.StatusAccepted, mapGormReviewJobToAPI(job, nil))
+}
+
+// StreamReviewWriting - Run an AI review and stream the feedback as Server-Sent Events
+//
+// A "feedback" event is sent for each viewpoint as soon as it is available,
+// followed by a "review" event with the saved review run and a "writing" event
+// with the saved writing, or an "error" event. With the heuristic fallback enabled,
+// an "error" event is followed by a "fallback" event with a provisional review.
+func (c *Container) StreamReviewWriting(ctx *gin.Context) {
+	// Get user ID from the context (set by the auth middleware)
+	userID, exists := ctx.Get("userId")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, models.APIError{Code: "UNAUTHORIZED", Message: "User ID not found in token"})
+		return
+	}
+
+	// Bind the incoming JSON to the NewReviewRequest struct
+	var req models.NewReviewRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, models.APIError{Code: "INVALID_INPUT", Message: err.Error()})
+		return
+	}
+
+	// Find the writing record in the database
+	var gormWriting models.GormWriting
+	if err := c.DB.Preload("Theme").First(&gormWriting, req.WritingID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			ctx.JSON(http.StatusNotFound, models.APIError{Code: "WRITING_NOT_FOUND", Message: "Writing not found"})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, models.APIError{Code: "DATABASE_ERROR", Message: "Failed to fetch writing"})
+		return
+	}
+
+	// Authorization check: Ensure the writing belongs to the authenticated user
+	if gormWriting.UserID != userID.(uint) {
+		ctx.JSON(http.StatusForbidden, models.APIError{Code: "FORBIDDEN", Message: "You do not have permission to review this writing"})
+		return
+	}
+	if !requireSubmitted(ctx, gormWriting) {
+		return
+	}
+
+	// Reject the request early if the user has no AI quota left.
+	if err := c.checkQuota(gormWriting.UserID); err != nil {
+		status, apiErr := aiErrorResponse(err)
+		ctx.JSON(status, apiErr)
+		return
+	}
+
+	// Errors from here on are reported as SSE events because the response has started.
+	ctx.Header("Cache-Control", "no-cache")
+	ctx.Header("Connection", "keep-alive")
+	ctx.Header("X-Accel-Buffering", "no") // Disable proxy buffering (e.g. nginx)
+
+	review, err := c.performReview(ctx.Request.Context(), &gormWriting, func(feedback services.FeedbackDetail) {
+		ctx.SSEvent("feedback", feedback)
+		ctx.Writer.Flush()
+	})
+	if err != nil {
+		_, apiErr := aiErrorResponse(err)
+		ctx.SSEvent("error", apiErr)
+		if c.HeuristicFallback && ctx.Request.Context().Err() == nil {
+			if fallback, err := c.saveFallbackReview(&gormWriting); err == nil {
+				ctx.SSEvent("fallback", mapGormReviewToAPI(*fallback))
+			}
+		}
+		ctx.Writer.Flush()
+		return
+	}
+
+	ctx.SSEvent("review", mapGormReviewToAPI(*review))
+	ctx.SSEvent("writing", mapGormWritingToAPI(gormWriting))
+	ctx.Writer.Flush()
+}
+
+// requireSubmitted writes a 409 response and returns false if the writing is still a draft.
+// Drafts change while the user writes, so they are not sent to the AI.
+func requireSubmitted(ctx *gin.Context, gormWriting models.GormWriting) bool {
+	if gormWriting.Status == models.WritingStatusDraft {
+		ctx.JSON(http.StatusConflict, models.APIError{Code: "WRITING_NOT_SUBMITTED", Message: "Submit the draft before using it"})
+		return false
+	}
+	return true
+}
+
+// aiErrorResponse maps an error from performReview to an HTTP status and API error.
+func aiErrorResponse(err error) (int, models.APIError) {
+	if errors.Is(err, errQuotaExceeded) {
+		return http.StatusTooManyRequests, models.APIError{Code: "QUOTA_EXCEEDED", Message: err.Error()}
+	}
+	if errors.Is(err, services.ErrCircuitOpen) {
+		return http.StatusServiceUnavailable, models.APIError{Code: "AI_SERVICE_UNAVAILABLE", Message: err.Error()}
+	}
+	if errors.Is(err, services.ErrInvalidAIResponse) {
+		return http.StatusBadGateway, models.APIError{Code: "AI_RESPONSE_INVALID", Message: "The AI returned a review that failed validation: " + err.Error()}
+	}
+	return http.StatusInternalServerError, models.APIError{Code: "AI_SERVICE_ERROR", Message: err.Error()}
 }
 
 // GetWritingByID - Get details of a specific writing record by ID
@@ -133,9 +616,10 @@ func (c *Container) GetWritingByID(ctx *gin.Context) {
 		return
 	}
 
-	// Find the writing record in the database, ensuring it belongs to the authenticated user.
+	// Find the writing record in the database, ensuring it belongs to the authenticated user
+	// or to a mentee who accepted them as mentor.
 	var gormWriting models.GormWriting
-	if err := c.DB.Where("id = ? AND user_id = ?", writingID, userID).First(&gormWriting).Error; err != nil {
+	if err := c.DB.Where("id = ?", writingID).Scopes(c.visibleWritings(userID.(uint))).First(&gormWriting).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			ctx.JSON(http.StatusNotFound, models.APIError{Code: "WRITING_NOT_FOUND", Message: "Writing not found or you don't have permission to view it"})
 			return
@@ -144,11 +628,50 @@ func (c *Container) GetWritingByID(ctx *gin.Context) {
 		return
 	}
 
-	// Map GORM model to API model for the response and return it
-	ctx.JSON(http.StatusOK, mapGormWritingToAPI(gormWriting))
+	apiWriting := mapGormWritingToAPI(gormWriting)
+	if metrics, err := json.Marshal(services.AnalyzeText(gormWriting.Content)); err == nil {
+		apiWriting.Metrics = metrics
+	}
+
+	// Include the annotations of the latest review so the client can highlight the commented spans.
+	if gormWriting.LatestReviewID != nil {
+		var latestReview models.GormReview
+		if err := c.DB.Select("id", "annotations").First(&latestReview, *gormWriting.LatestReviewID).Error; err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			ctx.JSON(http.StatusInternalServerError, models.APIError{Code: "DATABASE_ERROR", Message: "Failed to fetch review"})
+			return
+		}
+		apiWriting.Annotations = decodeAnnotations(latestReview.Annotations)
+	}
+
+	// Show the peer reviews next to the AI review.
+	var gormPeerReviews []models.GormPeerReview
+	if err := c.DB.Preload("Reviewer").Where("writing_id = ?", gormWriting.ID).Order("created_at asc").Find(&gormPeerReviews).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, models.APIError{Code: "DATABASE_ERROR", Message: "Failed to fetch peer reviews"})
+		return
+	}
+	for _, r := range gormPeerReviews {
+		apiWriting.PeerReviews = append(apiWriting.PeerReviews, mapGormPeerReviewToAPI(r))
+	}
+
+	mentorComments, err := c.mentorComments(gormWriting.ID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, models.APIError{Code: "DATABASE_ERROR", Message: "Failed to fetch mentor comments"})
+		return
+	}
+	if len(mentorComments) > 0 {
+		apiWriting.MentorComments = mentorComments
+	}
+
+	ctx.JSON(http.StatusOK, apiWriting)
 }
 
 // ListUserWritings - Get a list of all writings for the authenticated user
+//
+// The list can be filtered by status, theme, category, score range, whether the writing
+// was reviewed and creation date, and sorted by date, score or duration. With limit, it is
+// paginated: the X-Next-Cursor header carries the cursor of the next page. With
+// view=excerpt, writings are listed as summaries with an excerpt of their content.
+// A mentor can pass the userId of a mentee to list the mentee's submitted writings instead.
 func (c *Container) ListUserWritings(ctx *gin.Context) {
 	// Get user ID from the context (set by the auth middleware)
 	userID, exists := ctx.Get("userId")
@@ -156,13 +679,74 @@ func (c *Container) ListUserWritings(ctx *gin.Context) {
 		ctx.JSON(http.StatusUnauthorized, models.APIError{Code: "UNAUTHORIZED", Message: "User ID not found in token"})
 		return
 	}
+	ownerID, ok := c.viewedUserID(ctx, userID.(uint))
+	if !ok {
+		return
+	}
+
+	filters, ok := parseWritingFilters(ctx)
+	if !ok {
+		return
+	}
+	sortKey, columns, ok := parseWritingSort(ctx)
+	if !ok {
+		return
+	}
+	page, ok := parsePageQuery(ctx)
+	if !ok {
+		return
+	}
+	paginate, err := page.scope(columns)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, models.APIError{Code: "INVALID_INPUT", Message: err.Error()})
+		return
+	}
+	view := ctx.DefaultQuery("view", "full")
+	if view != "full" && view != "excerpt" {
+		ctx.JSON(http.StatusBadRequest, models.APIError{Code: "INVALID_INPUT", Message: "view must be full or excerpt"})
+		return
+	}
+
+	query := c.DB.Table("gorm_writings").
+		Joins("LEFT JOIN gorm_themes ON gorm_themes.id = gorm_writings.theme_id").
+		Where("gorm_writings.deleted_at IS NULL AND gorm_writings.user_id = ?", ownerID).
+		Scopes(filters, paginate)
+	if ownerID != userID.(uint) {
+		// A mentor only sees the mentee's submitted writings, not their drafts.
+		query = query.Where("gorm_writings.status = ?", models.WritingStatusSubmitted)
+	}
+
+	if view == "excerpt" {
+		var rows []writingSummaryRow
+		if err := query.Select(
+			"gorm_writings.id, gorm_writings.theme_id, gorm_themes.title AS theme_title, gorm_themes.category AS theme_category, "+
+				"SUBSTRING(gorm_writings.content, 1, ?) AS excerpt, CHAR_LENGTH(gorm_writings.content) AS character_count, "+
+				"gorm_writings.status, gorm_writings.duration_seconds, gorm_writings.ai_score, gorm_writings.mentor_score, "+
+				"gorm_writings.created_at, gorm_writings.updated_at", excerptLength).
+			Find(&rows).Error; err != nil {
+			ctx.JSON(http.StatusInternalServerError, models.APIError{Code: "DATABASE_ERROR", Message: "Failed to fetch writings"})
+			return
+		}
+		rows = setNextCursor(ctx, page, rows, func(r writingSummaryRow) []string {
+			return writingCursor(sortKey, r.ID, r.CreatedAt, r.AIScore, r.DurationSeconds)
+		})
+
+		summaries := make([]models.WritingSummary, len(rows))
+		for i, r := range rows {
+			summaries[i] = mapWritingSummaryRowToAPI(r)
+		}
+		ctx.JSON(http.StatusOK, summaries)
+		return
+	}
 
-	// Find all writings for the authenticated user, ordered by most recent
 	var gormWritings []models.GormWriting
-	if err := c.DB.Where("user_id = ?", userID).Order("created_at desc").Find(&gormWritings).Error; err != nil {
+	if err := query.Select("gorm_writings.*").Find(&gormWritings).Error; err != nil {
 		ctx.JSON(http.StatusInternalServerError, models.APIError{Code: "DATABASE_ERROR", Message: "Failed to fetch writings"})
 		return
 	}
+	gormWritings = setNextCursor(ctx, page, gormWritings, func(w models.GormWriting) []string {
+		return writingCursor(sortKey, w.ID, w.CreatedAt, w.AIScore, w.DurationSeconds)
+	})
 
 	// Map GORM writings to API writings
 	apiWritings := make([]models.Writing, len(gormWritings))
@@ -173,6 +757,189 @@ func (c *Container) ListUserWritings(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, apiWritings)
 }
 
+// excerptLength is the number of characters of content in a writing summary.
+const excerptLength = 120
+
+// writingSummaryRow is a row of the excerpt view of ListUserWritings.
+type writingSummaryRow struct {
+	ID              uint
+	ThemeID         uint
+	ThemeTitle      string
+	ThemeCategory   string
+	Excerpt         string
+	CharacterCount  int
+	Status          string
+	DurationSeconds int
+	AIScore         *int
+	MentorScore     *int
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+}
+
+// parseWritingFilters reads the filter query parameters of ListUserWritings. It writes an
+// error response and returns false if one is invalid.
+func parseWritingFilters(ctx *gin.Context) (func(*gorm.DB) *gorm.DB, bool) {
+	var conditions []func(*gorm.DB) *gorm.DB
+	invalid := func(message string) (func(*gorm.DB) *gorm.DB, bool) {
+		ctx.JSON(http.StatusBadRequest, models.APIError{Code: "INVALID_INPUT", Message: message})
+		return nil, false
+	}
+	where := func(query string, args ...any) {
+		conditions = append(conditions, func(db *gorm.DB) *gorm.DB { return db.Where(query, args...) })
+	}
+
+	switch status := ctx.Query("status"); status {
+	case "":
+	case models.WritingStatusDraft, models.WritingStatusSubmitted:
+		where("gorm_writings.status = ?", status)
+	default:
+		return invalid("status must be draft or submitted")
+	}
+	if themeIDStr := ctx.Query("themeId"); themeIDStr != "" {
+		themeID, err := strconv.ParseUint(themeIDStr, 10, 64)
+		if err != nil {
+			return invalid("Invalid theme ID format")
+		}
+		where("gorm_writings.theme_id = ?", themeID)
+	}
+	if category := ctx.Query("category"); category != "" {
+		where("gorm_themes.category = ?", category)
+	}
+	for _, bound := range []struct{ param, op string }{{"minScore", ">="}, {"maxScore", "<="}} {
+		if scoreStr := ctx.Query(bound.param); scoreStr != "" {
+			score, err := strconv.Atoi(scoreStr)
+			if err != nil || score < 0 || score > 100 {
+				return invalid(bound.param + " must be between 0 and 100")
+			}
+			where("gorm_writings.ai_score "+bound.op+" ?", score)
+		}
+	}
+	switch ctx.Query("reviewed") {
+	case "":
+	case "true":
+		where("gorm_writings.ai_score IS NOT NULL")
+	case "false":
+		where("gorm_writings.ai_score IS NULL")
+	default:
+		return invalid("reviewed must be true or false")
+	}
+	if fromStr := ctx.Query("from"); fromStr != "" {
+		from, _, err := parseDateParam(fromStr)
+		if err != nil {
+			return invalid("from must be a date (YYYY-MM-DD) or an RFC 3339 timestamp")
+		}
+		where("gorm_writings.created_at >= ?", from)
+	}
+	if toStr := ctx.Query("to"); toStr != "" {
+		to, dateOnly, err := parseDateParam(toStr)
+		if err != nil {
+			return invalid("to must be a date (YYYY-MM-DD) or an RFC 3339 timestamp")
+		}
+		// A date includes the whole day.
+		if dateOnly {
+			where("gorm_writings.created_at < ?", to.AddDate(0, 0, 1))
+		} else {
+			where("gorm_writings.created_at <= ?", to)
+		}
+	}
+
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Scopes(conditions...)
+	}, true
+}
+
+// parseDateParam parses a date in server local time or an RFC 3339 timestamp, and
+// reports whether it was a date.
+func parseDateParam(value string) (time.Time, bool, error) {
+	if t, err := time.ParseInLocation(time.DateOnly, value, time.Local); err == nil {
+		return t, true, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	return t, false, err
+}
+
+// Sort keys of ListUserWritings.
+const (
+	writingSortDate     = "date"
+	writingSortScore    = "score"
+	writingSortDuration = "duration"
+)
+
+// parseWritingSort reads the sort and order query parameters of ListUserWritings and
+// returns the sort key with its ordering. Writings without a score sort below every
+// scored writing. It writes an error response and returns false if they are invalid.
+func parseWritingSort(ctx *gin.Context) (string, []sortColumn, bool) {
+	desc := true
+	switch ctx.DefaultQuery("order", "desc") {
+	case "desc":
+	case "asc":
+		desc = false
+	default:
+		ctx.JSON(http.StatusBadRequest, models.APIError{Code: "INVALID_INPUT", Message: "order must be asc or desc"})
+		return "", nil, false
+	}
+
+	sortKey := ctx.DefaultQuery("sort", writingSortDate)
+	var columns []sortColumn
+	switch sortKey {
+	case writingSortDate:
+		columns = []sortColumn{{expr: "gorm_writings.created_at", desc: desc, time: true}}
+	case writingSortScore:
+		columns = []sortColumn{{expr: "COALESCE(gorm_writings.ai_score, -1)", desc: desc}}
+	case writingSortDuration:
+		columns = []sortColumn{{expr: "gorm_writings.duration_seconds", desc: desc}}
+	default:
+		ctx.JSON(http.StatusBadRequest, models.APIError{Code: "INVALID_INPUT", Message: "sort must be date, score or duration"})
+		return "", nil, false
+	}
+	return sortKey, append(columns, sortColumn{expr: "gorm_writings.id", desc: desc}), true
+}
+
+// writingCursor returns the sort values of a writing for the ordering of sortKey.
+func writingCursor(sortKey string, id uint, createdAt time.Time, aiScore *int, durationSeconds int) []string {
+	var value string
+	switch sortKey {
+	case writingSortDate:
+		value = cursorTime(createdAt)
+	case writingSortDuration:
+		value = cursorInt(durationSeconds)
+	default:
+		score := -1
+		if aiScore != nil {
+			score = *aiScore
+		}
+		value = cursorInt(score)
+	}
+	return []string{value, cursorInt(id)}
+}
+
+// mapWritingSummaryRowToAPI converts a row of the excerpt view to an API writing summary.
+func mapWritingSummaryRowToAPI(row writingSummaryRow) models.WritingSummary {
+	return models.WritingSummary{
+		ID:              int64(row.ID),
+		ThemeID:         int64(row.ThemeID),
+		ThemeTitle:      row.ThemeTitle,
+		ThemeCategory:   row.ThemeCategory,
+		Excerpt:         excerptText(row.Excerpt, row.CharacterCount),
+		CharacterCount:  row.CharacterCount,
+		Status:          row.Status,
+		DurationSeconds: int32(row.DurationSeconds),
+		AiScore:         row.AIScore,
+		MentorScore:     row.MentorScore,
+		CreatedAt:       row.CreatedAt,
+		UpdatedAt:       row.UpdatedAt,
+	}
+}
+
+// excerptText returns an excerpt of excerptLength characters, ending with "…" when the
+// content of characterCount characters is longer.
+func excerptText(excerpt string, characterCount int) string {
+	if characterCount > excerptLength {
+		return excerpt + "…"
+	}
+	return excerpt
+}
+
 // mapGormWritingToAPI converts a GORM writing model to an API writing model.
 func mapGormWritingToAPI(gormWriting models.GormWriting) models.Writing {
 	apiWriting := models.Writing{
@@ -181,6 +948,10 @@ func mapGormWritingToAPI(gormWriting models.GormWriting) models.Writing {
 		ThemeID:         int64(gormWriting.ThemeID),
 		Content:         gormWriting.Content,
 		DurationSeconds: int32(gormWriting.DurationSeconds),
+		Status:          gormWriting.Status,
+		LastSavedAt:     gormWriting.LastSavedAt,
+		SessionID:       gormWriting.SessionID,
+		Overtime:        gormWriting.Overtime,
 		CreatedAt:       gormWriting.CreatedAt,
 		UpdatedAt:       gormWriting.UpdatedAt,
 	}
@@ -194,6 +965,16 @@ func mapGormWritingToAPI(gormWriting models.GormWriting) models.Writing {
 	if gormWriting.AIFeedback != nil {
 		apiWriting.AiFeedback = string(gormWriting.AIFeedback)
 	}
+	apiWriting.LatestReviewID = gormWriting.LatestReviewID
+	apiWriting.InjectionFlagged = gormWriting.InjectionFlagged
+	apiWriting.InjectionSignals = gormWriting.InjectionSignals
+	apiWriting.InjectionNeedsReview = !gormWriting.InjectionFlagged && len(gormWriting.InjectionSignals) > 0
+	apiWriting.OpenForPeerReview = gormWriting.OpenForPeerReview
+	apiWriting.MentorScore = gormWriting.MentorScore
+	apiWriting.EditedAfterSubmitAt = gormWriting.EditedAfterSubmitAt
+	if gormWriting.DeletedAt.Valid {
+		apiWriting.DeletedAt = &gormWriting.DeletedAt.Time
+	}
 
 	return apiWriting
 }
@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/ch00z00/kotobalize/models"
+	"github.com/gin-gonic/gin"
+)
+
+func TestAutosaveSubmittedWriting(t *testing.T) {
+	c := newTestContainer(t)
+	user := createTestUser(t, c.DB, "alice")
+	theme := createTestTheme(t, c.DB, 600)
+	writing := createTestWriting(t, c.DB, user.ID, theme.ID, "提出した文章")
+
+	content := "書き換えた文章"
+	for _, req := range []models.AutosaveWritingRequest{
+		{Content: &content},
+		// Not even a request that changes nothing, which would otherwise succeed.
+		{Status: models.WritingStatusSubmitted},
+	} {
+		rec := serve(t, c.AutosaveWriting, http.MethodPatch, writingRoute, writingPath(writing.ID, ""), user.ID, req)
+		expectError(t, rec, http.StatusConflict, "WRITING_SUBMITTED")
+	}
+
+	var saved models.GormWriting
+	if err := c.DB.First(&saved, writing.ID).Error; err != nil {
+		t.Fatal(err)
+	}
+	if saved.Content != "提出した文章" || saved.LastSavedAt != nil {
+		t.Errorf("writing = %q saved at %v, want it untouched", saved.Content, saved.LastSavedAt)
+	}
+}
+
+func TestDraftMustBeSubmittedFirst(t *testing.T) {
+	c := newTestContainer(t)
+	user := createTestUser(t, c.DB, "alice")
+	theme := createTestTheme(t, c.DB, 600)
+	draft := models.GormWriting{UserID: user.ID, ThemeID: theme.ID, Content: "書きかけの文章", Status: models.WritingStatusDraft}
+	mustCreate(t, c.DB, &draft)
+
+	open, closed := true, false
+	review := models.NewReviewRequest{WritingID: int64(draft.ID)}
+	path := writingPath(draft.ID, "")
+	for _, r := range []struct {
+		name          string
+		handler       gin.HandlerFunc
+		route, target string
+		body          any
+	}{
+		{"review", c.ReviewWriting, "/review", "/review", review},
+		{"stream review", c.StreamReviewWriting, "/review/stream", "/review/stream", review},
+		{"interview", c.StartInterview, interviewRoute, path + "/interview", nil},
+		{"rewrite", c.RewriteWriting, writingRoute + "/rewrite", path + "/rewrite", nil},
+	} {
+		t.Run(r.name, func(t *testing.T) {
+			expectError(t, serve(t, r.handler, http.MethodPost, r.route, r.target, user.ID, r.body), http.StatusConflict, "WRITING_NOT_SUBMITTED")
+		})
+	}
+	rec := serve(t, c.UpdatePeerReviewSettings, http.MethodPut, writingRoute+"/peer-review", path+"/peer-review", user.ID, models.PeerReviewSettingsRequest{Open: &open})
+	expectError(t, rec, http.StatusConflict, "WRITING_NOT_SUBMITTED")
+
+	// Nothing was started for the draft, and no AI call was made or counted.
+	for _, model := range []any{&models.GormReviewJob{}, &models.GormReview{}, &models.GormInterview{}, &models.GormRewrite{}, &models.GormAIUsage{}} {
+		var count int64
+		if err := c.DB.Model(model).Count(&count).Error; err != nil {
+			t.Fatal(err)
+		}
+		if count != 0 {
+			t.Errorf("%T: %d rows, want none", model, count)
+		}
+	}
+	var saved models.GormWriting
+	if err := c.DB.First(&saved, draft.ID).Error; err != nil {
+		t.Fatal(err)
+	}
+	if saved.Status != models.WritingStatusDraft || saved.OpenForPeerReview || saved.AIScore != nil {
+		t.Errorf("draft = %+v, want it unchanged", saved)
+	}
+
+	// Closing a draft to peer review is harmless, and allowed.
+	rec = serve(t, c.UpdatePeerReviewSettings, http.MethodPut, writingRoute+"/peer-review", path+"/peer-review", user.ID, models.PeerReviewSettingsRequest{Open: &closed})
+	if got := decodeResponse[models.Writing](t, rec, http.StatusOK); got.OpenForPeerReview {
+		t.Errorf("closed draft = %+v, want it closed", got)
+	}
+}
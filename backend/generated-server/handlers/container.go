@@ -5,23 +5,36 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"strconv"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
 	"github.com/ch00z00/kotobalize/models"
-	openai "github.com/sashabaranov/go-openai"
+	"github.com/ch00z00/kotobalize/services"
 	"gorm.io/driver/mysql"
 	"gorm.io/gorm"
 )
 
 // Container will hold all dependencies for your application.
 type Container struct {
-	DB           *gorm.DB
-	JWTSecret    string
-	OpenAIClient *openai.Client
-	S3Client     *s3.Client
-	S3BucketName string
+	DB        *gorm.DB
+	JWTSecret string
+	Reviewer  services.Reviewer
+	Quota     QuotaConfig
+	// ReviewModel is the model name that reviews by Reviewer are recorded with. Only
+	// cached reviews by the same model are reused.
+	ReviewModel string
+	// ReviewCacheTTL is how long a stored review may be reused for identical input. 0 disables the cache.
+	ReviewCacheTTL time.Duration
+	// HeuristicFallback saves a provisional heuristic review when the AI reviewer cannot be used.
+	HeuristicFallback bool
+	// SessionGrace is how long after the time limit a writing session can still be used.
+	SessionGrace time.Duration
+	// ReviewWorkers is set by StartReviewWorkers.
+	ReviewWorkers *ReviewWorkerPool
+	S3Client      *s3.Client
+	S3BucketName  string
 }
 
 // min returns the minimum of two integers
@@ -32,13 +45,31 @@ func min(a, b int) int {
 	return b
 }
 
+// envInt reads a positive integer from the environment, falling back to def.
+func envInt(key string, def int) int {
+	value, err := strconv.Atoi(os.Getenv(key))
+	if err != nil || value <= 0 {
+		return def
+	}
+	return value
+}
+
+// ReviewerCircuitState reports the circuit breaker of the AI reviewer, or nil if it has none.
+func (c *Container) ReviewerCircuitState() *services.CircuitBreakerState {
+	resilient, ok := c.Reviewer.(*services.ResilientReviewer)
+	if !ok {
+		return nil
+	}
+	state := resilient.CircuitState()
+	return &state
+}
+
 // NewContainer returns an empty or an initialized container for your handlers.
 func NewContainer() (Container, error) {
 	log.Println("Starting container initialization...")
-	
+
 	// Load environment variables
 	jwtSecret := os.Getenv("JWT_SECRET")
-	openaiAPIKey := os.Getenv("OPENAI_API_KEY")
 	awsRegion := os.Getenv("AWS_REGION")
 	s3BucketName := os.Getenv("S3_BUCKET_NAME")
 
@@ -46,9 +77,6 @@ func NewContainer() (Container, error) {
 	if jwtSecret == "" {
 		return Container{}, fmt.Errorf("JWT_SECRET environment variable is required")
 	}
-	if openaiAPIKey == "" {
-		return Container{}, fmt.Errorf("OPENAI_API_KEY environment variable is required")
-	}
 
 	var dsn string
 
@@ -56,7 +84,7 @@ func NewContainer() (Container, error) {
 	if databaseURL := os.Getenv("DATABASE_URL"); databaseURL != "" {
 		log.Println("Using DATABASE_URL for database connection")
 		dsn = databaseURL
-		
+
 		// Check if we're running on Cloud Run with Cloud SQL
 		if instanceConnectionName := os.Getenv("INSTANCE_CONNECTION_NAME"); instanceConnectionName != "" {
 			log.Printf("Detected Cloud SQL instance: %s", instanceConnectionName)
@@ -80,13 +108,14 @@ func NewContainer() (Container, error) {
 	// Connect to MySQL with GORM (with retry logic)
 	log.Println("Attempting to connect to database...")
 	log.Printf("DSN (first 50 chars): %s...", dsn[:min(50, len(dsn))])
-	
+
 	var db *gorm.DB
 	var err error
-	
+
 	// Retry connection up to 5 times with exponential backoff
 	for i := 0; i < 5; i++ {
-		db, err = gorm.Open(mysql.Open(dsn), &gorm.Config{})
+		// TranslateError reports duplicate keys as gorm.ErrDuplicatedKey.
+		db, err = gorm.Open(mysql.Open(dsn), &gorm.Config{TranslateError: true})
 		if err == nil {
 			// Test the connection
 			sqlDB, err := db.DB()
@@ -98,7 +127,7 @@ func NewContainer() (Container, error) {
 				}
 			}
 		}
-		
+
 		log.Printf("Database connection attempt %d failed: %v", i+1, err)
 		if i < 4 {
 			waitTime := time.Duration(1<<uint(i)) * time.Second
@@ -106,22 +135,29 @@ func NewContainer() (Container, error) {
 			time.Sleep(waitTime)
 		}
 	}
-	
+
 	if err != nil {
 		return Container{}, fmt.Errorf("failed to connect to database after 5 attempts: %w", err)
 	}
 
 	// Auto migrate the schema
 	log.Println("Running database migrations...")
-	err = db.AutoMigrate(&models.GormUser{}, &models.GormWriting{}, &models.GormTheme{}, &models.UserFavoriteTheme{})
+	err = db.AutoMigrate(&models.GormUser{}, &models.GormWriting{}, &models.GormTheme{}, &models.UserFavoriteTheme{}, &models.GormReviewJob{}, &models.GormRubric{}, &models.GormReview{}, &models.GormAIUsage{}, &models.GormRewrite{}, &models.GormInterview{}, &models.GormInterviewTurn{}, &models.GormPeerReview{}, &models.GormMentorship{}, &models.GormMentorComment{}, &models.GormWritingRevision{}, &models.GormWritingSession{})
 	if err != nil {
 		return Container{}, fmt.Errorf("failed to run migrations: %w", err)
 	}
+	if err := MigrateSearchIndexes(db); err != nil {
+		return Container{}, fmt.Errorf("failed to run migrations: %w", err)
+	}
 	log.Println("Database migrations completed successfully")
 
-	// Initialize OpenAI client
-	log.Println("Initializing OpenAI client...")
-	openaiClient := openai.NewClient(openaiAPIKey)
+	// Initialize the AI reviewer selected by AI_REVIEWER
+	reviewerConfig := services.LoadReviewerConfig()
+	log.Printf("Initializing AI reviewer (backend: %s)...", reviewerConfig.Backend)
+	reviewer, err := services.NewReviewer(reviewerConfig)
+	if err != nil {
+		return Container{}, fmt.Errorf("failed to initialize AI reviewer: %w", err)
+	}
 
 	// Initialize S3 client
 	log.Println("Initializing AWS S3 client...")
@@ -133,9 +169,14 @@ func NewContainer() (Container, error) {
 
 	log.Println("Container initialization completed successfully")
 	c := Container{DB: db,
-		JWTSecret:    jwtSecret,
-		OpenAIClient: openaiClient,
-		S3Client:     s3Client,
-		S3BucketName: s3BucketName}
+		JWTSecret:         jwtSecret,
+		Reviewer:          reviewer,
+		ReviewModel:       reviewerConfig.ModelName(),
+		Quota:             loadQuotaConfig(),
+		ReviewCacheTTL:    time.Duration(services.EnvNonNegativeInt("REVIEW_CACHE_TTL_HOURS", defaultReviewCacheTTLHours)) * time.Hour,
+		HeuristicFallback: os.Getenv("REVIEW_HEURISTIC_FALLBACK") != "false",
+		SessionGrace:      time.Duration(services.EnvNonNegativeInt("WRITING_SESSION_GRACE_MINUTES", defaultSessionGraceMinutes)) * time.Minute,
+		S3Client:          s3Client,
+		S3BucketName:      s3BucketName}
 	return c, nil
 }
@@ -0,0 +1,154 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ch00z00/kotobalize/models"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// maxPageSize caps the limit query parameter of paginated lists.
+const maxPageSize = 100
+
+// nextCursorHeader carries the cursor of the next page of a paginated list.
+// It is absent on the last page.
+const nextCursorHeader = "X-Next-Cursor"
+
+var errInvalidCursor = errors.New("invalid cursor")
+
+// sortColumn is one column of the ordering of a paginated list. The last column of
+// an ordering must be unique, such as the ID, so that pages neither skip nor repeat rows.
+type sortColumn struct {
+	expr string // SQL expression, e.g. "gorm_writings.created_at"
+	desc bool
+	time bool // values are timestamps rather than integers
+}
+
+// pageQuery is the pagination requested by the limit and cursor query parameters.
+// A zero limit means the list is not paginated.
+type pageQuery struct {
+	limit int
+	after []string // sort values of the last row of the previous page
+}
+
+// parsePageQuery reads the limit and cursor query parameters. It writes an error
+// response and returns false if they are invalid.
+func parsePageQuery(ctx *gin.Context) (pageQuery, bool) {
+	var page pageQuery
+	if limitStr := ctx.Query("limit"); limitStr != "" {
+		limit, err := strconv.Atoi(limitStr)
+		if err != nil || limit < 1 || limit > maxPageSize {
+			ctx.JSON(http.StatusBadRequest, models.APIError{Code: "INVALID_INPUT", Message: fmt.Sprintf("limit must be between 1 and %d", maxPageSize)})
+			return pageQuery{}, false
+		}
+		page.limit = limit
+	}
+	if cursor := ctx.Query("cursor"); cursor != "" {
+		if page.limit == 0 {
+			ctx.JSON(http.StatusBadRequest, models.APIError{Code: "INVALID_INPUT", Message: "cursor requires limit"})
+			return pageQuery{}, false
+		}
+		data, err := base64.RawURLEncoding.DecodeString(cursor)
+		if err == nil {
+			err = json.Unmarshal(data, &page.after)
+		}
+		if err != nil || len(page.after) == 0 {
+			ctx.JSON(http.StatusBadRequest, models.APIError{Code: "INVALID_INPUT", Message: errInvalidCursor.Error()})
+			return pageQuery{}, false
+		}
+	}
+	return page, true
+}
+
+// scope orders a query by columns and, when paginated, limits it to the rows after the
+// cursor. One more row than the limit is fetched to tell whether there is a next page.
+func (p pageQuery) scope(columns []sortColumn) (func(*gorm.DB) *gorm.DB, error) {
+	order := make([]string, len(columns))
+	for i, col := range columns {
+		order[i] = col.expr + " asc"
+		if col.desc {
+			order[i] = col.expr + " desc"
+		}
+	}
+
+	var where string
+	var args []any
+	if p.after != nil {
+		if len(p.after) != len(columns) {
+			return nil, errInvalidCursor
+		}
+		values := make([]any, len(columns))
+		for i, col := range columns {
+			var err error
+			if col.time {
+				values[i], err = time.Parse(time.RFC3339Nano, p.after[i])
+			} else {
+				values[i], err = strconv.ParseInt(p.after[i], 10, 64)
+			}
+			if err != nil {
+				return nil, errInvalidCursor
+			}
+		}
+		// (a, b, c) after (x, y, z): a > x OR (a = x AND b > y) OR (a = x AND b = y AND c > z),
+		// with < for descending columns.
+		var alternatives []string
+		for i, col := range columns {
+			var terms []string
+			for j := range i {
+				terms = append(terms, columns[j].expr+" = ?")
+				args = append(args, values[j])
+			}
+			op := " > ?"
+			if col.desc {
+				op = " < ?"
+			}
+			terms = append(terms, col.expr+op)
+			args = append(args, values[i])
+			alternatives = append(alternatives, "("+strings.Join(terms, " AND ")+")")
+		}
+		where = "(" + strings.Join(alternatives, " OR ") + ")"
+	}
+
+	return func(db *gorm.DB) *gorm.DB {
+		if where != "" {
+			db = db.Where(where, args...)
+		}
+		db = db.Order(strings.Join(order, ", "))
+		if p.limit > 0 {
+			db = db.Limit(p.limit + 1)
+		}
+		return db
+	}, nil
+}
+
+// setNextCursor trims rows fetched with scope to the page and, if there is a next page,
+// sets the X-Next-Cursor header from the sort values of the page's last row.
+func setNextCursor[T any](ctx *gin.Context, p pageQuery, rows []T, values func(T) []string) []T {
+	if p.limit == 0 || len(rows) <= p.limit {
+		return rows
+	}
+	rows = rows[:p.limit]
+	data, err := json.Marshal(values(rows[len(rows)-1]))
+	if err == nil {
+		ctx.Header(nextCursorHeader, base64.RawURLEncoding.EncodeToString(data))
+	}
+	return rows
+}
+
+// cursorTime formats a timestamp for a cursor.
+func cursorTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339Nano)
+}
+
+// cursorInt formats an integer for a cursor.
+func cursorInt[T ~int | ~uint](n T) string {
+	return strconv.FormatInt(int64(n), 10)
+}
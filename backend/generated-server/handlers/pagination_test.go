@@ -0,0 +1,234 @@
+package handlers
+
+import (
+	"cmp"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/ch00z00/kotobalize/models"
+	"github.com/gin-gonic/gin"
+)
+
+// listWritings calls ListUserWritings with the query and returns the IDs it listed
+// and the cursor of the next page.
+func listWritings(t *testing.T, c *Container, userID uint, query url.Values) ([]uint, string) {
+	t.Helper()
+	rec := serve(t, c.ListUserWritings, http.MethodGet, "/writings", "/writings?"+query.Encode(), userID, nil)
+	writings := decodeResponse[[]models.Writing](t, rec, http.StatusOK)
+	ids := make([]uint, len(writings))
+	for i, w := range writings {
+		ids[i] = uint(w.ID)
+	}
+	return ids, rec.Header().Get(nextCursorHeader)
+}
+
+// cursorOf encodes sort values as a cursor.
+func cursorOf(values string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(values))
+}
+
+func TestListUserWritingsRejectsInvalidPages(t *testing.T) {
+	c := newTestContainer(t)
+	user := createTestUser(t, c.DB, "alice")
+
+	for name, query := range map[string]url.Values{
+		"zero limit":                   {"limit": {"0"}},
+		"limit over the maximum":       {"limit": {"101"}},
+		"cursor without limit":         {"cursor": {cursorOf(`["1"]`)}},
+		"cursor not in base64":         {"limit": {"2"}, "cursor": {"!!"}},
+		"cursor not a list":            {"limit": {"2"}, "cursor": {cursorOf(`{"id":1}`)}},
+		"empty cursor":                 {"limit": {"2"}, "cursor": {cursorOf(`[]`)}},
+		"cursor too short":             {"limit": {"2"}, "cursor": {cursorOf(`["1"]`)}},
+		"cursor too long":              {"limit": {"2"}, "cursor": {cursorOf(`["2026-01-01T00:00:00Z","1","1"]`)}},
+		"date cursor for a score":      {"limit": {"2"}, "sort": {"score"}, "cursor": {cursorOf(`["2026-01-01T00:00:00Z","1"]`)}},
+		"score cursor for a date":      {"limit": {"2"}, "cursor": {cursorOf(`["80","1"]`)}},
+		"fractional id in cursor":      {"limit": {"2"}, "sort": {"duration"}, "cursor": {cursorOf(`["60","1.5"]`)}},
+		"unknown sort":                 {"sort": {"title"}},
+		"unknown order":                {"order": {"newest"}},
+		"unknown status":               {"status": {"deleted"}},
+		"score out of range":           {"minScore": {"101"}},
+		"reviewed not a boolean":       {"reviewed": {"yes"}},
+		"date with a time and no zone": {"to": {"2026-03-01T12:00:00"}},
+	} {
+		rec := serve(t, c.ListUserWritings, http.MethodGet, "/writings", "/writings?"+query.Encode(), user.ID, nil)
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, want 400: %s", name, rec.Code, rec.Body)
+		}
+	}
+}
+
+func TestListUserWritingsPagesEveryWritingOnce(t *testing.T) {
+	c := newTestContainer(t)
+	user := createTestUser(t, c.DB, "alice")
+	theme := createTestTheme(t, c.DB, 600)
+
+	// Ties in every sort key, and scores of 0 next to unreviewed writings.
+	at := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	score := func(n int) *int { return &n }
+	var writings []models.GormWriting
+	for _, w := range []struct {
+		createdAt time.Time
+		score     *int
+		duration  int
+	}{
+		{at, score(80), 300},
+		{at, nil, 300},
+		{at.Add(time.Millisecond), score(0), 120},
+		{at, score(80), 60},
+		{at.Add(-time.Hour), nil, 300},
+		{at.Add(time.Hour), score(95), 120},
+		{at, score(0), 60},
+	} {
+		writing := models.GormWriting{UserID: user.ID, ThemeID: theme.ID, Content: "本文", Status: models.WritingStatusSubmitted,
+			AIScore: w.score, DurationSeconds: w.duration}
+		writing.CreatedAt = w.createdAt
+		mustCreate(t, c.DB, &writing)
+		writings = append(writings, writing)
+	}
+
+	scoreOf := func(w models.GormWriting) int {
+		if w.AIScore == nil {
+			return -1
+		}
+		return *w.AIScore
+	}
+	keys := map[string]func(a, b models.GormWriting) int{
+		"date":     func(a, b models.GormWriting) int { return a.CreatedAt.Compare(b.CreatedAt) },
+		"score":    func(a, b models.GormWriting) int { return cmp.Compare(scoreOf(a), scoreOf(b)) },
+		"duration": func(a, b models.GormWriting) int { return cmp.Compare(a.DurationSeconds, b.DurationSeconds) },
+	}
+	for sortKey, compare := range keys {
+		for _, order := range []string{"asc", "desc"} {
+			sorted := slices.Clone(writings)
+			slices.SortFunc(sorted, func(a, b models.GormWriting) int {
+				n := cmp.Or(compare(a, b), cmp.Compare(a.ID, b.ID))
+				if order == "desc" {
+					return -n
+				}
+				return n
+			})
+			want := make([]uint, len(sorted))
+			for i, w := range sorted {
+				want[i] = w.ID
+			}
+
+			query := url.Values{"sort": {sortKey}, "order": {order}}
+			if all, cursor := listWritings(t, c, user.ID, query); !slices.Equal(all, want) || cursor != "" {
+				t.Errorf("sort=%s&order=%s: listed %v with cursor %q, want %v without one", sortKey, order, all, cursor, want)
+			}
+
+			var paged []uint
+			query.Set("limit", "2")
+			for pages := 1; ; pages++ {
+				ids, cursor := listWritings(t, c, user.ID, query)
+				paged = append(paged, ids...)
+				if cursor == "" {
+					break
+				}
+				if pages > len(want) {
+					t.Fatalf("sort=%s&order=%s: pagination does not end", sortKey, order)
+				}
+				query.Set("cursor", cursor)
+			}
+			if !slices.Equal(paged, want) {
+				t.Errorf("sort=%s&order=%s: paged through %v, want %v", sortKey, order, paged, want)
+			}
+		}
+	}
+}
+
+func TestPageScopeMixedOrder(t *testing.T) {
+	c := newTestContainer(t)
+	user := createTestUser(t, c.DB, "alice")
+	theme := createTestTheme(t, c.DB, 600)
+	var want []uint
+	for _, duration := range []int{120, 60, 120, 60} {
+		w := models.GormWriting{UserID: user.ID, ThemeID: theme.ID, Content: "本文", Status: models.WritingStatusDraft, DurationSeconds: duration}
+		mustCreate(t, c.DB, &w)
+		want = append(want, w.ID)
+	}
+	// Shortest first, newest first among equal durations.
+	want = []uint{want[3], want[1], want[2], want[0]}
+	columns := []sortColumn{{expr: "duration_seconds"}, {expr: "id", desc: true}}
+
+	var got []uint
+	target := "/?limit=2"
+	for pages := 0; pages < len(want); pages++ {
+		ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+		ctx.Request = httptest.NewRequest(http.MethodGet, target, nil)
+		page, ok := parsePageQuery(ctx)
+		if !ok {
+			t.Fatalf("parsePageQuery(%q) failed", target)
+		}
+		paginate, err := page.scope(columns)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var rows []models.GormWriting
+		if err := c.DB.Scopes(paginate).Find(&rows).Error; err != nil {
+			t.Fatal(err)
+		}
+		rows = setNextCursor(ctx, page, rows, func(w models.GormWriting) []string {
+			return []string{cursorInt(w.DurationSeconds), cursorInt(w.ID)}
+		})
+		for _, w := range rows {
+			got = append(got, w.ID)
+		}
+		// A full last page has no cursor, rather than one to an empty page.
+		cursor := ctx.Writer.Header().Get(nextCursorHeader)
+		if cursor == "" {
+			break
+		}
+		target = "/?limit=2&cursor=" + cursor
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("paged through %v, want %v", got, want)
+	}
+}
+
+func TestListUserWritingsFilters(t *testing.T) {
+	c := newTestContainer(t)
+	user := createTestUser(t, c.DB, "alice")
+	theme := createTestTheme(t, c.DB, 600)
+	other := models.GormTheme{Title: "TCPとUDP", Description: "比較してください", Category: "network"}
+	mustCreate(t, c.DB, &other)
+
+	day := time.Date(2026, 3, 1, 0, 0, 0, 0, time.Local)
+	score := func(n int) *int { return &n }
+	create := func(themeID uint, status string, aiScore *int, createdAt time.Time) uint {
+		w := models.GormWriting{UserID: user.ID, ThemeID: themeID, Content: "本文", Status: status, AIScore: aiScore}
+		w.CreatedAt = createdAt
+		mustCreate(t, c.DB, &w)
+		return w.ID
+	}
+	startOfDay := create(theme.ID, models.WritingStatusSubmitted, score(50), day)
+	endOfDay := create(theme.ID, models.WritingStatusSubmitted, score(80), day.Add(24*time.Hour-time.Millisecond))
+	nextDay := create(other.ID, models.WritingStatusSubmitted, score(81), day.AddDate(0, 0, 1))
+	draft := create(theme.ID, models.WritingStatusDraft, nil, day.AddDate(0, 0, -1))
+
+	for _, f := range []struct {
+		query url.Values
+		want  []uint
+	}{
+		// A date includes the whole day, a timestamp only up to that instant.
+		{url.Values{"to": {"2026-03-01"}}, []uint{endOfDay, startOfDay, draft}},
+		{url.Values{"to": {day.Format(time.RFC3339)}}, []uint{startOfDay, draft}},
+		{url.Values{"from": {"2026-03-01"}, "to": {"2026-03-01"}}, []uint{endOfDay, startOfDay}},
+		{url.Values{"from": {"2026-03-02"}}, []uint{nextDay}},
+		// Score bounds are inclusive, and unreviewed writings have no score to compare.
+		{url.Values{"minScore": {"50"}, "maxScore": {"80"}}, []uint{endOfDay, startOfDay}},
+		{url.Values{"maxScore": {"100"}}, []uint{nextDay, endOfDay, startOfDay}},
+		{url.Values{"reviewed": {"false"}}, []uint{draft}},
+		{url.Values{"status": {"submitted"}, "category": {"database"}}, []uint{endOfDay, startOfDay}},
+		{url.Values{"themeId": {cursorInt(other.ID)}}, []uint{nextDay}},
+	} {
+		if got, _ := listWritings(t, c, user.ID, f.query); !slices.Equal(got, f.want) {
+			t.Errorf("%s: listed %v, want %v", f.query.Encode(), got, f.want)
+		}
+	}
+}
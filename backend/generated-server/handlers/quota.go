@@ -0,0 +1,136 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/ch00z00/kotobalize/models"
+	"github.com/ch00z00/kotobalize/services"
+	"gorm.io/gorm"
+)
+
+// Default AI quotas per user. A limit of 0 means unlimited.
+const (
+	defaultDailyRequestLimit   = 20
+	defaultMonthlyRequestLimit = 300
+)
+
+// QuotaConfig holds the per-user AI quotas. A limit of 0 means unlimited.
+type QuotaConfig struct {
+	DailyRequests   int64
+	MonthlyRequests int64
+	DailyTokens     int64
+	MonthlyTokens   int64
+}
+
+// loadQuotaConfig reads the quotas from AI_DAILY_REQUEST_LIMIT, AI_MONTHLY_REQUEST_LIMIT,
+// AI_DAILY_TOKEN_LIMIT and AI_MONTHLY_TOKEN_LIMIT.
+func loadQuotaConfig() QuotaConfig {
+	return QuotaConfig{
+		DailyRequests:   int64(services.EnvNonNegativeInt("AI_DAILY_REQUEST_LIMIT", defaultDailyRequestLimit)),
+		MonthlyRequests: int64(services.EnvNonNegativeInt("AI_MONTHLY_REQUEST_LIMIT", defaultMonthlyRequestLimit)),
+		DailyTokens:     int64(services.EnvNonNegativeInt("AI_DAILY_TOKEN_LIMIT", 0)),
+		MonthlyTokens:   int64(services.EnvNonNegativeInt("AI_MONTHLY_TOKEN_LIMIT", 0)),
+	}
+}
+
+// errQuotaExceeded is wrapped by quotaExceededError so callers can use errors.Is.
+var errQuotaExceeded = errors.New("AI quota exceeded")
+
+// quotaExceededError describes which quota was exhausted.
+type quotaExceededError struct {
+	Period   string // "daily" or "monthly"
+	Resource string // "requests" or "tokens"
+	Limit    int64
+	ResetsAt time.Time
+}
+
+func (e *quotaExceededError) Error() string {
+	return fmt.Sprintf("%v: %s %s limit of %d reached, resets at %s",
+		errQuotaExceeded, e.Period, e.Resource, e.Limit, e.ResetsAt.Format(time.RFC3339))
+}
+
+func (e *quotaExceededError) Unwrap() error { return errQuotaExceeded }
+
+// usageTotals is the aggregated AI usage of a user within a period.
+type usageTotals struct {
+	Requests int64 `gorm:"column:requests"`
+	Tokens   int64 `gorm:"column:tokens"`
+}
+
+// usageSince aggregates a user's AI usage recorded at or after since.
+func (c *Container) usageSince(userID uint, since time.Time) (usageTotals, error) {
+	var totals usageTotals
+	err := c.DB.Model(&models.GormAIUsage{}).
+		Select("COUNT(*) as requests, COALESCE(SUM(total_tokens), 0) as tokens").
+		Where("user_id = ? AND created_at >= ?", userID, since).
+		Scan(&totals).Error
+	return totals, err
+}
+
+// quotaPeriods returns the start and end of the current day and month.
+func quotaPeriods(now time.Time) (dayStart, dayEnd, monthStart, monthEnd time.Time) {
+	dayStart = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	monthStart = time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+	return dayStart, dayStart.AddDate(0, 0, 1), monthStart, monthStart.AddDate(0, 1, 0)
+}
+
+// checkQuota returns a quotaExceededError if the user has used up any AI quota.
+// It must be called before every model call made on behalf of a user.
+//
+// Usage is only recorded once a call has finished, so the limits are best-effort: requests
+// that pass the check at the same time can together go over a limit by the calls in flight.
+func (c *Container) checkQuota(userID uint) error {
+	dayStart, dayEnd, monthStart, monthEnd := quotaPeriods(time.Now())
+
+	daily, err := c.usageSince(userID, dayStart)
+	if err != nil {
+		return fmt.Errorf("failed to check AI quota: %w", err)
+	}
+	if c.Quota.DailyRequests > 0 && daily.Requests >= c.Quota.DailyRequests {
+		return &quotaExceededError{Period: "daily", Resource: "requests", Limit: c.Quota.DailyRequests, ResetsAt: dayEnd}
+	}
+	if c.Quota.DailyTokens > 0 && daily.Tokens >= c.Quota.DailyTokens {
+		return &quotaExceededError{Period: "daily", Resource: "tokens", Limit: c.Quota.DailyTokens, ResetsAt: dayEnd}
+	}
+
+	monthly, err := c.usageSince(userID, monthStart)
+	if err != nil {
+		return fmt.Errorf("failed to check AI quota: %w", err)
+	}
+	if c.Quota.MonthlyRequests > 0 && monthly.Requests >= c.Quota.MonthlyRequests {
+		return &quotaExceededError{Period: "monthly", Resource: "requests", Limit: c.Quota.MonthlyRequests, ResetsAt: monthEnd}
+	}
+	if c.Quota.MonthlyTokens > 0 && monthly.Tokens >= c.Quota.MonthlyTokens {
+		return &quotaExceededError{Period: "monthly", Resource: "tokens", Limit: c.Quota.MonthlyTokens, ResetsAt: monthEnd}
+	}
+	return nil
+}
+
+// recordAIUsage stores the tokens consumed by one AI request.
+func recordAIUsage(tx *gorm.DB, userID uint, kind, modelName string, usage services.TokenUsage, reviewID *uint) error {
+	return tx.Create(&models.GormAIUsage{
+		UserID:           userID,
+		Kind:             kind,
+		ModelName:        modelName,
+		ReviewID:         reviewID,
+		PromptTokens:     usage.PromptTokens,
+		CompletionTokens: usage.CompletionTokens,
+		TotalTokens:      usage.TotalTokens,
+	}).Error
+}
+
+// recordFailedAIUsage charges the tokens a failed AI request had already spent, such as a
+// review that kept failing validation, so that failures count towards the quota too.
+// Failures that spent nothing are not recorded.
+func (c *Container) recordFailedAIUsage(userID uint, kind string, err error) {
+	usage, modelName, ok := services.SpentUsage(err)
+	if !ok {
+		return
+	}
+	if err := recordAIUsage(c.DB, userID, kind, modelName, usage, nil); err != nil {
+		log.Printf("failed to record AI usage of failed %s request for user %d: %v", kind, userID, err)
+	}
+}
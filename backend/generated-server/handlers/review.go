@@ -0,0 +1,324 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ch00z00/kotobalize/models"
+	"github.com/ch00z00/kotobalize/services"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// defaultReviewCacheTTLHours is how long a review may be reused for identical input
+// unless REVIEW_CACHE_TTL_HOURS says otherwise.
+const defaultReviewCacheTTLHours = 24
+
+// performReview calls the AI reviewer for a writing, records the run as a new
+// GormReview, and points the writing at it. The writing must have its Theme preloaded.
+// When onFeedback is non-nil, the review is streamed and onFeedback receives each
+// viewpoint's feedback as it arrives.
+//
+// A recent review of identical input is reused instead of calling the model; the
+// returned review then has CachedFromID set.
+func (c *Container) performReview(ctx context.Context, gormWriting *models.GormWriting, onFeedback func(services.FeedbackDetail)) (*models.GormReview, error) {
+	input, err := c.reviewInput(gormWriting)
+	if err != nil {
+		return nil, err
+	}
+	rubric := input.Rubric
+	contentHash := services.ReviewCacheKey(input)
+	// The content may have changed since the writing was created, so check it again.
+	flagInjection(gormWriting)
+
+	cached, err := c.reuseCachedReview(gormWriting, contentHash, rubric)
+	if err != nil {
+		return nil, err
+	}
+	if cached != nil {
+		if onFeedback != nil {
+			replayFeedback(cached, onFeedback)
+		}
+		return cached, nil
+	}
+
+	// Quotas are checked right before the model call, because jobs may wait in the queue.
+	if err := c.checkQuota(gormWriting.UserID); err != nil {
+		return nil, err
+	}
+
+	startedAt := time.Now()
+	var result *services.ReviewResult
+	if onFeedback != nil {
+		result, err = c.Reviewer.StreamAIReview(ctx, input, onFeedback)
+	} else {
+		result, err = c.Reviewer.GetAIReview(ctx, input)
+	}
+	if err != nil {
+		c.recordFailedAIUsage(gormWriting.UserID, models.AIUsageKindReview, err)
+		return nil, fmt.Errorf("failed to get AI review: %w", err)
+	}
+	latency := time.Since(startedAt)
+
+	// Flagged writings cannot score above the cap, whatever the model was talked into.
+	scoreCapped := false
+	if gormWriting.InjectionFlagged {
+		scoreCapped = services.CapScores(result.Review, services.InjectionScoreCap)
+	}
+
+	feedbackJSON, err := json.Marshal(result.Review)
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize AI response: %w", err)
+	}
+	annotations := result.Review.Annotations
+	if annotations == nil {
+		annotations = []services.Annotation{}
+	}
+	annotationsJSON, err := json.Marshal(annotations)
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize annotations: %w", err)
+	}
+	var coverageJSON []byte
+	if len(result.Review.Coverage) > 0 {
+		if coverageJSON, err = json.Marshal(result.Review.Coverage); err != nil {
+			return nil, fmt.Errorf("failed to serialize coverage: %w", err)
+		}
+	}
+
+	review := models.GormReview{
+		WritingID:        gormWriting.ID,
+		UserID:           gormWriting.UserID,
+		ModelName:        result.Model,
+		PromptVersion:    rubric.PromptVersion(),
+		LatencyMs:        latency.Milliseconds(),
+		PromptTokens:     result.Usage.PromptTokens,
+		CompletionTokens: result.Usage.CompletionTokens,
+		TotalTokens:      result.Usage.TotalTokens,
+		TotalScore:       result.Review.TotalScore,
+		ScoreCapped:      scoreCapped,
+		RawResponse:      result.Raw,
+		Feedback:         feedbackJSON,
+		Annotations:      annotationsJSON,
+		Coverage:         coverageJSON,
+		ContentHash:      contentHash,
+	}
+	if rubric.ID != 0 {
+		review.RubricID = &rubric.ID
+	}
+
+	if err := c.saveReview(gormWriting, &review, &result.Usage); err != nil {
+		return nil, err
+	}
+	return &review, nil
+}
+
+// reviewInput builds the reviewer input for a writing with its Theme preloaded.
+func (c *Container) reviewInput(gormWriting *models.GormWriting) (services.ReviewInput, error) {
+	rubric, err := c.resolveRubric(gormWriting.Theme)
+	if err != nil {
+		return services.ReviewInput{}, fmt.Errorf("failed to load rubric: %w", err)
+	}
+	return services.ReviewInput{
+		ThemeTitle:       gormWriting.Theme.Title,
+		ThemeDescription: gormWriting.Theme.Description,
+		Content:          gormWriting.Content,
+		Rubric:           rubric,
+		KeyPoints:        gormWriting.Theme.KeyPoints,
+		Misconceptions:   gormWriting.Theme.Misconceptions,
+	}, nil
+}
+
+// reuseCachedReview looks for a review of the same input by ReviewModel within ReviewCacheTTL,
+// so that switching the reviewer backend or model does not serve results of the old one.
+// On a hit it records a copy of that review for the writing, without charging any tokens,
+// and returns it. It returns nil when there is nothing to reuse.
+//
+// The cache key ignores whitespace differences, so the annotations are aligned
+// with this writing's content again before they are copied.
+func (c *Container) reuseCachedReview(gormWriting *models.GormWriting, contentHash string, rubric services.Rubric) (*models.GormReview, error) {
+	if c.ReviewCacheTTL <= 0 {
+		return nil, nil
+	}
+
+	// Only reviews that actually called the model are reused, so the TTL counts from the original run.
+	var source models.GormReview
+	err := c.DB.Where("content_hash = ? AND model_name = ? AND cached_from_id IS NULL AND created_at >= ?", contentHash, c.ReviewModel, time.Now().Add(-c.ReviewCacheTTL)).
+		Order("created_at desc, id desc").
+		First(&source).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up cached review: %w", err)
+	}
+	feedback, annotations, err := realignCachedAnnotations(source, gormWriting.Content, rubric)
+	if err != nil {
+		return nil, err
+	}
+
+	review := models.GormReview{
+		WritingID:     gormWriting.ID,
+		UserID:        gormWriting.UserID,
+		ModelName:     source.ModelName,
+		RubricID:      source.RubricID,
+		PromptVersion: source.PromptVersion,
+		TotalScore:    source.TotalScore,
+		ScoreCapped:   source.ScoreCapped,
+		RawResponse:   source.RawResponse,
+		Feedback:      feedback,
+		Annotations:   annotations,
+		Coverage:      source.Coverage,
+		ContentHash:   contentHash,
+		CachedFromID:  &source.ID,
+	}
+	if err := c.saveReview(gormWriting, &review, nil); err != nil {
+		return nil, err
+	}
+	return &review, nil
+}
+
+// realignCachedAnnotations returns the feedback and annotations of a cached review with
+// the annotations aligned with content, which may differ from the source's in whitespace.
+func realignCachedAnnotations(source models.GormReview, content string, rubric services.Rubric) (datatypes.JSON, datatypes.JSON, error) {
+	var annotations []services.Annotation
+	if len(source.Annotations) > 0 {
+		if err := json.Unmarshal(source.Annotations, &annotations); err != nil {
+			return nil, nil, fmt.Errorf("failed to decode cached annotations: %w", err)
+		}
+	}
+	annotations = services.RealignAnnotations(annotations, content, rubric)
+	annotationsJSON, err := json.Marshal(annotations)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to serialize annotations: %w", err)
+	}
+
+	// The feedback holds a copy of the annotations too.
+	var review services.AIReviewResponse
+	if err := json.Unmarshal(source.Feedback, &review); err != nil {
+		return nil, nil, fmt.Errorf("failed to decode cached review: %w", err)
+	}
+	review.Annotations = annotations
+	feedbackJSON, err := json.Marshal(review)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to serialize AI response: %w", err)
+	}
+	return feedbackJSON, annotationsJSON, nil
+}
+
+// flagInjection runs the prompt injection detector on a writing's content and records the result on it.
+func flagInjection(gormWriting *models.GormWriting) {
+	report := services.DetectInjection(gormWriting.Content)
+	gormWriting.InjectionFlagged = report.Flagged
+	gormWriting.InjectionSignals = report.Signals
+}
+
+// replayFeedback reports the feedbacks of a stored review to a streaming client.
+func replayFeedback(review *models.GormReview, onFeedback func(services.FeedbackDetail)) {
+	var parsed services.AIReviewResponse
+	if err := json.Unmarshal(review.Feedback, &parsed); err != nil {
+		return
+	}
+	for _, feedback := range parsed.Feedbacks {
+		onFeedback(feedback)
+	}
+}
+
+// saveFallbackReview stores a provisional heuristic review for a writing whose AI review
+// could not be produced. The writing must have its Theme preloaded. The review does not
+// change the writing's AIScore, so leaderboards and statistics only count AI reviews.
+func (c *Container) saveFallbackReview(gormWriting *models.GormWriting) (*models.GormReview, error) {
+	input, err := c.reviewInput(gormWriting)
+	if err != nil {
+		return nil, err
+	}
+	result, err := services.HeuristicReview(input)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build heuristic review: %w", err)
+	}
+	feedbackJSON, err := json.Marshal(result.Review)
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize heuristic review: %w", err)
+	}
+
+	review := models.GormReview{
+		WritingID:     gormWriting.ID,
+		UserID:        gormWriting.UserID,
+		ModelName:     result.Model,
+		PromptVersion: input.Rubric.PromptVersion(),
+		TotalScore:    result.Review.TotalScore,
+		RawResponse:   result.Raw,
+		Feedback:      feedbackJSON,
+		Provisional:   true,
+	}
+	if input.Rubric.ID != 0 {
+		review.RubricID = &input.Rubric.ID
+	}
+	err = c.DB.Transaction(func(tx *gorm.DB) error {
+		if err := ensureRevision(tx, gormWriting); err != nil {
+			return err
+		}
+		review.RevisionID = gormWriting.LatestRevisionID
+		return tx.Create(&review).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to save heuristic review: %w", err)
+	}
+	return &review, nil
+}
+
+// saveReview stores a review run of the writing's latest revision and makes it the
+// writing's latest review. AIScore and AIFeedback mirror the latest review so existing
+// clients keep working. If usage is non-nil, the tokens are charged to the writing's owner.
+func (c *Container) saveReview(gormWriting *models.GormWriting, review *models.GormReview, usage *services.TokenUsage) error {
+	err := c.DB.Transaction(func(tx *gorm.DB) error {
+		if err := ensureRevision(tx, gormWriting); err != nil {
+			return err
+		}
+		review.RevisionID = gormWriting.LatestRevisionID
+		if err := tx.Create(review).Error; err != nil {
+			return err
+		}
+		if usage != nil {
+			if err := recordAIUsage(tx, review.UserID, models.AIUsageKindReview, review.ModelName, *usage, &review.ID); err != nil {
+				return err
+			}
+		}
+
+		score := review.TotalScore
+		gormWriting.AIScore = &score
+		gormWriting.AIFeedback = review.Feedback
+		gormWriting.FeedbackText = feedbackSearchText(review.Feedback)
+		gormWriting.LatestReviewID = &review.ID
+		return tx.Model(gormWriting).Updates(map[string]interface{}{
+			"ai_score":          gormWriting.AIScore,
+			"ai_feedback":       gormWriting.AIFeedback,
+			"feedback_text":     gormWriting.FeedbackText,
+			"latest_review_id":  gormWriting.LatestReviewID,
+			"injection_flagged": gormWriting.InjectionFlagged,
+			"injection_signals": gormWriting.InjectionSignals,
+		}).Error
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save AI review: %w", err)
+	}
+	return nil
+}
+
+// ensureRevision snapshots writings created before revisions were kept, so that
+// their reviews can point at the revision they scored.
+func ensureRevision(tx *gorm.DB, gormWriting *models.GormWriting) error {
+	if gormWriting.LatestRevisionID != nil {
+		return nil
+	}
+	if err := lockWriting(tx, gormWriting); err != nil {
+		return err
+	}
+	// Another review may have snapshotted the writing in the meantime.
+	if gormWriting.LatestRevisionID != nil {
+		return nil
+	}
+	return saveRevision(tx, gormWriting)
+}
@@ -0,0 +1,63 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/ch00z00/kotobalize/models"
+	"github.com/ch00z00/kotobalize/services"
+)
+
+func TestReuseCachedReview(t *testing.T) {
+	c := newTestContainer(t)
+	c.ReviewCacheTTL = time.Hour
+	c.ReviewModel = "gpt-4o"
+	user := createTestUser(t, c.DB, "alice")
+	theme := createTestTheme(t, c.DB, 600)
+	earlier := createTestWriting(t, c.DB, user.ID, theme.ID, "ACIDはトランザクションの性質です。")
+	writing := createTestWriting(t, c.DB, user.ID, theme.ID, "ACIDはトランザクションの性質です。\n")
+	writing.Theme = theme
+
+	input, err := c.reviewInput(&writing)
+	if err != nil {
+		t.Fatal(err)
+	}
+	contentHash := services.ReviewCacheKey(input)
+	result, err := (&services.FakeReviewer{}).GetAIReview(context.Background(), input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	feedback, _ := json.Marshal(result.Review)
+	storeReview := func(modelName string, createdAt time.Time, cachedFromID *uint) models.GormReview {
+		review := models.GormReview{WritingID: earlier.ID, UserID: user.ID, ModelName: modelName, PromptVersion: input.Rubric.PromptVersion(),
+			TotalScore: result.Review.TotalScore, Feedback: feedback, ContentHash: contentHash, CachedFromID: cachedFromID}
+		review.CreatedAt = createdAt
+		mustCreate(t, c.DB, &review)
+		return review
+	}
+
+	// None of these may be reused: another model's result, one past the TTL, and a copy.
+	fake := storeReview(services.FakeModelName, time.Now(), nil)
+	stale := storeReview("gpt-4o", time.Now().Add(-2*time.Hour), nil)
+	storeReview("gpt-4o", time.Now(), &stale.ID)
+	if cached, err := c.reuseCachedReview(&writing, contentHash, input.Rubric); err != nil || cached != nil {
+		t.Fatalf("reuseCachedReview = %v, %v, want no hit", cached, err)
+	}
+
+	// After switching back to the fake reviewer, its result is reused.
+	c.ReviewModel = services.FakeModelName
+	cached, err := c.reuseCachedReview(&writing, contentHash, input.Rubric)
+	if err != nil || cached == nil {
+		t.Fatalf("reuseCachedReview = %v, %v, want a hit", cached, err)
+	}
+	if cached.CachedFromID == nil || *cached.CachedFromID != fake.ID || cached.WritingID != writing.ID {
+		t.Errorf("copy of review %v for writing %d, want a copy of %d for writing %d", cached.CachedFromID, cached.WritingID, fake.ID, writing.ID)
+	}
+
+	c.ReviewCacheTTL = 0
+	if cached, err := c.reuseCachedReview(&writing, contentHash, input.Rubric); err != nil || cached != nil {
+		t.Errorf("reuseCachedReview with the cache disabled = %v, %v, want no hit", cached, err)
+	}
+}
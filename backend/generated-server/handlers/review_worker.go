@@ -0,0 +1,287 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/ch00z00/kotobalize/models"
+	"github.com/ch00z00/kotobalize/services"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultReviewWorkers        = 4
+	defaultReviewJobMaxAttempts = 3
+	// reviewJobQueueSize bounds the in-memory queue. Jobs that do not fit stay
+	// queued in the database and are picked up by the periodic sweep.
+	reviewJobQueueSize = 256
+	// reviewJobTimeout limits a single attempt, including the AI call. The AI call is not
+	// retried within an attempt, since the job is retried instead.
+	reviewJobTimeout = 2 * time.Minute
+	// reviewJobSweepInterval controls how often stranded queued jobs are re-enqueued.
+	reviewJobSweepInterval = 30 * time.Second
+	// reviewJobHeartbeatInterval is how often a worker renews the lease of the job it runs.
+	reviewJobHeartbeatInterval = 15 * time.Second
+	// reviewJobLeaseTimeout is how old the heartbeat of a running job may get before the
+	// job is considered abandoned and queued again.
+	reviewJobLeaseTimeout = 4 * reviewJobHeartbeatInterval
+)
+
+// ReviewWorkerPool processes persisted review jobs with a bounded number of workers.
+type ReviewWorkerPool struct {
+	container   *Container
+	jobs        chan uint
+	workers     int
+	maxAttempts int
+}
+
+// newReviewWorkerPool creates a pool configured by REVIEW_WORKERS and REVIEW_JOB_MAX_ATTEMPTS.
+func newReviewWorkerPool(c *Container) *ReviewWorkerPool {
+	return &ReviewWorkerPool{
+		container:   c,
+		jobs:        make(chan uint, reviewJobQueueSize),
+		workers:     envInt("REVIEW_WORKERS", defaultReviewWorkers),
+		maxAttempts: envInt("REVIEW_JOB_MAX_ATTEMPTS", defaultReviewJobMaxAttempts),
+	}
+}
+
+// StartReviewWorkers starts the review worker pool. It must be called once,
+// after the database has been migrated. Several instances may share the job table:
+// jobs are claimed atomically, and only jobs whose lease has expired are taken over.
+func (c *Container) StartReviewWorkers() {
+	pool := newReviewWorkerPool(c)
+	c.ReviewWorkers = pool
+
+	for i := 0; i < pool.workers; i++ {
+		go pool.run()
+	}
+	go pool.sweep()
+
+	log.Printf("Started %d review workers", pool.workers)
+}
+
+// Enqueue schedules a job for processing without blocking.
+// If the in-memory queue is full, the job is left for the next sweep.
+func (p *ReviewWorkerPool) Enqueue(jobID uint) {
+	select {
+	case p.jobs <- jobID:
+	default:
+		log.Printf("review job queue is full, job %d will be picked up by the next sweep", jobID)
+	}
+}
+
+// run processes jobs from the queue until the process exits.
+func (p *ReviewWorkerPool) run() {
+	for jobID := range p.jobs {
+		p.process(jobID)
+	}
+}
+
+// sweep periodically queues jobs abandoned by a stopped worker again, and re-enqueues
+// jobs that are queued in the database and due. Enqueueing a job twice is harmless
+// because workers claim jobs atomically.
+func (p *ReviewWorkerPool) sweep() {
+	ticker := time.NewTicker(reviewJobSweepInterval)
+	defer ticker.Stop()
+
+	for ; ; <-ticker.C {
+		now := time.Now()
+		// Jobs running before heartbeats were recorded only have a start time.
+		if err := p.container.DB.Model(&models.GormReviewJob{}).
+			Where("status = ? AND (COALESCE(heartbeat_at, started_at) IS NULL OR COALESCE(heartbeat_at, started_at) < ?)",
+				models.ReviewJobStatusRunning, now.Add(-reviewJobLeaseTimeout)).
+			Update("status", models.ReviewJobStatusQueued).Error; err != nil {
+			log.Printf("failed to requeue abandoned review jobs: %v", err)
+		}
+
+		var jobIDs []uint
+		if err := p.container.DB.Model(&models.GormReviewJob{}).
+			Where("status = ? AND (next_run_at IS NULL OR next_run_at <= ?)", models.ReviewJobStatusQueued, now).
+			Order("id asc").
+			Limit(reviewJobQueueSize).
+			Pluck("id", &jobIDs).Error; err != nil {
+			log.Printf("failed to sweep review jobs: %v", err)
+			continue
+		}
+		for _, id := range jobIDs {
+			p.Enqueue(id)
+		}
+	}
+}
+
+// process claims a queued job that is due, runs the review, and records the outcome.
+func (p *ReviewWorkerPool) process(jobID uint) {
+	db := p.container.DB
+	now := time.Now()
+
+	// Claim the job. If another worker already took it, or its retry is not due yet,
+	// RowsAffected is 0.
+	result := db.Model(&models.GormReviewJob{}).
+		Where("id = ? AND status = ? AND (next_run_at IS NULL OR next_run_at <= ?)", jobID, models.ReviewJobStatusQueued, now).
+		Updates(map[string]interface{}{
+			"status":       models.ReviewJobStatusRunning,
+			"attempts":     gorm.Expr("attempts + 1"),
+			"started_at":   now,
+			"heartbeat_at": now,
+			"next_run_at":  nil,
+		})
+	if result.Error != nil {
+		log.Printf("failed to claim review job %d: %v", jobID, result.Error)
+		return
+	}
+	if result.RowsAffected == 0 {
+		return
+	}
+
+	var job models.GormReviewJob
+	if err := db.First(&job, jobID).Error; err != nil {
+		log.Printf("failed to load review job %d: %v", jobID, err)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(services.WithoutRetries(context.Background()), reviewJobTimeout)
+	defer cancel()
+	stopHeartbeat := p.keepLease(job)
+	defer stopHeartbeat()
+
+	// Updates of the outcome only apply while this attempt still holds the job.
+	owned := db.Model(&job).Where("status = ? AND attempts = ?", models.ReviewJobStatusRunning, job.Attempts)
+
+	err := p.runJob(ctx, job)
+	if err == nil {
+		finishedAt := time.Now()
+		if err := owned.Updates(map[string]interface{}{
+			"status":          models.ReviewJobStatusSucceeded,
+			"last_error":      nil,
+			"last_error_code": nil,
+			"finished_at":     finishedAt,
+		}).Error; err != nil {
+			log.Printf("failed to mark review job %d as succeeded: %v", jobID, err)
+		}
+		return
+	}
+
+	log.Printf("review job %d attempt %d failed: %v", jobID, job.Attempts, err)
+	message := err.Error()
+	code := jobErrorCode(err)
+
+	// Retry with exponential backoff unless the error is permanent or attempts are exhausted.
+	var permanent *permanentJobError
+	retryable := !errors.As(err, &permanent) && !errors.Is(err, errQuotaExceeded)
+	if retryable && job.Attempts < p.maxAttempts {
+		// The retry time is stored so that the sweep and other instances respect the backoff
+		// and a restart does not lose it. The timer only enqueues the job as soon as it is due.
+		backoff := time.Duration(1<<uint(job.Attempts-1)) * 5 * time.Second
+		if err := owned.Updates(map[string]interface{}{
+			"status":          models.ReviewJobStatusQueued,
+			"last_error":      message,
+			"last_error_code": code,
+			"next_run_at":     time.Now().Add(backoff),
+		}).Error; err != nil {
+			log.Printf("failed to requeue review job %d: %v", jobID, err)
+			return
+		}
+		time.AfterFunc(backoff, func() { p.Enqueue(jobID) })
+		return
+	}
+
+	updates := map[string]interface{}{
+		"status":          models.ReviewJobStatusFailed,
+		"last_error":      message,
+		"last_error_code": code,
+		"finished_at":     time.Now(),
+	}
+	// Leave the user with a provisional review rather than nothing.
+	if p.container.HeuristicFallback && !errors.As(err, &permanent) {
+		if reviewID := p.saveFallbackReview(job); reviewID != nil {
+			updates["fallback_review_id"] = *reviewID
+		}
+	}
+	if err := owned.Updates(updates).Error; err != nil {
+		log.Printf("failed to mark review job %d as failed: %v", jobID, err)
+	}
+}
+
+// keepLease renews the heartbeat of a running job until the returned function is called.
+func (p *ReviewWorkerPool) keepLease(job models.GormReviewJob) func() {
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(reviewJobHeartbeatInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case now := <-ticker.C:
+				if err := p.container.DB.Model(&models.GormReviewJob{}).
+					Where("id = ? AND status = ? AND attempts = ?", job.ID, models.ReviewJobStatusRunning, job.Attempts).
+					Update("heartbeat_at", now).Error; err != nil {
+					log.Printf("failed to renew the lease of review job %d: %v", job.ID, err)
+				}
+			}
+		}
+	}()
+	return func() { close(done) }
+}
+
+// saveFallbackReview stores a heuristic review for a failed job and returns its ID, or nil on error.
+func (p *ReviewWorkerPool) saveFallbackReview(job models.GormReviewJob) *uint {
+	var gormWriting models.GormWriting
+	if err := p.container.DB.Preload("Theme").First(&gormWriting, job.WritingID).Error; err != nil {
+		log.Printf("failed to load writing %d for fallback review: %v", job.WritingID, err)
+		return nil
+	}
+	review, err := p.container.saveFallbackReview(&gormWriting)
+	if err != nil {
+		// Rubrics without the structure and vocabulary viewpoints get no fallback review.
+		if !errors.Is(err, services.ErrNoHeuristicViewpoints) {
+			log.Printf("failed to save fallback review for job %d: %v", job.ID, err)
+		}
+		return nil
+	}
+	return &review.ID
+}
+
+// runJob loads the writing for a job and reviews it.
+func (p *ReviewWorkerPool) runJob(ctx context.Context, job models.GormReviewJob) error {
+	var gormWriting models.GormWriting
+	if err := p.container.DB.Preload("Theme").First(&gormWriting, job.WritingID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return &permanentJobError{code: "WRITING_NOT_FOUND", err: fmt.Errorf("writing %d no longer exists", job.WritingID)}
+		}
+		return err
+	}
+	review, err := p.container.performReview(ctx, &gormWriting, nil)
+	if err != nil {
+		return err
+	}
+	if review.CachedFromID != nil {
+		if err := p.container.DB.Model(&job).Update("cached", true).Error; err != nil {
+			log.Printf("failed to mark review job %d as cached: %v", job.ID, err)
+		}
+	}
+	return nil
+}
+
+// permanentJobError marks a job failure that retrying cannot fix.
+type permanentJobError struct {
+	code string
+	err  error
+}
+
+func (e *permanentJobError) Error() string { return e.err.Error() }
+
+func (e *permanentJobError) Unwrap() error { return e.err }
+
+// jobErrorCode returns the API error code recorded for a failed job attempt.
+func jobErrorCode(err error) string {
+	var permanent *permanentJobError
+	if errors.As(err, &permanent) {
+		return permanent.code
+	}
+	_, apiErr := aiErrorResponse(err)
+	return apiErr.Code
+}
@@ -0,0 +1,134 @@
+package handlers
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/ch00z00/kotobalize/models"
+	"github.com/ch00z00/kotobalize/services"
+	"github.com/gin-gonic/gin"
+	sqlitedriver "github.com/glebarez/go-sqlite"
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+
+	// CHAR_LENGTH, used by the excerpt views, is MySQL's name for SQLite's length.
+	sqlitedriver.MustRegisterDeterministicScalarFunction("CHAR_LENGTH", 1, func(_ *sqlitedriver.FunctionContext, args []driver.Value) (driver.Value, error) {
+		return int64(utf8.RuneCountInString(args[0].(string))), nil
+	})
+}
+
+// newTestContainer returns a container backed by a new SQLite database with every model
+// migrated. It reviews with FakeReviewer and has no AI quota.
+func newTestContainer(t *testing.T) *Container {
+	t.Helper()
+	dsn := filepath.Join(t.TempDir(), "test.db") + "?_pragma=busy_timeout(5000)"
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{TranslateError: true, Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatalf("open database: %v", err)
+	}
+	err = db.AutoMigrate(&models.GormUser{}, &models.GormWriting{}, &models.GormTheme{}, &models.UserFavoriteTheme{}, &models.GormReviewJob{}, &models.GormRubric{}, &models.GormReview{}, &models.GormAIUsage{}, &models.GormRewrite{}, &models.GormInterview{}, &models.GormInterviewTurn{}, &models.GormPeerReview{}, &models.GormMentorship{}, &models.GormMentorComment{}, &models.GormWritingRevision{}, &models.GormWritingSession{})
+	if err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+	return &Container{DB: db, Reviewer: &services.FakeReviewer{}}
+}
+
+// mustCreate inserts value or fails the test.
+func mustCreate(t *testing.T, db *gorm.DB, value any) {
+	t.Helper()
+	if err := db.Create(value).Error; err != nil {
+		t.Fatalf("create %T: %v", value, err)
+	}
+}
+
+// createTestUser inserts a user named name.
+func createTestUser(t *testing.T, db *gorm.DB, name string) models.GormUser {
+	t.Helper()
+	user := models.GormUser{Name: &name, Email: name + "@example.com", Password: "x"}
+	mustCreate(t, db, &user)
+	return user
+}
+
+// createTestTheme inserts an official theme with the time limit.
+func createTestTheme(t *testing.T, db *gorm.DB, timeLimitSeconds int) models.GormTheme {
+	t.Helper()
+	theme := models.GormTheme{Title: "ACIDとは", Description: "説明してください", Category: "database", TimeLimitInSeconds: timeLimitSeconds}
+	mustCreate(t, db, &theme)
+	return theme
+}
+
+// createTestWriting inserts a submitted writing of the user on the theme.
+func createTestWriting(t *testing.T, db *gorm.DB, userID, themeID uint, content string) models.GormWriting {
+	t.Helper()
+	writing := models.GormWriting{UserID: userID, ThemeID: themeID, Content: content, Status: models.WritingStatusSubmitted}
+	mustCreate(t, db, &writing)
+	return writing
+}
+
+// serve calls handler for a request to target as the user, routing it with route so
+// path parameters are set. body is encoded as JSON unless it is nil.
+func serve(t *testing.T, handler gin.HandlerFunc, method, route, target string, userID uint, body any) *httptest.ResponseRecorder {
+	t.Helper()
+	var reader *bytes.Reader
+	if body == nil {
+		reader = bytes.NewReader(nil)
+	} else {
+		encoded, err := json.Marshal(body)
+		if err != nil {
+			t.Fatalf("encode body: %v", err)
+		}
+		reader = bytes.NewReader(encoded)
+	}
+	router := gin.New()
+	router.Handle(method, route, func(ctx *gin.Context) {
+		ctx.Set("userId", userID)
+	}, handler)
+	recorder := httptest.NewRecorder()
+	req := httptest.NewRequest(method, target, reader)
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(recorder, req)
+	return recorder
+}
+
+// decodeResponse decodes the JSON body of a response, failing the test unless it has the status.
+func decodeResponse[T any](t *testing.T, recorder *httptest.ResponseRecorder, status int) T {
+	t.Helper()
+	var value T
+	if recorder.Code != status {
+		t.Fatalf("status = %d, want %d: %s", recorder.Code, status, recorder.Body)
+	}
+	if err := json.Unmarshal(recorder.Body.Bytes(), &value); err != nil {
+		t.Fatalf("decode %s: %v", recorder.Body, err)
+	}
+	return value
+}
+
+// expectError fails the test unless the response is an API error with the status and code.
+func expectError(t *testing.T, recorder *httptest.ResponseRecorder, status int, code string) {
+	t.Helper()
+	apiErr := decodeResponse[models.APIError](t, recorder, status)
+	if apiErr.Code != code {
+		t.Fatalf("error code = %q, want %q", apiErr.Code, code)
+	}
+}
+
+// writingPath is the path of a writing endpoint.
+func writingPath(writingID uint, suffix string) string {
+	return fmt.Sprintf("/writings/%d%s", writingID, suffix)
+}
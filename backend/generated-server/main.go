@@ -26,6 +26,11 @@ import (
 func main() {
 	log.Println("Kotobalize backend server starting...")
 
+	// eval サブコマンドはサーバーを起動せず、ゴールデンセットでレビュアーを評価して終了します。
+	if len(os.Args) > 1 && os.Args[1] == "eval" {
+		os.Exit(runEval(os.Args[2:]))
+	}
+
 	// --reset-db フラグを定義します。このフラグが指定されると、DBがリセットされます。
 	resetDB := flag.Bool("reset-db", false, "Reset the database by dropping all tables before migrating.")
 	flag.Parse()
@@ -115,6 +120,18 @@ func setupRoutes(router *gin.Engine, c *handlers.Container, resetDB bool) {
 			&models.GormTheme{},
 			&models.GormWriting{},
 			&models.UserFavoriteTheme{}, // 新しいお気に入りモデルも対象に含めます
+			&models.GormReviewJob{},
+			&models.GormRubric{},
+			&models.GormReview{},
+			&models.GormAIUsage{},
+			&models.GormRewrite{},
+			&models.GormInterview{},
+			&models.GormInterviewTurn{},
+			&models.GormPeerReview{},
+			&models.GormMentorship{},
+			&models.GormMentorComment{},
+			&models.GormWritingRevision{},
+			&models.GormWritingSession{},
 		)
 		if err != nil {
 			log.Printf("failed to drop tables: %v", err)
@@ -126,20 +143,29 @@ func setupRoutes(router *gin.Engine, c *handlers.Container, resetDB bool) {
 	// データベースのマイグレーションを実行します。
 	// 必要なテーブルやカラムが自動で作成されます。
 	log.Println("Running database migrations...")
-	if err := c.DB.AutoMigrate(&models.GormUser{}, &models.GormTheme{}, &models.GormWriting{}, &models.UserFavoriteTheme{}); err != nil {
+	if err := c.DB.AutoMigrate(&models.GormUser{}, &models.GormTheme{}, &models.GormWriting{}, &models.UserFavoriteTheme{}, &models.GormReviewJob{}, &models.GormRubric{}, &models.GormReview{}, &models.GormAIUsage{}, &models.GormRewrite{}, &models.GormInterview{}, &models.GormInterviewTurn{}, &models.GormPeerReview{}, &models.GormMentorship{}, &models.GormMentorComment{}, &models.GormWritingRevision{}, &models.GormWritingSession{}); err != nil {
+		log.Printf("failed to migrate database: %v", err)
+		return
+	}
+	if err := handlers.MigrateSearchIndexes(c.DB); err != nil {
 		log.Printf("failed to migrate database: %v", err)
 		return
 	}
 
 	// Seed the database with initial data
+	seeder.SeedRubrics(c.DB)
 	seeder.SeedThemes(c.DB)
 
+	// Start the background workers that process queued AI reviews
+	c.StartReviewWorkers()
+
 	// Update health check to show full readiness
 	router.GET("/ready", func(ctx *gin.Context) {
 		ctx.JSON(200, gin.H{
 			"status": "ready",
 			"message": "Application is fully initialized and ready",
 			"database": "connected",
+			"aiReviewer": c.ReviewerCircuitState(),
 			"timestamp": time.Now().UTC().Format(time.RFC3339),
 		})
 	})
@@ -164,6 +190,8 @@ func setupRoutes(router *gin.Engine, c *handlers.Container, resetDB bool) {
 	
 	// Allow necessary headers for authentication and content type
 	config.AllowHeaders = []string{"Origin", "Content-Type", "Accept", "Authorization"}
+	// Let clients read the cursor of the next page of paginated lists
+	config.ExposeHeaders = []string{"X-Next-Cursor"}
 	// Allow all standard HTTP methods
 	config.AllowMethods = []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"}
 	// Allow credentials (cookies, authorization headers, etc.)
@@ -192,6 +220,7 @@ func setupRoutes(router *gin.Engine, c *handlers.Container, resetDB bool) {
 			protected.PUT("/users/me/password", c.UpdateUserPassword)
 			protected.POST("/users/me/avatar/upload-url", c.GetAvatarUploadURL)
 			protected.GET("/users/me/activity", c.GetUserActivity)
+			protected.GET("/users/me/usage", c.GetUserUsage)
 
 			protected.GET("/themes", c.ListThemes)
 			protected.GET("/themes/:themeId", c.GetThemeByID)
@@ -200,12 +229,47 @@ func setupRoutes(router *gin.Engine, c *handlers.Container, resetDB bool) {
 			protected.DELETE("/themes/:themeId", c.DeleteTheme)
 			protected.POST("/themes/:themeId/favorite", c.FavoriteTheme)
 			protected.DELETE("/themes/:themeId/favorite", c.UnfavoriteTheme)
+			protected.GET("/themes/:themeId/attempts", c.ListThemeAttempts)
+			protected.GET("/themes/:themeId/attempts/compare", c.CompareThemeAttempts)
+			protected.POST("/themes/:themeId/sessions", c.StartWritingSession)
+
+			protected.GET("/rubrics", c.ListRubrics)
+			protected.GET("/rubrics/:rubricId", c.GetRubricByID)
+			protected.POST("/rubrics", c.CreateRubric)
 
 			protected.GET("/writings", c.ListUserWritings)
 			protected.POST("/writings", c.CreateWriting)
 			protected.GET("/writings/:writingId", c.GetWritingByID)
+			protected.PATCH("/writings/:writingId", c.AutosaveWriting)
+			protected.PUT("/writings/:writingId", c.UpdateWriting)
+			protected.DELETE("/writings/:writingId", c.DeleteWriting)
+			protected.GET("/writings/trash", c.ListDeletedWritings)
+			protected.GET("/writings/search", c.SearchWritings)
+			protected.POST("/writings/:writingId/restore", c.RestoreWriting)
+			protected.GET("/writings/:writingId/reviews", c.ListWritingReviews)
+			protected.GET("/writings/:writingId/revisions", c.ListWritingRevisions)
+			protected.GET("/writings/:writingId/revisions/diff", c.DiffWritingRevisions)
+			protected.GET("/writings/:writingId/rewrite", c.GetWritingRewrite)
+			protected.POST("/writings/:writingId/rewrite", c.RewriteWriting)
+			protected.GET("/writings/:writingId/interview", c.GetInterview)
+			protected.POST("/writings/:writingId/interview", c.StartInterview)
+			protected.POST("/writings/:writingId/interview/answers", c.AnswerInterview)
+			protected.POST("/writings/:writingId/interview/finish", c.FinishInterview)
+			protected.PUT("/writings/:writingId/peer-review", c.UpdatePeerReviewSettings)
+			protected.GET("/writings/:writingId/peer-reviews", c.ListPeerReviews)
+			protected.POST("/writings/:writingId/peer-reviews", c.CreatePeerReview)
+			protected.GET("/peer-review/writings", c.ListPeerReviewWritings)
+			protected.POST("/mentorships", c.InviteMentee)
+			protected.GET("/mentorships", c.ListMentorships)
+			protected.POST("/mentorships/:mentorshipId/accept", c.AcceptMentorship)
+			protected.POST("/mentorships/:mentorshipId/decline", c.DeclineMentorship)
+			protected.DELETE("/mentorships/:mentorshipId", c.EndMentorship)
+			protected.GET("/writings/:writingId/mentor-comments", c.ListMentorComments)
+			protected.POST("/writings/:writingId/mentor-comments", c.CreateMentorComment)
 
 			protected.POST("/review", c.ReviewWriting)
+			protected.POST("/review/stream", c.StreamReviewWriting)
+			protected.GET("/review-jobs/:jobId", c.GetReviewJob)
 		}
 	}
 
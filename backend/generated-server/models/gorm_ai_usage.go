@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+// AI usage kinds recorded in GormAIUsage.
+const (
+	AIUsageKindReview    = "review"
+	AIUsageKindRewrite   = "rewrite"
+	AIUsageKindInterview = "interview"
+	AIUsageKindCompare   = "compare"
+)
+
+// GormAIUsage records the tokens consumed by one AI request made on behalf of a user.
+// Quotas are enforced by aggregating these rows.
+type GormAIUsage struct {
+	ID               uint      `gorm:"primarykey"`
+	UserID           uint      `gorm:"not null;index:idx_ai_usage_user_created"`
+	Kind             string    `gorm:"size:30;not null"`
+	ModelName        string    `gorm:"size:100;not null"`
+	ReviewID         *uint     // Set when the request produced a GormReview
+	PromptTokens     int       `gorm:"not null;default:0"`
+	CompletionTokens int       `gorm:"not null;default:0"`
+	TotalTokens      int       `gorm:"not null;default:0"`
+	CreatedAt        time.Time `gorm:"index:idx_ai_usage_user_created"`
+}
@@ -0,0 +1,45 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// Interview statuses.
+const (
+	InterviewStatusActive   = "active"
+	InterviewStatusFinished = "finished"
+)
+
+// GormInterview represents a mock interview about a writing.
+// The AI asks follow-up questions one at a time and scores the whole exchange at the end.
+type GormInterview struct {
+	gorm.Model
+	WritingID    uint                `gorm:"not null;index"`
+	UserID       uint                `gorm:"not null;index"`
+	Status       string              `gorm:"size:20;not null"`
+	MaxQuestions int                 `gorm:"not null"`
+	Turns        []GormInterviewTurn `gorm:"foreignKey:InterviewID"`
+	// Wrap-up review, set when the interview is finished.
+	ModelName     *string `gorm:"size:100"`
+	PromptVersion *string `gorm:"size:100"`
+	TotalScore    *int
+	// ScoreCapped is true when the scores were lowered because the writing or an answer was flagged for prompt injection.
+	ScoreCapped bool           `gorm:"not null;default:false"`
+	Feedback    datatypes.JSON // services.AIReviewResponse
+	RawResponse *string        `gorm:"type:mediumtext"`
+	FinishedAt  *time.Time
+}
+
+// GormInterviewTurn is one interviewer question and the user's answer.
+type GormInterviewTurn struct {
+	gorm.Model
+	InterviewID uint    `gorm:"not null;uniqueIndex:idx_interview_turn_seq"`
+	Seq         int     `gorm:"not null;uniqueIndex:idx_interview_turn_seq"` // 1-based position in the interview
+	Question    string  `gorm:"type:text;not null"`
+	ModelName   string  `gorm:"size:100;not null"` // Model that asked the question
+	Answer      *string `gorm:"type:text"`         // nil while the question is open
+	AnsweredAt  *time.Time
+}
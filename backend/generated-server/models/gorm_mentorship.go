@@ -0,0 +1,45 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// Mentorship statuses.
+const (
+	MentorshipStatusPending  = "pending"
+	MentorshipStatusAccepted = "accepted"
+	MentorshipStatusDeclined = "declined"
+	MentorshipStatusEnded    = "ended"
+)
+
+// GormMentorship is a mentor/mentee relationship. The mentor invites the mentee, and only
+// once the mentee accepts can the mentor read their writings and comment on them.
+// A pair has a single row, which is reused when the mentor invites the mentee again.
+type GormMentorship struct {
+	gorm.Model
+	MentorID uint     `gorm:"not null;uniqueIndex:idx_mentorship_pair"`
+	Mentor   GormUser `gorm:"foreignKey:MentorID"`
+	// MenteeID is nil while no account has the invited email; it is set when one signs up.
+	MenteeID *uint    `gorm:"uniqueIndex:idx_mentorship_pair;index"`
+	Mentee   GormUser `gorm:"foreignKey:MenteeID"`
+	// MenteeEmail is the email the mentee was invited with.
+	MenteeEmail string `gorm:"size:255;not null;default:'';index"`
+	Status      string `gorm:"size:20;not null;index"`
+	AcceptedAt  *time.Time
+}
+
+// GormMentorComment is a mentor's comment on a mentee's writing, optionally overriding its scores.
+type GormMentorComment struct {
+	gorm.Model
+	WritingID uint     `gorm:"not null;index"`
+	MentorID  uint     `gorm:"not null;index"`
+	Mentor    GormUser `gorm:"foreignKey:MentorID"`
+	Body      string   `gorm:"type:text;not null"`
+	// Score overrides the writing's total score when set.
+	Score *int
+	// ViewpointScores overrides the AI's score of some viewpoints, keyed by viewpoint key.
+	ViewpointScores datatypes.JSONType[map[string]int]
+}
@@ -0,0 +1,19 @@
+package models
+
+import (
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// GormPeerReview is a review of a writing by another user, on the same rubric as the AI review.
+// Each user can review a writing once.
+type GormPeerReview struct {
+	gorm.Model
+	WritingID     uint           `gorm:"not null;uniqueIndex:idx_peer_review_writing_reviewer"`
+	ReviewerID    uint           `gorm:"not null;uniqueIndex:idx_peer_review_writing_reviewer;index"`
+	Reviewer      GormUser       `gorm:"foreignKey:ReviewerID"`
+	RubricID      *uint          // nil when the built-in default rubric was used
+	RubricVersion string         `gorm:"size:100;not null"` // e.g. "default@v1"
+	TotalScore    int            `gorm:"not null"`
+	Feedback      datatypes.JSON // services.AIReviewResponse without annotations or coverage
+}
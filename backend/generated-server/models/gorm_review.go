@@ -0,0 +1,39 @@
+package models
+
+import (
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// GormReview represents one AI review run of a writing.
+// Every run is kept so that earlier reviews and their provenance are not lost.
+type GormReview struct {
+	gorm.Model
+	WritingID        uint   `gorm:"not null;index"`
+	UserID           uint   `gorm:"not null;index"`
+	ModelName        string `gorm:"size:100;not null"` // e.g. "gpt-4o-2024-08-06"
+	RubricID         *uint  // nil when the built-in default rubric was used
+	PromptVersion    string `gorm:"size:100;not null"` // e.g. "default@v1/p2"
+	LatencyMs        int64  `gorm:"not null;default:0"`
+	PromptTokens     int    `gorm:"not null;default:0"`
+	CompletionTokens int    `gorm:"not null;default:0"`
+	TotalTokens      int    `gorm:"not null;default:0"`
+	TotalScore       int    `gorm:"not null"`
+	// ScoreCapped is true when the scores were lowered because the writing was flagged for prompt injection.
+	ScoreCapped bool `gorm:"not null;default:false"`
+	// Provisional is true for heuristic reviews saved when the AI reviewer was unavailable.
+	// They are kept in the history but do not change the writing's AIScore.
+	Provisional bool           `gorm:"not null;default:false"`
+	Coverage    datatypes.JSON // []services.KeyPointCoverage; nil when the theme has no key points
+	RawResponse string         `gorm:"type:mediumtext"` // Unparsed model output
+	Feedback    datatypes.JSON // Parsed review (services.AIReviewResponse)
+	Annotations datatypes.JSON // []services.Annotation with offsets into the reviewed content
+	// ContentHash is services.ReviewCacheKey of the reviewed input.
+	ContentHash string `gorm:"size:64;index"`
+	// CachedFromID points at the review this one was copied from, when the
+	// result was reused from the cache instead of calling the model.
+	CachedFromID *uint
+	// RevisionID is the revision of the writing that was reviewed. It is nil for
+	// reviews recorded before revisions were kept.
+	RevisionID *uint `gorm:"index"`
+}
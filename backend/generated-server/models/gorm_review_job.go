@@ -0,0 +1,39 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Review job statuses.
+const (
+	ReviewJobStatusQueued    = "queued"
+	ReviewJobStatusRunning   = "running"
+	ReviewJobStatusSucceeded = "succeeded"
+	ReviewJobStatusFailed    = "failed"
+)
+
+// GormReviewJob represents an asynchronous AI review request for a writing.
+// Jobs are persisted so that queued work survives a server restart.
+type GormReviewJob struct {
+	gorm.Model
+	WritingID uint    `gorm:"not null;index"`
+	UserID    uint    `gorm:"not null;index"`
+	Status    string  `gorm:"size:20;not null;index"`
+	Attempts  int     `gorm:"not null;default:0"`
+	LastError *string `gorm:"type:text"`
+	// LastErrorCode is the APIError code matching LastError, e.g. AI_RESPONSE_INVALID.
+	LastErrorCode *string `gorm:"size:50"`
+	// Cached is true when the job was answered from the review cache.
+	Cached bool `gorm:"not null;default:false"`
+	// FallbackReviewID is the provisional heuristic review saved when the job failed.
+	FallbackReviewID *uint
+	StartedAt        *time.Time
+	// HeartbeatAt is renewed by the worker running the job. A running job whose heartbeat is
+	// stale was abandoned, e.g. by a crashed instance, and is queued again.
+	HeartbeatAt *time.Time
+	// NextRunAt delays a retry until its backoff has passed. Nil means the job is due.
+	NextRunAt  *time.Time `gorm:"index"`
+	FinishedAt *time.Time
+}
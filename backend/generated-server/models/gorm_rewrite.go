@@ -0,0 +1,25 @@
+package models
+
+import (
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// GormRewrite represents an AI-generated improved version of a writing.
+// The original content and the diff are stored so the rewrite can be shown
+// again without another model call, even after the writing changes.
+type GormRewrite struct {
+	gorm.Model
+	WritingID        uint           `gorm:"not null;index"`
+	UserID           uint           `gorm:"not null;index"`
+	ReviewID         *uint          // Review whose feedback the rewrite addresses; nil if none
+	ModelName        string         `gorm:"size:100;not null"`
+	LatencyMs        int64          `gorm:"not null;default:0"`
+	PromptTokens     int            `gorm:"not null;default:0"`
+	CompletionTokens int            `gorm:"not null;default:0"`
+	TotalTokens      int            `gorm:"not null;default:0"`
+	OriginalContent  string         `gorm:"type:text;not null"`
+	Content          string         `gorm:"type:text;not null"`
+	RawResponse      string         `gorm:"type:mediumtext"` // Unparsed model output
+	Diff             datatypes.JSON // []services.DiffSegment from OriginalContent to Content
+}
@@ -0,0 +1,27 @@
+package models
+
+import (
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// RubricViewpoint is one evaluation viewpoint stored in a rubric.
+type RubricViewpoint struct {
+	Key         string  `json:"key"`
+	Name        string  `json:"name"`
+	Description string  `json:"description"`
+	Weight      float64 `json:"weight"`
+}
+
+// GormRubric represents an evaluation rubric in the database.
+// A rubric is immutable once created; editing it creates a new version with the same slug.
+type GormRubric struct {
+	gorm.Model
+	Slug        string                               `gorm:"size:100;not null;uniqueIndex:idx_rubric_slug_version"`
+	Version     int                                  `gorm:"not null;uniqueIndex:idx_rubric_slug_version"`
+	Name        string                               `gorm:"size:255;not null"`
+	Description string                               `gorm:"type:text"`
+	Viewpoints  datatypes.JSONSlice[RubricViewpoint] `gorm:"not null"`
+	IsDefault   bool                                 `gorm:"not null;default:false"` // Used for themes without a rubric.
+	CreatorID   *uint                                // FK to the users table. nil for official rubrics.
+}
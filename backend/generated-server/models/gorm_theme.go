@@ -1,6 +1,7 @@
 package models
 
 import (
+	"gorm.io/datatypes"
 	"gorm.io/gorm"
 )
 
@@ -12,5 +13,10 @@ type GormTheme struct {
 	Category           string `gorm:"size:100;not null"`
 	TimeLimitInSeconds int    `gorm:"not null"`
 	CreatorID          *uint  // FK to the users table. nil for official themes.
+	RubricID           *uint  // FK to the rubrics table. nil uses the default rubric.
 	FavoritesCount     int    `gorm:"not null;default:0"`
+	// KeyPoints lists what a complete answer should cover, e.g. the four ACID properties.
+	KeyPoints datatypes.JSONSlice[string]
+	// Misconceptions lists common wrong statements the reviewer should watch for.
+	Misconceptions datatypes.JSONSlice[string]
 }
@@ -1,18 +1,44 @@
 package models
 
 import (
+	"time"
+
 	"gorm.io/datatypes"
 	"gorm.io/gorm"
 )
 
+// Statuses of a writing. Drafts are autosaved while the user writes and can only be
+// reviewed after they are submitted.
+const (
+	WritingStatusDraft     = "draft"
+	WritingStatusSubmitted = "submitted"
+)
+
 // GormWriting represents the writing model for database operations.
 type GormWriting struct {
 	gorm.Model
-	UserID          uint
-	ThemeID         uint
-	Theme           GormTheme `gorm:"foreignKey:ThemeID"`
-	Content         string
-	DurationSeconds int
-	AIScore         *int
-	AIFeedback      datatypes.JSON // JSON形式でフィードバック全体を保存
+	UserID           uint
+	ThemeID          uint
+	Theme            GormTheme `gorm:"foreignKey:ThemeID"`
+	Content          string
+	DurationSeconds  int
+	Status           string     `gorm:"size:20;not null;default:submitted;index"`
+	LastSavedAt      *time.Time // 下書きが最後に自動保存された日時
+	SessionID        *uint      // 文章を書いたセッション (GormWritingSession)。DurationSeconds はセッション開始からの経過時間
+	Overtime         bool       `gorm:"not null;default:false"` // 制限時間を超えて提出されたかどうか
+	AIScore          *int
+	AIFeedback       datatypes.JSON // JSON形式でフィードバック全体を保存
+	FeedbackText     *string        `gorm:"type:text"` // 全文検索用。AIFeedback の良い点・悪い点をテキストにしたもの
+	LatestReviewID   *uint          // 最新のレビュー (GormReview)。AIScore と AIFeedback はこのレビューの値
+	LatestRevisionID *uint          // 最新のリビジョン (GormWritingRevision)。Content と DurationSeconds はこのリビジョンの値
+	// プロンプトインジェクションの疑い。フラグが立った文章のスコアは上限が設けられます
+	// フラグが立たずに InjectionSignals だけがある文章は要確認です
+	InjectionFlagged bool `gorm:"not null;default:false;index"`
+	InjectionSignals datatypes.JSONSlice[string]
+	// ピアレビューの受付中かどうか。受付中の文章は他のユーザーが閲覧・レビューできます
+	OpenForPeerReview bool `gorm:"not null;default:false;index"`
+	// メンターが上書きしたスコア。最新のスコア付きメンターコメント (GormMentorComment) の値
+	MentorScore *int
+	// 提出後に内容が最後に編集された日時。DurationSeconds と Overtime は提出時の値のまま
+	EditedAfterSubmitAt *time.Time
 }
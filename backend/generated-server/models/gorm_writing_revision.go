@@ -0,0 +1,15 @@
+package models
+
+import "gorm.io/gorm"
+
+// GormWritingRevision is a snapshot of a writing's content, saved each time it changes.
+// Revisions are numbered from 1 per writing and never modified, except for a draft
+// revision, which follows the autosaves of its draft until the draft is submitted.
+type GormWritingRevision struct {
+	gorm.Model
+	WritingID       uint   `gorm:"not null;uniqueIndex:idx_writing_revision_number"`
+	Number          int    `gorm:"not null;uniqueIndex:idx_writing_revision_number"`
+	Content         string `gorm:"type:text;not null"`
+	DurationSeconds int    `gorm:"not null;default:0"`
+	Draft           bool   `gorm:"not null;default:false"`
+}
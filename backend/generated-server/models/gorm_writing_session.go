@@ -0,0 +1,21 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// GormWritingSession is a timed writing session started by the server. A writing must be
+// created within a session, whose start time the server uses to measure the duration.
+// Each session can be used for one writing only.
+type GormWritingSession struct {
+	gorm.Model
+	UserID    uint      `gorm:"not null;index"`
+	ThemeID   uint      `gorm:"not null"`
+	StartedAt time.Time `gorm:"not null"`
+	// TimeLimitSeconds is the theme's time limit when the session started; 0 means no limit.
+	TimeLimitSeconds int       `gorm:"not null;default:0"`
+	ExpiresAt        time.Time `gorm:"not null"`
+	WritingID        *uint     `gorm:"uniqueIndex"` // the writing created in this session
+}
@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// AIUsagePeriod reports AI usage and the remaining quota for one period.
+// Limits of 0 mean unlimited, in which case the remaining values are omitted.
+type AIUsagePeriod struct {
+	RequestsUsed      int64     `json:"requestsUsed"`
+	RequestLimit      int64     `json:"requestLimit"`
+	RequestsRemaining *int64    `json:"requestsRemaining,omitempty"`
+	TokensUsed        int64     `json:"tokensUsed"`
+	TokenLimit        int64     `json:"tokenLimit"`
+	TokensRemaining   *int64    `json:"tokensRemaining,omitempty"`
+	ResetsAt          time.Time `json:"resetsAt"`
+}
+
+// AIUsage is the API model for the authenticated user's AI usage.
+type AIUsage struct {
+	Daily   AIUsagePeriod `json:"daily"`
+	Monthly AIUsagePeriod `json:"monthly"`
+}
@@ -0,0 +1,17 @@
+package models
+
+// Annotation is the API model for an AI comment on a span of a writing.
+// Start and End are character offsets into the writing's content; End is exclusive.
+type Annotation struct {
+	Start int `json:"start"`
+
+	End int `json:"end"`
+
+	Quote string `json:"quote"`
+
+	Viewpoint string `json:"viewpoint"`
+
+	Severity string `json:"severity"`
+
+	Suggestion string `json:"suggestion"`
+}
@@ -0,0 +1,70 @@
+package models
+
+import (
+	"time"
+)
+
+// Attempt is the API model for one of the user's writings on a theme.
+type Attempt struct {
+	WritingID int64 `json:"writingId"`
+
+	// AttemptNumber counts the user's writings on the theme from 1, oldest first.
+	AttemptNumber int `json:"attemptNumber"`
+
+	AiScore *int `json:"aiScore,omitempty"`
+
+	// Scores of the latest review by viewpoint key.
+	Scores map[string]int `json:"scores,omitempty"`
+
+	LatestReviewID *uint `json:"latestReviewId,omitempty"`
+
+	DurationSeconds int32 `json:"durationSeconds"`
+
+	CharacterCount int `json:"characterCount"`
+
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// AttemptComparison is the API model for the comparison of two attempts on the same theme.
+type AttemptComparison struct {
+	ThemeID int64 `json:"themeId"`
+
+	Base Attempt `json:"base"`
+
+	Target Attempt `json:"target"`
+
+	// ScoreDelta is the change of the total score, set when both attempts have been reviewed.
+	ScoreDelta *int `json:"scoreDelta,omitempty"`
+
+	Viewpoints []ViewpointDelta `json:"viewpoints"`
+
+	// Diff is a sentence-level diff from the base content to the target content.
+	Diff []DiffSegment `json:"diff"`
+
+	// Summary is only set when requested with summary=true.
+	Summary *ComparisonSummary `json:"summary,omitempty"`
+}
+
+// ViewpointDelta is the change of one viewpoint's score between two attempts.
+type ViewpointDelta struct {
+	Key string `json:"key"`
+
+	Name string `json:"name"`
+
+	Base *int `json:"base"`
+
+	Target *int `json:"target"`
+
+	Delta *int `json:"delta"`
+}
+
+// ComparisonSummary is an AI-written description of what changed between two attempts.
+type ComparisonSummary struct {
+	Summary string `json:"summary"`
+
+	Improved []string `json:"improved"`
+
+	Regressed []string `json:"regressed"`
+
+	Model string `json:"model"`
+}
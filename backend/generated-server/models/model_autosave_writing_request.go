@@ -0,0 +1,12 @@
+package models
+
+// AutosaveWritingRequest defines the request body for autosaving a draft.
+// Fields that are omitted keep their saved value.
+type AutosaveWritingRequest struct {
+	Content *string `json:"content,omitempty"`
+
+	DurationSeconds *int32 `json:"durationSeconds,omitempty" binding:"omitempty,min=0"`
+
+	// Status "submitted" saves the draft and submits it. A submitted writing cannot go back to draft.
+	Status string `json:"status,omitempty" binding:"omitempty,oneof=draft submitted"`
+}
@@ -0,0 +1,48 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Interview is the API model for a mock interview about a writing.
+type Interview struct {
+	ID int64 `json:"id"`
+
+	WritingID int64 `json:"writingId"`
+
+	Status string `json:"status"`
+
+	MaxQuestions int `json:"maxQuestions"`
+
+	Turns []InterviewTurn `json:"turns"`
+
+	// Wrap-up review of the whole exchange, set once the interview is finished.
+	Model string `json:"model,omitempty"`
+
+	PromptVersion string `json:"promptVersion,omitempty"`
+
+	TotalScore *int `json:"totalScore,omitempty"`
+
+	// ScoreCapped reports whether the scores were capped because the writing or an answer was flagged for prompt injection.
+	ScoreCapped bool `json:"scoreCapped"`
+
+	Feedback json.RawMessage `json:"feedback,omitempty"`
+
+	CreatedAt time.Time `json:"createdAt"`
+
+	FinishedAt *time.Time `json:"finishedAt,omitempty"`
+}
+
+// InterviewTurn is the API model for one interviewer question and the user's answer.
+type InterviewTurn struct {
+	Seq int `json:"seq"`
+
+	Question string `json:"question"`
+
+	Answer *string `json:"answer"`
+
+	AskedAt time.Time `json:"askedAt"`
+
+	AnsweredAt *time.Time `json:"answeredAt,omitempty"`
+}
@@ -0,0 +1,6 @@
+package models
+
+// InterviewAnswerRequest defines the request body for answering the open interview question.
+type InterviewAnswerRequest struct {
+	Answer string `json:"answer" binding:"required"`
+}
@@ -0,0 +1,68 @@
+package models
+
+import (
+	"time"
+)
+
+// Mentorship is the API model for a mentor/mentee relationship.
+type Mentorship struct {
+	ID int64 `json:"id"`
+
+	MentorID int64 `json:"mentorId"`
+
+	MentorName *string `json:"mentorName,omitempty"`
+
+	// MenteeID and MenteeName are hidden from the mentor until the mentee accepts.
+	MenteeID *int64 `json:"menteeId,omitempty"`
+
+	MenteeName *string `json:"menteeName,omitempty"`
+
+	// MenteeEmail is the email the mentee was invited with, shown to the mentor only.
+	MenteeEmail *string `json:"menteeEmail,omitempty"`
+
+	// Status is one of "pending", "accepted", "declined" or "ended".
+	Status string `json:"status"`
+
+	// Role is the authenticated user's side of the relationship: "mentor" or "mentee".
+	Role string `json:"role"`
+
+	AcceptedAt *time.Time `json:"acceptedAt,omitempty"`
+
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// NewMentorshipRequest defines the request body for inviting a mentee.
+type NewMentorshipRequest struct {
+	MenteeEmail string `json:"menteeEmail" binding:"required,email"`
+}
+
+// MentorComment is the API model for a mentor's comment on a mentee's writing.
+type MentorComment struct {
+	ID int64 `json:"id"`
+
+	WritingID int64 `json:"writingId"`
+
+	MentorID int64 `json:"mentorId"`
+
+	MentorName *string `json:"mentorName,omitempty"`
+
+	Body string `json:"body"`
+
+	Score *int `json:"score,omitempty"`
+
+	ViewpointScores map[string]int `json:"viewpointScores,omitempty"`
+
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// NewMentorCommentRequest defines the request body for commenting on a mentee's writing.
+type NewMentorCommentRequest struct {
+	Body string `json:"body" binding:"required"`
+
+	// Score overrides the writing's total score.
+	Score *int `json:"score" binding:"omitempty,min=0,max=100"`
+
+	// ViewpointScores overrides the AI's scores of some viewpoints, keyed by viewpoint key.
+	// Without score, the total is recomputed from the AI's scores with these overrides.
+	ViewpointScores map[string]int `json:"viewpointScores"`
+}
@@ -0,0 +1,10 @@
+package models
+
+// NewRubricRequest is an API model used to create a rubric.
+// If a rubric with the same slug already exists, a new version of it is created.
+type NewRubricRequest struct {
+	Slug        string            `json:"slug" binding:"required"`
+	Name        string            `json:"name" binding:"required"`
+	Description string            `json:"description"`
+	Viewpoints  []RubricViewpoint `json:"viewpoints" binding:"required"`
+}
@@ -2,8 +2,11 @@ package models
 
 // NewThemeRequest is a an API model that is used by the frontend to create a new theme.
 type NewThemeRequest struct {
-	Title              string `json:"title"`
-	Description        string `json:"description"`
-	Category           string `json:"category"`
-	TimeLimitInSeconds int    `json:"timeLimitInSeconds"`
+	Title              string   `json:"title"`
+	Description        string   `json:"description"`
+	Category           string   `json:"category"`
+	TimeLimitInSeconds int      `json:"timeLimitInSeconds"`
+	RubricID           *uint    `json:"rubricId,omitempty"`
+	KeyPoints          []string `json:"keyPoints,omitempty"`
+	Misconceptions     []string `json:"misconceptions,omitempty"`
 }
@@ -4,7 +4,14 @@ package models
 type NewWritingRequest struct {
 	ThemeID int64 `json:"themeId"`
 
+	// SessionID is the session started with POST /themes/{themeId}/sessions.
+	SessionID *uint `json:"sessionId" binding:"required"`
+
 	Content string `json:"content"`
 
+	// DurationSeconds is ignored: the server measures the duration from the session start.
 	DurationSeconds int32 `json:"durationSeconds"`
+
+	// Status is "draft" to start a draft, or "submitted" (the default) to create a final writing.
+	Status string `json:"status,omitempty" binding:"omitempty,oneof=draft submitted"`
 }
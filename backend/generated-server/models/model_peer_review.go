@@ -0,0 +1,98 @@
+package models
+
+import (
+	"time"
+)
+
+// PeerReview is the API model for a review of a writing by another user.
+type PeerReview struct {
+	ID int64 `json:"id"`
+
+	WritingID int64 `json:"writingId"`
+
+	ReviewerID int64 `json:"reviewerId"`
+
+	ReviewerName *string `json:"reviewerName,omitempty"`
+
+	RubricID *uint `json:"rubricId"`
+
+	RubricVersion string `json:"rubricVersion"`
+
+	TotalScore int `json:"totalScore"`
+
+	Scores map[string]int `json:"scores"`
+
+	Feedbacks []FeedbackDetail `json:"feedbacks"`
+
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// FeedbackDetail is the feedback for one rubric viewpoint.
+type FeedbackDetail struct {
+	// Viewpoint is the viewpoint's key or display name.
+	Viewpoint string `json:"viewpoint" binding:"required"`
+
+	Score int `json:"score"`
+
+	GoodPoint string `json:"goodPoint" binding:"required"`
+
+	BadPoint string `json:"badPoint" binding:"required"`
+}
+
+// NewPeerReviewRequest defines the request body for reviewing another user's writing.
+type NewPeerReviewRequest struct {
+	// Feedbacks must cover every viewpoint of the theme's rubric exactly once.
+	Feedbacks []FeedbackDetail `json:"feedbacks" binding:"required,dive"`
+}
+
+// PeerReviewSettingsRequest defines the request body for opening or closing a writing for peer review.
+type PeerReviewSettingsRequest struct {
+	Open *bool `json:"open" binding:"required"`
+}
+
+// PeerReviewWriting is the API model for a writing that is open for peer review.
+type PeerReviewWriting struct {
+	ID int64 `json:"id"`
+
+	ThemeID int64 `json:"themeId"`
+
+	ThemeTitle string `json:"themeTitle"`
+
+	AuthorID int64 `json:"authorId"`
+
+	AuthorName *string `json:"authorName,omitempty"`
+
+	Content string `json:"content"`
+
+	PeerReviewCount int `json:"peerReviewCount"`
+
+	// ReviewedByMe reports whether the authenticated user has already reviewed the writing.
+	ReviewedByMe bool `json:"reviewedByMe"`
+
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// PeerReviewWritingSummary is the excerpt view of a PeerReviewWriting, without the content.
+type PeerReviewWritingSummary struct {
+	ID int64 `json:"id"`
+
+	ThemeID int64 `json:"themeId"`
+
+	ThemeTitle string `json:"themeTitle"`
+
+	AuthorID int64 `json:"authorId"`
+
+	AuthorName *string `json:"authorName,omitempty"`
+
+	// Excerpt is the beginning of the content, ending with "…" when the content is longer.
+	Excerpt string `json:"excerpt"`
+
+	CharacterCount int `json:"characterCount"`
+
+	PeerReviewCount int `json:"peerReviewCount"`
+
+	// ReviewedByMe reports whether the authenticated user has already reviewed the writing.
+	ReviewedByMe bool `json:"reviewedByMe"`
+
+	CreatedAt time.Time `json:"createdAt"`
+}
@@ -0,0 +1,64 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Review is the API model for one AI review run of a writing.
+type Review struct {
+	ID int64 `json:"id"`
+
+	WritingID int64 `json:"writingId"`
+
+	Model string `json:"model"`
+
+	RubricID *uint `json:"rubricId"`
+
+	PromptVersion string `json:"promptVersion"`
+
+	LatencyMs int64 `json:"latencyMs"`
+
+	PromptTokens int `json:"promptTokens"`
+
+	CompletionTokens int `json:"completionTokens"`
+
+	TotalTokens int `json:"totalTokens"`
+
+	TotalScore int `json:"totalScore"`
+
+	// ScoreCapped reports whether the scores were capped because the writing was flagged for prompt injection.
+	ScoreCapped bool `json:"scoreCapped"`
+
+	// Provisional reports whether this is a heuristic review made without the AI, covering only some viewpoints.
+	Provisional bool `json:"provisional"`
+
+	// Coverage of the theme's key points. Empty when the theme has none.
+	Coverage []KeyPointCoverage `json:"coverage"`
+
+	Feedback json.RawMessage `json:"feedback"`
+
+	Annotations []Annotation `json:"annotations"`
+
+	RawResponse string `json:"rawResponse"`
+
+	// Cached reports whether the result was reused from an earlier review of the same input.
+	Cached bool `json:"cached"`
+
+	CachedFromID *uint `json:"cachedFromId,omitempty"`
+
+	// RevisionID is the revision of the writing that was reviewed.
+	RevisionID *uint `json:"revisionId,omitempty"`
+
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// KeyPointCoverage reports whether a writing covered one of its theme's key points.
+type KeyPointCoverage struct {
+	KeyPoint string `json:"keyPoint"`
+
+	// Status is one of "covered", "missed" or "incorrect".
+	Status string `json:"status"`
+
+	Comment string `json:"comment,omitempty"`
+}
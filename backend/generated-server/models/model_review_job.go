@@ -0,0 +1,40 @@
+package models
+
+import (
+	"time"
+)
+
+// ReviewJob is the API model for an asynchronous AI review job.
+type ReviewJob struct {
+	ID int64 `json:"id"`
+
+	WritingID int64 `json:"writingId"`
+
+	Status string `json:"status"`
+
+	Attempts int `json:"attempts"`
+
+	Error string `json:"error,omitempty"`
+
+	ErrorCode string `json:"errorCode,omitempty"`
+
+	// Cached reports whether the review was reused from an earlier review of the same input.
+	Cached bool `json:"cached"`
+
+	// Writing holds the reviewed writing once the job has succeeded.
+	Writing *Writing `json:"writing,omitempty"`
+
+	// FallbackReview is a provisional heuristic review, set when the job failed.
+	FallbackReview *Review `json:"fallbackReview,omitempty"`
+
+	CreatedAt time.Time `json:"createdAt"`
+
+	UpdatedAt time.Time `json:"updatedAt"`
+
+	StartedAt *time.Time `json:"startedAt,omitempty"`
+
+	// NextRunAt is when a queued retry will run.
+	NextRunAt *time.Time `json:"nextRunAt,omitempty"`
+
+	FinishedAt *time.Time `json:"finishedAt,omitempty"`
+}
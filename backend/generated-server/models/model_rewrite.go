@@ -0,0 +1,36 @@
+package models
+
+import (
+	"time"
+)
+
+// Rewrite is the API model for an AI-generated improved version of a writing.
+type Rewrite struct {
+	ID int64 `json:"id"`
+
+	WritingID int64 `json:"writingId"`
+
+	ReviewID *uint `json:"reviewId"`
+
+	Model string `json:"model"`
+
+	LatencyMs int64 `json:"latencyMs"`
+
+	TotalTokens int `json:"totalTokens"`
+
+	OriginalContent string `json:"originalContent"`
+
+	Content string `json:"content"`
+
+	Diff []DiffSegment `json:"diff"`
+
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// DiffSegment is one sentence of a sentence-level diff.
+type DiffSegment struct {
+	// Op is one of "equal", "insert" or "delete".
+	Op string `json:"op"`
+
+	Text string `json:"text"`
+}
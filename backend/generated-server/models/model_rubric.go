@@ -0,0 +1,26 @@
+package models
+
+import (
+	"time"
+)
+
+// Rubric is the API model for an evaluation rubric.
+type Rubric struct {
+	ID int64 `json:"id"`
+
+	Slug string `json:"slug"`
+
+	Version int `json:"version"`
+
+	Name string `json:"name"`
+
+	Description string `json:"description"`
+
+	Viewpoints []RubricViewpoint `json:"viewpoints"`
+
+	IsDefault bool `json:"isDefault"`
+
+	CreatorID *uint `json:"creatorId"`
+
+	CreatedAt time.Time `json:"createdAt"`
+}
@@ -0,0 +1,43 @@
+package models
+
+import (
+	"time"
+)
+
+// SearchResult is one writing found by a search, with snippets of where it matched.
+type SearchResult struct {
+	WritingID int64 `json:"writingId"`
+
+	ThemeID int64 `json:"themeId"`
+
+	ThemeTitle string `json:"themeTitle"`
+
+	Status string `json:"status"`
+
+	AiScore *int `json:"aiScore,omitempty"`
+
+	// Relevance ranks the results; higher is better. It is only comparable within one search.
+	Relevance float64 `json:"relevance"`
+
+	Matches []SearchMatch `json:"matches"`
+
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// SearchMatch is a snippet of one field of a writing that matched a search.
+type SearchMatch struct {
+	// Field is "themeTitle", "content" or "feedback".
+	Field string `json:"field"`
+
+	Snippet string `json:"snippet"`
+
+	// Highlights are the matched parts of the snippet, in character offsets.
+	Highlights []TextRange `json:"highlights"`
+}
+
+// TextRange is a span of text in character offsets; end is exclusive.
+type TextRange struct {
+	Start int `json:"start"`
+
+	End int `json:"end"`
+}
@@ -25,4 +25,10 @@ type Theme struct {
 	UpdatedAt time.Time `json:"updatedAt"`
 
 	CreatorID *uint `json:"creatorId"`
+
+	RubricID *uint `json:"rubricId"`
+
+	KeyPoints []string `json:"keyPoints"`
+
+	Misconceptions []string `json:"misconceptions"`
 }
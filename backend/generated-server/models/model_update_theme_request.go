@@ -6,4 +6,8 @@ type UpdateThemeRequest struct {
 	Description        string `json:"description,omitempty"`
 	Category           string `json:"category,omitempty"`
 	TimeLimitInSeconds int    `json:"timeLimitInSeconds,omitempty"`
+	RubricID           *uint  `json:"rubricId,omitempty"`
+	// KeyPoints and Misconceptions replace the stored lists when present; an empty list clears them.
+	KeyPoints      *[]string `json:"keyPoints,omitempty" gorm:"-"`
+	Misconceptions *[]string `json:"misconceptions,omitempty" gorm:"-"`
 }
@@ -0,0 +1,9 @@
+package models
+
+// UpdateWritingRequest defines the request body for editing a writing.
+type UpdateWritingRequest struct {
+	Content *string `json:"content" binding:"required"`
+
+	// DurationSeconds keeps its saved value when omitted.
+	DurationSeconds *int32 `json:"durationSeconds,omitempty" binding:"omitempty,min=0"`
+}
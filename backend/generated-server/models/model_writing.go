@@ -1,6 +1,7 @@
 package models
 
 import (
+	"encoding/json"
 	"time"
 )
 
@@ -16,11 +17,59 @@ type Writing struct {
 
 	DurationSeconds int32 `json:"durationSeconds"`
 
+	// Status is "draft" or "submitted". Only submitted writings can be reviewed.
+	Status string `json:"status"`
+
+	// LastSavedAt is when the draft was last autosaved.
+	LastSavedAt *time.Time `json:"lastSavedAt,omitempty"`
+
+	// SessionID is the timed session the writing was created in.
+	SessionID *uint `json:"sessionId,omitempty"`
+
+	// Overtime is true when the writing was submitted after the theme's time limit.
+	Overtime bool `json:"overtime"`
+
+	// EditedAfterSubmitAt is when the content was last edited after submission. The duration
+	// and overtime stay as measured at submission.
+	EditedAfterSubmitAt *time.Time `json:"editedAfterSubmitAt,omitempty"`
+
 	AiScore int32 `json:"aiScore,omitempty"`
 
 	AiFeedback string `json:"aiFeedback,omitempty"`
 
+	LatestReviewID *uint `json:"latestReviewId,omitempty"`
+
+	// InjectionFlagged is true when the content looks like an attempt to manipulate the AI reviewer.
+	InjectionFlagged bool `json:"injectionFlagged"`
+
+	InjectionSignals []string `json:"injectionSignals,omitempty"`
+
+	// InjectionNeedsReview is true when suspicious phrases were found that are too weak to flag the writing.
+	InjectionNeedsReview bool `json:"injectionNeedsReview"`
+
+	// OpenForPeerReview is true when other users may read and review the writing.
+	OpenForPeerReview bool `json:"openForPeerReview"`
+
+	// MentorScore is the total score set by a mentor, overriding aiScore.
+	MentorScore *int `json:"mentorScore,omitempty"`
+
+	// Metrics are measurable signals of the content, such as sentence lengths and
+	// technical term density. Only included by the writing detail endpoint.
+	Metrics json.RawMessage `json:"metrics,omitempty"`
+
+	// Annotations of the latest review. Only included by the writing detail endpoint.
+	Annotations []Annotation `json:"annotations,omitempty"`
+
+	// PeerReviews by other users, oldest first. Only included by the writing detail endpoint.
+	PeerReviews []PeerReview `json:"peerReviews,omitempty"`
+
+	// MentorComments by the author's mentors, oldest first. Only included by the writing detail endpoint.
+	MentorComments []MentorComment `json:"mentorComments,omitempty"`
+
 	CreatedAt time.Time `json:"createdAt"`
 
 	UpdatedAt time.Time `json:"updatedAt"`
+
+	// DeletedAt is set for writings in the trash.
+	DeletedAt *time.Time `json:"deletedAt,omitempty"`
 }
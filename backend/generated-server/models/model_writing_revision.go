@@ -0,0 +1,66 @@
+package models
+
+import (
+	"time"
+)
+
+// WritingRevision is the API model for one saved version of a writing.
+type WritingRevision struct {
+	ID int64 `json:"id"`
+
+	WritingID int64 `json:"writingId"`
+
+	// Number counts the versions of the writing from 1.
+	Number int `json:"number"`
+
+	Content string `json:"content"`
+
+	DurationSeconds int32 `json:"durationSeconds"`
+
+	CharacterCount int `json:"characterCount"`
+
+	// Draft is true while the revision still follows the autosaves of a draft.
+	Draft bool `json:"draft"`
+
+	// ReviewID and AiScore come from the latest AI review of this revision, if any.
+	ReviewID *uint `json:"reviewId,omitempty"`
+
+	AiScore *int `json:"aiScore,omitempty"`
+
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// WritingRevisionSummary is the list representation of a revision, without its content.
+type WritingRevisionSummary struct {
+	ID int64 `json:"id"`
+
+	WritingID int64 `json:"writingId"`
+
+	Number int `json:"number"`
+
+	DurationSeconds int32 `json:"durationSeconds"`
+
+	CharacterCount int `json:"characterCount"`
+
+	Draft bool `json:"draft"`
+
+	ReviewID *uint `json:"reviewId,omitempty"`
+
+	AiScore *int `json:"aiScore,omitempty"`
+
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// RevisionDiff compares two revisions of a writing.
+type RevisionDiff struct {
+	WritingID int64 `json:"writingId"`
+
+	Base WritingRevision `json:"base"`
+
+	Target WritingRevision `json:"target"`
+
+	// ScoreDelta is the target's aiScore minus the base's, when both were reviewed.
+	ScoreDelta *int `json:"scoreDelta,omitempty"`
+
+	Diff []DiffSegment `json:"diff"`
+}
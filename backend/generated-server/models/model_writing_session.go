@@ -0,0 +1,22 @@
+package models
+
+import (
+	"time"
+)
+
+// WritingSession is the API model for a timed writing session.
+type WritingSession struct {
+	ID int64 `json:"id"`
+
+	ThemeID int64 `json:"themeId"`
+
+	StartedAt time.Time `json:"startedAt"`
+
+	// TimeLimitSeconds is the theme's time limit; 0 means no limit.
+	TimeLimitSeconds int `json:"timeLimitSeconds"`
+
+	// ExpiresAt is the deadline for creating the writing of this session.
+	ExpiresAt time.Time `json:"expiresAt"`
+
+	WritingID *uint `json:"writingId,omitempty"`
+}
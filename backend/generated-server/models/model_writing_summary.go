@@ -0,0 +1,34 @@
+package models
+
+import (
+	"time"
+)
+
+// WritingSummary is the lightweight list representation of a writing, with an excerpt
+// of its content instead of the full content and feedback.
+type WritingSummary struct {
+	ID int64 `json:"id"`
+
+	ThemeID int64 `json:"themeId"`
+
+	ThemeTitle string `json:"themeTitle"`
+
+	ThemeCategory string `json:"themeCategory"`
+
+	// Excerpt is the beginning of the content, ending with "…" when the content is longer.
+	Excerpt string `json:"excerpt"`
+
+	CharacterCount int `json:"characterCount"`
+
+	Status string `json:"status"`
+
+	DurationSeconds int32 `json:"durationSeconds"`
+
+	AiScore *int `json:"aiScore,omitempty"`
+
+	MentorScore *int `json:"mentorScore,omitempty"`
+
+	CreatedAt time.Time `json:"createdAt"`
+
+	UpdatedAt time.Time `json:"updatedAt"`
+}
@@ -0,0 +1,45 @@
+package seeder
+
+import (
+	"log"
+
+	"github.com/ch00z00/kotobalize/models"
+	"github.com/ch00z00/kotobalize/services"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// SeedRubrics stores the built-in default rubric if no default rubric exists yet.
+func SeedRubrics(db *gorm.DB) {
+	var count int64
+	db.Model(&models.GormRubric{}).Where("is_default = ?", true).Count(&count)
+	if count > 0 {
+		// If a default rubric exists, do not seed again.
+		return
+	}
+
+	rubric := services.DefaultRubric()
+	viewpoints := make([]models.RubricViewpoint, len(rubric.Viewpoints))
+	for i, vp := range rubric.Viewpoints {
+		viewpoints[i] = models.RubricViewpoint{
+			Key:         vp.Key,
+			Name:        vp.Name,
+			Description: vp.Description,
+			Weight:      vp.Weight,
+		}
+	}
+
+	gormRubric := models.GormRubric{
+		Slug:        rubric.Slug,
+		Version:     rubric.Version,
+		Name:        rubric.Name,
+		Description: "技術的な事柄を言語化する力を5つの観点で評価する標準のルーブリックです。",
+		Viewpoints:  datatypes.NewJSONSlice(viewpoints),
+		IsDefault:   true,
+	}
+	if err := db.Create(&gormRubric).Error; err != nil {
+		log.Fatalf("Failed to seed rubrics: %v", err)
+	}
+
+	log.Println("Database seeded with the default rubric.")
+}
@@ -42,6 +42,16 @@ func SeedThemes(db *gorm.DB) {
 			Description:        "原子性(Atomicity)、一貫性(Consistency)、独立性(Isolation)、永続性(Durability)の4つの特性がなぜ重要なのかを説明してください。",
 			Category:           "バックエンド",
 			TimeLimitInSeconds: 300,
+			KeyPoints: []string{
+				"原子性(Atomicity): トランザクション内の処理はすべて成功するか、すべて取り消されるかのどちらかになる",
+				"一貫性(Consistency): トランザクションの前後で制約や整合性が保たれる",
+				"独立性(Isolation): 同時に実行されるトランザクションが互いに影響しない",
+				"永続性(Durability): コミットされた結果は障害が起きても失われない",
+			},
+			Misconceptions: []string{
+				"一貫性(Consistency)を、分散システムのCAP定理における一貫性と同じものとして説明している",
+				"独立性は常に完全に保証されると説明し、分離レベルによる違いに触れていない",
+			},
 		},
 		{
 			Title:              "N+1問題とは何か、そしてそれをどのように解決しますか？",
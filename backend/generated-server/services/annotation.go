@@ -0,0 +1,125 @@
+package services
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+// Annotation severities, from least to most important.
+const (
+	AnnotationSeverityInfo     = "info"
+	AnnotationSeverityWarning  = "warning"
+	AnnotationSeverityCritical = "critical"
+)
+
+// maxAnnotations caps how many annotations a single review may contain.
+const maxAnnotations = 20
+
+// Annotation points at a span of the reviewed content.
+// Start and End are character (rune) offsets into the content; End is exclusive.
+type Annotation struct {
+	Start      int    `json:"start"`
+	End        int    `json:"end"`
+	Quote      string `json:"quote"`
+	Viewpoint  string `json:"viewpoint"`
+	Severity   string `json:"severity"`
+	Suggestion string `json:"suggestion"`
+}
+
+// alignAnnotations corrects offsets that do not match their quote.
+// Models quote text reliably but often miscount characters, so when the quote
+// appears in the content, the occurrence nearest to the given start wins.
+func alignAnnotations(annotations []Annotation, content string) {
+	for i := range annotations {
+		a := &annotations[i]
+		if a.Quote == "" || runeSlice(content, a.Start, a.End) == a.Quote {
+			continue
+		}
+		if start, ok := nearestOccurrence(content, a.Quote, a.Start); ok {
+			a.Start = start
+			a.End = start + utf8.RuneCountInString(a.Quote)
+		}
+	}
+}
+
+// RealignAnnotations fits annotations made for one text to another one that only differs in
+// whitespace, such as when a cached review is reused for content with other line endings.
+// Annotations whose quote can no longer be found in content are dropped.
+func RealignAnnotations(annotations []Annotation, content string, rubric Rubric) []Annotation {
+	aligned := slices.Clone(annotations)
+	alignAnnotations(aligned, content)
+	kept := []Annotation{}
+	for _, a := range aligned {
+		if len(ValidateAnnotations([]Annotation{a}, content, rubric)) == 0 {
+			kept = append(kept, a)
+		}
+	}
+	return kept
+}
+
+// ValidateAnnotations checks annotations against the reviewed content and the rubric
+// and returns a list of problems. An empty list means the annotations are valid.
+func ValidateAnnotations(annotations []Annotation, content string, rubric Rubric) []string {
+	var problems []string
+	if len(annotations) > maxAnnotations {
+		problems = append(problems, fmt.Sprintf("annotations must contain at most %d items, got %d", maxAnnotations, len(annotations)))
+	}
+
+	length := utf8.RuneCountInString(content)
+	for i, a := range annotations {
+		if a.Start < 0 || a.End > length || a.Start >= a.End {
+			problems = append(problems, fmt.Sprintf("annotations[%d] range [%d, %d) is outside the content (length %d)", i, a.Start, a.End, length))
+		} else if a.Quote != runeSlice(content, a.Start, a.End) {
+			problems = append(problems, fmt.Sprintf("annotations[%d].quote does not match the content at [%d, %d)", i, a.Start, a.End))
+		}
+		if _, ok := rubric.Viewpoint(a.Viewpoint); !ok {
+			problems = append(problems, fmt.Sprintf("annotations[%d].viewpoint %q is not a rubric viewpoint", i, a.Viewpoint))
+		}
+		switch a.Severity {
+		case AnnotationSeverityInfo, AnnotationSeverityWarning, AnnotationSeverityCritical:
+		default:
+			problems = append(problems, fmt.Sprintf("annotations[%d].severity must be one of info, warning, critical, got %q", i, a.Severity))
+		}
+		if strings.TrimSpace(a.Suggestion) == "" {
+			problems = append(problems, fmt.Sprintf("annotations[%d].suggestion is required", i))
+		}
+	}
+	return problems
+}
+
+// runeSlice returns the runes of s in [start, end), or "" if the range is invalid.
+func runeSlice(s string, start, end int) string {
+	if start < 0 || start >= end {
+		return ""
+	}
+	runes := []rune(s)
+	if end > len(runes) {
+		return ""
+	}
+	return string(runes[start:end])
+}
+
+// nearestOccurrence finds the rune offset of the occurrence of quote closest to near.
+func nearestOccurrence(content, quote string, near int) (int, bool) {
+	best, found := 0, false
+	for offset := 0; ; {
+		i := strings.Index(content[offset:], quote)
+		if i < 0 {
+			return best, found
+		}
+		start := utf8.RuneCountInString(content[:offset+i])
+		if !found || abs(start-near) < abs(best-near) {
+			best, found = start, true
+		}
+		offset += i + len(quote)
+	}
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
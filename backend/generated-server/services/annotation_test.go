@@ -0,0 +1,101 @@
+package services
+
+import (
+	"slices"
+	"testing"
+)
+
+// annotationContent is 9 + 11 characters, 60 bytes.
+const annotationContent = "キャッシュは速い。しかし整合性が難しい。"
+
+func annotationAt(start, end int) Annotation {
+	return Annotation{Start: start, End: end, Quote: runeSlice(annotationContent, start, end),
+		Viewpoint: "structure", Severity: AnnotationSeverityInfo, Suggestion: "具体例を挙げる"}
+}
+
+func TestValidateAnnotationsRanges(t *testing.T) {
+	// The whole content, a single character at either end, and overlapping spans are all fine.
+	valid := []Annotation{annotationAt(0, 20), annotationAt(0, 1), annotationAt(19, 20), annotationAt(5, 12), annotationAt(8, 10)}
+	if problems := ValidateAnnotations(valid, annotationContent, DefaultRubric()); len(problems) != 0 {
+		t.Errorf("problems = %q, want none", problems)
+	}
+
+	// Offsets count characters: a byte offset points somewhere else. Out of range, the
+	// quote cannot be compared, so only the range is reported.
+	byBytes := annotationAt(9, 20)
+	byBytes.Start, byBytes.End = 27, 60
+	got := ValidateAnnotations([]Annotation{annotationAt(20, 21), byBytes, annotationAt(3, 3)}, annotationContent, DefaultRubric())
+	want := []string{
+		"annotations[0] range [20, 21) is outside the content (length 20)",
+		"annotations[1] range [27, 60) is outside the content (length 20)",
+		"annotations[2] range [3, 3) is outside the content (length 20)",
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("problems = %q, want %q", got, want)
+	}
+
+	// An empty review leaves nothing to point at.
+	want = []string{"annotations[0] range [0, 1) is outside the content (length 0)"}
+	if got := ValidateAnnotations([]Annotation{annotationAt(0, 1)}, "", DefaultRubric()); !slices.Equal(got, want) {
+		t.Errorf("problems with empty content = %q, want %q", got, want)
+	}
+}
+
+func TestValidateAnnotationsReportsEveryField(t *testing.T) {
+	// Viewpoints may be given by key or by name, like scores.
+	byName := annotationAt(0, 9)
+	byName.Viewpoint = "構造化力"
+	bad := Annotation{Start: 9, End: 12, Quote: "しかし整", Viewpoint: "Structure", Severity: "Warning", Suggestion: "\n\t"}
+
+	got := ValidateAnnotations([]Annotation{byName, bad}, annotationContent, DefaultRubric())
+	want := []string{
+		"annotations[1].quote does not match the content at [9, 12)",
+		`annotations[1].viewpoint "Structure" is not a rubric viewpoint`,
+		`annotations[1].severity must be one of info, warning, critical, got "Warning"`,
+		"annotations[1].suggestion is required",
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("problems = %q, want %q", got, want)
+	}
+}
+
+func TestValidateAnnotationsLimit(t *testing.T) {
+	limit := slices.Repeat([]Annotation{annotationAt(0, 9)}, maxAnnotations)
+	if problems := ValidateAnnotations(limit, annotationContent, DefaultRubric()); len(problems) != 0 {
+		t.Errorf("%d annotations: problems = %q, want none", maxAnnotations, problems)
+	}
+	// Going over the limit is reported once, in addition to the problems of each item.
+	over := append(limit, annotationAt(0, 30))
+	want := []string{
+		"annotations must contain at most 20 items, got 21",
+		"annotations[20] range [0, 30) is outside the content (length 20)",
+	}
+	if got := ValidateAnnotations(over, annotationContent, DefaultRubric()); !slices.Equal(got, want) {
+		t.Errorf("problems = %q, want %q", got, want)
+	}
+}
+
+func TestRealignAnnotations(t *testing.T) {
+	// The cached review was made for LF content; the writing uses CRLF and trailing spaces.
+	source := "キャッシュは速い。\nしかし整合性が難しい。"
+	content := "キャッシュは速い。  \r\nしかし整合性が難しい。"
+	annotations := []Annotation{
+		{Start: 10, End: 21, Quote: "しかし整合性が難しい。", Viewpoint: "structure", Severity: AnnotationSeverityWarning, Suggestion: "理由を述べる"},
+		{Start: 0, End: 10, Quote: "キャッシュは速い。\n", Viewpoint: "vocabulary", Severity: AnnotationSeverityInfo, Suggestion: "何より速いのか"},
+	}
+	if problems := ValidateAnnotations(annotations, source, DefaultRubric()); len(problems) != 0 {
+		t.Fatalf("annotations do not fit the source: %v", problems)
+	}
+
+	got := RealignAnnotations(annotations, content, DefaultRubric())
+	if len(got) != 1 {
+		t.Fatalf("got %d annotations, want 1: %+v", len(got), got)
+	}
+	if got[0].Start != 13 || got[0].End != 24 {
+		t.Errorf("range = [%d, %d), want [13, 24)", got[0].Start, got[0].End)
+	}
+	// The input is left as it was.
+	if annotations[0].Start != 10 {
+		t.Errorf("input was modified: %+v", annotations[0])
+	}
+}
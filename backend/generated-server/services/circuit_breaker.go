@@ -0,0 +1,112 @@
+package services
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned without calling the AI backend while the circuit breaker is open.
+var ErrCircuitOpen = errors.New("AI service is temporarily unavailable (circuit breaker open)")
+
+// Circuit breaker states.
+const (
+	CircuitClosed   = "closed"
+	CircuitOpen     = "open"
+	CircuitHalfOpen = "half_open"
+)
+
+// CircuitBreaker stops calls to a failing backend for a while so that requests fail fast
+// instead of waiting for timeouts. After failureThreshold consecutive failures it opens;
+// once openDuration has passed it lets a single trial call through (half-open), and that
+// call decides whether it closes again or stays open.
+type CircuitBreaker struct {
+	failureThreshold int
+	openDuration     time.Duration
+
+	mu                  sync.Mutex
+	state               string
+	consecutiveFailures int
+	openedAt            time.Time
+	trialInFlight       bool
+}
+
+// NewCircuitBreaker returns a closed breaker. A failureThreshold of 0 or less disables it.
+func NewCircuitBreaker(failureThreshold int, openDuration time.Duration) *CircuitBreaker {
+	return &CircuitBreaker{failureThreshold: failureThreshold, openDuration: openDuration, state: CircuitClosed}
+}
+
+// Allow reports whether a call may be made now, returning ErrCircuitOpen if not.
+// Every allowed call must be followed by Record or Abandon.
+func (b *CircuitBreaker) Allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == CircuitOpen && time.Since(b.openedAt) >= b.openDuration {
+		b.state = CircuitHalfOpen
+	}
+	switch b.state {
+	case CircuitOpen:
+		return ErrCircuitOpen
+	case CircuitHalfOpen:
+		if b.trialInFlight {
+			return ErrCircuitOpen
+		}
+		b.trialInFlight = true
+	}
+	return nil
+}
+
+// Record reports the outcome of an allowed call. healthy is false only for failures
+// that suggest the backend is down or overloaded, such as timeouts, 429 and 5xx.
+func (b *CircuitBreaker) Record(healthy bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.trialInFlight = false
+	if healthy {
+		b.state = CircuitClosed
+		b.consecutiveFailures = 0
+		return
+	}
+	b.consecutiveFailures++
+	if b.failureThreshold > 0 && (b.state == CircuitHalfOpen || b.consecutiveFailures >= b.failureThreshold) {
+		b.state = CircuitOpen
+		b.openedAt = time.Now()
+	}
+}
+
+// Abandon releases an allowed call that ended without telling anything about the backend,
+// for example because the caller cancelled it.
+func (b *CircuitBreaker) Abandon() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.trialInFlight = false
+}
+
+// CircuitBreakerState is a snapshot of a CircuitBreaker for health checks.
+type CircuitBreakerState struct {
+	State               string     `json:"state"`
+	ConsecutiveFailures int        `json:"consecutiveFailures"`
+	OpenedAt            *time.Time `json:"openedAt,omitempty"`
+	// RetryAt is when an open breaker will let a trial call through.
+	RetryAt *time.Time `json:"retryAt,omitempty"`
+}
+
+// State returns a snapshot of the breaker.
+func (b *CircuitBreaker) State() CircuitBreakerState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	state := CircuitBreakerState{State: b.state, ConsecutiveFailures: b.consecutiveFailures}
+	if b.state == CircuitOpen && time.Since(b.openedAt) >= b.openDuration {
+		state.State = CircuitHalfOpen
+	}
+	if b.state != CircuitClosed {
+		openedAt := b.openedAt
+		retryAt := b.openedAt.Add(b.openDuration)
+		state.OpenedAt = &openedAt
+		state.RetryAt = &retryAt
+	}
+	return state
+}
@@ -0,0 +1,185 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	openai "github.com/sashabaranov/go-openai"
+)
+
+// Attempt is one writing on a theme together with its latest review, if any.
+type Attempt struct {
+	Content string
+	Review  *AIReviewResponse
+}
+
+// ViewpointDelta is the change of one viewpoint's score between two attempts.
+// Before and After are nil when that attempt has no score for the viewpoint.
+type ViewpointDelta struct {
+	Key    string
+	Name   string
+	Before *int
+	After  *int
+	Delta  *int
+}
+
+// ComparisonSummary describes what changed between two attempts.
+type ComparisonSummary struct {
+	Summary   string   `json:"summary"`
+	Improved  []string `json:"improved"`
+	Regressed []string `json:"regressed"`
+}
+
+// ComparisonResult is a ComparisonSummary together with metadata about how it was produced.
+type ComparisonResult struct {
+	Summary ComparisonSummary
+	// Raw is the unparsed model output.
+	Raw   string
+	Model string
+	Usage TokenUsage
+}
+
+// ViewpointDeltas compares the scores of two reviews, in the order of the rubric's viewpoints
+// followed by any other viewpoint either review has. Either review may be nil.
+func ViewpointDeltas(before, after *AIReviewResponse, rubric Rubric) []ViewpointDelta {
+	var keys []string
+	for _, vp := range rubric.Viewpoints {
+		keys = append(keys, vp.Key)
+	}
+	var extra []string
+	for _, review := range []*AIReviewResponse{before, after} {
+		if review == nil {
+			continue
+		}
+		for key := range review.Scores {
+			if !slices.Contains(keys, key) && !slices.Contains(extra, key) {
+				extra = append(extra, key)
+			}
+		}
+	}
+	slices.Sort(extra)
+	keys = append(keys, extra...)
+
+	score := func(review *AIReviewResponse, key string) *int {
+		if review == nil {
+			return nil
+		}
+		if s, ok := review.Scores[key]; ok {
+			return &s
+		}
+		return nil
+	}
+
+	deltas := make([]ViewpointDelta, 0, len(keys))
+	for _, key := range keys {
+		d := ViewpointDelta{Key: key, Name: key, Before: score(before, key), After: score(after, key)}
+		if vp, ok := rubric.Viewpoint(key); ok {
+			d.Name = vp.Name
+		}
+		if d.Before == nil && d.After == nil {
+			continue
+		}
+		if d.Before != nil && d.After != nil {
+			delta := *d.After - *d.Before
+			d.Delta = &delta
+		}
+		deltas = append(deltas, d)
+	}
+	return deltas
+}
+
+// CompareAttempts asks the model what improved and what regressed from before to after.
+// input provides the theme and rubric; its Content is not used.
+func (s *OpenAIService) CompareAttempts(ctx context.Context, input ReviewInput, before, after Attempt) (*ComparisonResult, error) {
+	request := openai.ChatCompletionRequest{
+		Model: s.Model,
+		Messages: []openai.ChatCompletionMessage{
+			{Role: openai.ChatMessageRoleSystem, Content: compareSystemPrompt + untrustedContentNotice},
+			{Role: openai.ChatMessageRoleUser, Content: buildCompareUserPrompt(input, before, after)},
+		},
+	}
+	if s.JSONMode {
+		request.ResponseFormat = &openai.ChatCompletionResponseFormat{
+			Type: openai.ChatCompletionResponseFormatTypeJSONObject,
+		}
+	}
+
+	result := &ComparisonResult{Model: s.Model}
+	content, err := s.complete(ctx, request, &result.Model, &result.Usage)
+	if err != nil {
+		return nil, withUsage(err, result.Model, result.Usage)
+	}
+
+	var parsed ComparisonSummary
+	if err := json.Unmarshal([]byte(extractJSONObject(content)), &parsed); err != nil {
+		return nil, withUsage(fmt.Errorf("%w: comparison is not valid JSON: %v", ErrInvalidAIResponse, err), result.Model, result.Usage)
+	}
+	if strings.TrimSpace(parsed.Summary) == "" {
+		return nil, withUsage(fmt.Errorf("%w: comparison summary is empty", ErrInvalidAIResponse), result.Model, result.Usage)
+	}
+	parsed.Summary = strings.TrimSpace(parsed.Summary)
+	parsed.Improved = nonEmptyStrings(parsed.Improved)
+	parsed.Regressed = nonEmptyStrings(parsed.Regressed)
+	result.Summary = parsed
+	result.Raw = content
+	return result, nil
+}
+
+// nonEmptyStrings trims items and drops the empty ones, never returning nil.
+func nonEmptyStrings(items []string) []string {
+	cleaned := []string{}
+	for _, item := range items {
+		if item = strings.TrimSpace(item); item != "" {
+			cleaned = append(cleaned, item)
+		}
+	}
+	return cleaned
+}
+
+const compareSystemPrompt = `
+あなたは、ユーザーが技術的な事柄を言語化する能力を向上させるための、世界クラスのソフトウェアエンジニアリングコーチです。
+同じテーマについてユーザーが書いた、以前の文章と今回の文章を比較し、何が良くなり、何が悪くなったかを伝えてください。
+
+## 比較のルール
+- 2つの文章の内容とレビュー結果に基づいて、具体的に比較してください。
+- 良くなった点・悪くなった点は、それぞれ1文で簡潔に書いてください。該当がなければ空の配列にしてください。
+- summary では、次に書くときに意識すべきことを含めて全体の変化を2〜3文でまとめてください。
+
+## 出力形式 (JSON)
+{
+  "summary": "<全体の変化のまとめ>",
+  "improved": ["<良くなった点>"],
+  "regressed": ["<悪くなった点>"]
+}
+`
+
+// buildCompareUserPrompt renders two attempts and their reviews for a comparison request.
+func buildCompareUserPrompt(input ReviewInput, before, after Attempt) string {
+	var deltas strings.Builder
+	for _, d := range ViewpointDeltas(before.Review, after.Review, input.Rubric) {
+		if d.Delta != nil {
+			fmt.Fprintf(&deltas, "- %s: %d点 → %d点\n", d.Name, *d.Before, *d.After)
+		}
+	}
+	if deltas.Len() == 0 {
+		deltas.WriteString("（比較できるレビューがありません）\n")
+	}
+
+	return fmt.Sprintf(`
+以下のテーマについて書かれた2つの文章を比較してください。
+
+## テーマ
+%s
+
+## 以前の文章
+%s
+
+## 今回の文章
+%s
+
+## 観点別スコアの変化
+%s`, input.themeText(), fenceUserContent(before.Content), fenceUserContent(after.Content), deltas.String())
+}
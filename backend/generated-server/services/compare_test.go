@@ -0,0 +1,59 @@
+package services
+
+import "testing"
+
+// optionalScore returns the score, or nil without one, so that scores compare with ==.
+func optionalScore(score *int) any {
+	if score == nil {
+		return nil
+	}
+	return *score
+}
+
+func TestViewpointDeltas(t *testing.T) {
+	rubric := Rubric{Slug: "weighted", Version: 1, Viewpoints: []RubricViewpoint{
+		{Key: "structure", Name: "論理構成", Weight: 2},
+		{Key: "vocabulary", Name: "用語", Weight: 1},
+		{Key: "accuracy", Name: "正確性", Weight: 1},
+	}}
+	before := &AIReviewResponse{Scores: map[string]int{"vocabulary": 70, "structure": 60, "perspective": 50}}
+	after := &AIReviewResponse{Scores: map[string]int{"structure": 75, "vocabulary": 70, "observation": 40, "abstraction": 80}}
+
+	// The rubric's viewpoints come first in its order, then the others by key under
+	// their key. A viewpoint neither review scored is left out.
+	want := []struct {
+		key, name            string
+		before, after, delta any
+	}{
+		{"structure", "論理構成", 60, 75, 15},
+		{"vocabulary", "用語", 70, 70, 0},
+		{"abstraction", "abstraction", nil, 80, nil},
+		{"observation", "observation", nil, 40, nil},
+		{"perspective", "perspective", 50, nil, nil},
+	}
+	got := ViewpointDeltas(before, after, rubric)
+	if len(got) != len(want) {
+		t.Fatalf("deltas = %+v, want %d of them", got, len(want))
+	}
+	for i, w := range want {
+		d := got[i]
+		if d.Key != w.key || d.Name != w.name || optionalScore(d.Before) != w.before || optionalScore(d.After) != w.after || optionalScore(d.Delta) != w.delta {
+			t.Errorf("deltas[%d] = %s %s %v→%v (%v), want %s %s %v→%v (%v)", i,
+				d.Key, d.Name, optionalScore(d.Before), optionalScore(d.After), optionalScore(d.Delta), w.key, w.name, w.before, w.after, w.delta)
+		}
+	}
+}
+
+func TestViewpointDeltasWithoutReviews(t *testing.T) {
+	rubric := DefaultRubric()
+	if got := ViewpointDeltas(nil, nil, rubric); got == nil || len(got) != 0 {
+		t.Errorf("deltas without reviews = %#v, want an empty, non-nil list", got)
+	}
+
+	// Against an unreviewed attempt, every score is there but no delta is.
+	after := &AIReviewResponse{Scores: map[string]int{"structure": 75}}
+	got := ViewpointDeltas(nil, after, rubric)
+	if len(got) != 1 || got[0].Key != "structure" || got[0].Before != nil || optionalScore(got[0].After) != 75 || got[0].Delta != nil {
+		t.Errorf("deltas against no review = %+v, want only structure without a delta", got)
+	}
+}
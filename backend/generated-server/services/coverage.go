@@ -0,0 +1,86 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Key point coverage statuses.
+const (
+	CoverageStatusCovered   = "covered"
+	CoverageStatusMissed    = "missed"
+	CoverageStatusIncorrect = "incorrect"
+)
+
+// KeyPointCoverage reports whether the writing covered one of the theme's key points.
+type KeyPointCoverage struct {
+	KeyPoint string `json:"keyPoint"`
+	Status   string `json:"status"`
+	Comment  string `json:"comment,omitempty"`
+}
+
+// ValidateCoverage checks that coverage lists each key point exactly once with a valid status
+// and returns a list of problems. An empty list means the coverage is valid.
+func ValidateCoverage(coverage []KeyPointCoverage, keyPoints []string) []string {
+	var problems []string
+
+	expected := make(map[string]bool, len(keyPoints))
+	for _, kp := range keyPoints {
+		expected[kp] = true
+	}
+	seen := make(map[string]bool, len(coverage))
+	for i, c := range coverage {
+		keyPoint := strings.TrimSpace(c.KeyPoint)
+		if !expected[keyPoint] {
+			problems = append(problems, fmt.Sprintf("coverage[%d].keyPoint %q is not one of the theme's key points", i, c.KeyPoint))
+			continue
+		}
+		if seen[keyPoint] {
+			problems = append(problems, fmt.Sprintf("coverage contains %q more than once", keyPoint))
+			continue
+		}
+		seen[keyPoint] = true
+		switch c.Status {
+		case CoverageStatusCovered, CoverageStatusMissed, CoverageStatusIncorrect:
+		default:
+			problems = append(problems, fmt.Sprintf("coverage[%d].status must be one of covered, missed, incorrect, got %q", i, c.Status))
+		}
+	}
+	for _, kp := range keyPoints {
+		if !seen[kp] {
+			problems = append(problems, fmt.Sprintf("coverage is missing %q", kp))
+		}
+	}
+	return problems
+}
+
+// buildCoveragePrompt explains the theme's key points and misconceptions to the reviewer.
+// It returns "" when the theme has neither.
+func buildCoveragePrompt(input ReviewInput) string {
+	if len(input.KeyPoints) == 0 && len(input.Misconceptions) == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteString("\n## キーポイントの網羅性\n")
+	if len(input.KeyPoints) > 0 {
+		b.WriteString("このテーマの模範的な回答は、次のキーポイントに触れています。\n")
+		for _, kp := range input.KeyPoints {
+			fmt.Fprintf(&b, "- %s\n", kp)
+		}
+		b.WriteString(`
+出力するJSONに "coverage" を追加し、全てのキーポイントについて文章での扱いを報告してください。
+keyPoint には上記のキーポイントを一字一句そのまま記載し、status は covered（正しく説明できている）、missed（触れていない）、incorrect（誤って説明している）のいずれかにしてください。
+  "coverage": [
+    { "keyPoint": "<キーポイント>", "status": "<covered | missed | incorrect>", "comment": "<判断の根拠>" }
+  ]
+`)
+	}
+	if len(input.Misconceptions) > 0 {
+		b.WriteString("\nこのテーマでよくある誤解は次のとおりです。文章にこれらの誤解が含まれている場合は、該当する観点の改善点で指摘してください。\n")
+		for _, m := range input.Misconceptions {
+			fmt.Fprintf(&b, "- %s\n", m)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,105 @@
+package services
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+var coverageKeyPoints = []string{"書き込みの順序", "障害時の挙動"}
+
+func TestValidateCoverageAcceptsAnyOrder(t *testing.T) {
+	coverage := []KeyPointCoverage{
+		{KeyPoint: "障害時の挙動", Status: CoverageStatusIncorrect, Comment: "逆に説明している"},
+		// The model may pad the key point it copies.
+		{KeyPoint: " 書き込みの順序\n", Status: CoverageStatusMissed},
+	}
+	if problems := ValidateCoverage(coverage, coverageKeyPoints); len(problems) != 0 {
+		t.Errorf("problems = %q, want none", problems)
+	}
+}
+
+func TestValidateCoverageWithoutKeyPoints(t *testing.T) {
+	if problems := ValidateCoverage(nil, nil); problems != nil {
+		t.Errorf("problems = %q, want none", problems)
+	}
+	// Coverage the theme did not ask for is an error, not something to store.
+	got := ValidateCoverage([]KeyPointCoverage{{KeyPoint: "書き込みの順序", Status: CoverageStatusCovered}}, nil)
+	want := []string{`coverage[0].keyPoint "書き込みの順序" is not one of the theme's key points`}
+	if !slices.Equal(got, want) {
+		t.Errorf("problems = %q, want %q", got, want)
+	}
+}
+
+func TestValidateCoverageProblems(t *testing.T) {
+	checks := []struct {
+		name     string
+		coverage []KeyPointCoverage
+		want     []string
+	}{
+		{
+			// A paraphrased key point does not count, so the original is missing too.
+			name: "paraphrased key point",
+			coverage: []KeyPointCoverage{
+				{KeyPoint: "書き込み順序", Status: CoverageStatusCovered},
+				{KeyPoint: "障害時の挙動", Status: CoverageStatusMissed},
+			},
+			want: []string{
+				`coverage[0].keyPoint "書き込み順序" is not one of the theme's key points`,
+				`coverage is missing "書き込みの順序"`,
+			},
+		},
+		{
+			// Only the first entry counts; a contradicting duplicate is reported, not checked.
+			name: "duplicate with another status",
+			coverage: []KeyPointCoverage{
+				{KeyPoint: "書き込みの順序", Status: CoverageStatusCovered},
+				{KeyPoint: "障害時の挙動", Status: CoverageStatusMissed},
+				{KeyPoint: " 書き込みの順序", Status: "partial"},
+			},
+			want: []string{`coverage contains "書き込みの順序" more than once`},
+		},
+		{
+			name: "statuses are case-sensitive",
+			coverage: []KeyPointCoverage{
+				{KeyPoint: "書き込みの順序", Status: "Covered"},
+				{KeyPoint: "障害時の挙動", Status: ""},
+			},
+			want: []string{
+				`coverage[0].status must be one of covered, missed, incorrect, got "Covered"`,
+				`coverage[1].status must be one of covered, missed, incorrect, got ""`,
+			},
+		},
+		{
+			name: "nothing reported",
+			want: []string{`coverage is missing "書き込みの順序"`, `coverage is missing "障害時の挙動"`},
+		},
+	}
+	for _, c := range checks {
+		if got := ValidateCoverage(c.coverage, coverageKeyPoints); !slices.Equal(got, c.want) {
+			t.Errorf("%s: problems = %q, want %q", c.name, got, c.want)
+		}
+	}
+}
+
+func TestBuildCoveragePrompt(t *testing.T) {
+	if prompt := buildCoveragePrompt(ReviewInput{KeyPoints: []string{}, Misconceptions: nil}); prompt != "" {
+		t.Errorf("prompt without key points or misconceptions = %q, want none", prompt)
+	}
+
+	// Without key points there is nothing to report coverage for.
+	prompt := buildCoveragePrompt(ReviewInput{Misconceptions: []string{"常に速い"}})
+	if strings.Contains(prompt, `"coverage"`) || !strings.Contains(prompt, "- 常に速い\n") {
+		t.Errorf("prompt with only misconceptions = %q, want the misconception without a coverage field", prompt)
+	}
+
+	prompt = buildCoveragePrompt(ReviewInput{KeyPoints: coverageKeyPoints})
+	for _, kp := range coverageKeyPoints {
+		if !strings.Contains(prompt, "- "+kp+"\n") {
+			t.Errorf("prompt does not list key point %q verbatim: %q", kp, prompt)
+		}
+	}
+	if !strings.Contains(prompt, `"coverage"`) {
+		t.Errorf("prompt does not ask for coverage: %q", prompt)
+	}
+}
@@ -0,0 +1,89 @@
+package services
+
+import "strings"
+
+// Diff operations.
+const (
+	DiffOpEqual  = "equal"
+	DiffOpInsert = "insert"
+	DiffOpDelete = "delete"
+)
+
+// DiffSegment is one sentence of a sentence-level diff.
+type DiffSegment struct {
+	Op   string `json:"op"`
+	Text string `json:"text"`
+}
+
+// SplitSentences splits Japanese or English text into sentences. A sentence ends
+// after 。！？!? or a line break; the terminator stays with the sentence and
+// surrounding whitespace is trimmed. Blank lines produce no sentences.
+func SplitSentences(text string) []string {
+	var sentences []string
+	var current strings.Builder
+	flush := func() {
+		if s := strings.TrimSpace(current.String()); s != "" {
+			sentences = append(sentences, s)
+		}
+		current.Reset()
+	}
+	for _, ch := range text {
+		switch ch {
+		case '\n', '\r':
+			flush()
+		case '。', '！', '？', '!', '?':
+			current.WriteRune(ch)
+			flush()
+		default:
+			current.WriteRune(ch)
+		}
+	}
+	flush()
+	return sentences
+}
+
+// DiffSentences computes a sentence-level diff that turns before into after,
+// using the longest common subsequence of sentences. Deletions are listed
+// before insertions at each changed position.
+func DiffSentences(before, after string) []DiffSegment {
+	a, b := SplitSentences(before), SplitSentences(after)
+
+	// lcs[i][j] is the LCS length of a[i:] and b[j:].
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	segments := make([]DiffSegment, 0, len(a)+len(b))
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			segments = append(segments, DiffSegment{Op: DiffOpEqual, Text: a[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			segments = append(segments, DiffSegment{Op: DiffOpDelete, Text: a[i]})
+			i++
+		default:
+			segments = append(segments, DiffSegment{Op: DiffOpInsert, Text: b[j]})
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		segments = append(segments, DiffSegment{Op: DiffOpDelete, Text: a[i]})
+	}
+	for ; j < len(b); j++ {
+		segments = append(segments, DiffSegment{Op: DiffOpInsert, Text: b[j]})
+	}
+	return segments
+}
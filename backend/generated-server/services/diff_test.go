@@ -0,0 +1,86 @@
+package services
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestSplitSentences(t *testing.T) {
+	tests := map[string][]string{
+		// Periods inside numbers and versions do not end a sentence, only 。！？!? and line breaks do.
+		"Go 1.23では3.5倍速い。次へ":  {"Go 1.23では3.5倍速い。", "次へ"},
+		"Why? Because!本当？はい！": {"Why?", "Because!", "本当？", "はい！"},
+		// CR, LF and CRLF all break lines without leaving empty sentences.
+		"一行目\r\n二行目\r三行目\n\n\n四行目": {"一行目", "二行目", "三行目", "四行目"},
+		"  前後の空白は除く。  \t":          {"前後の空白は除く。"},
+		" \n\t\r\n ":               nil,
+	}
+	for text, want := range tests {
+		if got := SplitSentences(text); !slices.Equal(got, want) {
+			t.Errorf("SplitSentences(%q) = %q, want %q", text, got, want)
+		}
+	}
+}
+
+// diffSides rebuilds the sentences of both sides from a diff.
+func diffSides(segments []DiffSegment) (before, after []string, equal int) {
+	for _, s := range segments {
+		switch s.Op {
+		case DiffOpEqual:
+			before = append(before, s.Text)
+			after = append(after, s.Text)
+			equal++
+		case DiffOpDelete:
+			before = append(before, s.Text)
+		case DiffOpInsert:
+			after = append(after, s.Text)
+		}
+	}
+	return before, after, equal
+}
+
+func TestDiffSentencesRebuildsBothSides(t *testing.T) {
+	pairs := []struct {
+		before, after string
+		common        int // length of the longest common subsequence of sentences
+	}{
+		{"A。B。C。", "A。B。C。", 3},
+		{"A。B。C。", "C。B。A。", 1},
+		{"A。A。B。", "A。B。A。", 2},
+		{"A。B。A。B。", "B。A。B。A。", 3},
+		{"A。", "B。C。D。", 0},
+		{"", "新しい文章。", 0},
+		{"古い文章。\n", "", 0},
+		{"結論です。\n\n理由です。", "結論です。理由です。", 2},
+	}
+	for _, p := range pairs {
+		segments := DiffSentences(p.before, p.after)
+		before, after, equal := diffSides(segments)
+		if !slices.Equal(before, SplitSentences(p.before)) || !slices.Equal(after, SplitSentences(p.after)) {
+			t.Errorf("DiffSentences(%q, %q) = %v, which does not rebuild both sides", p.before, p.after, segments)
+		}
+		// Anything less would report unchanged sentences as edited.
+		if equal != p.common {
+			t.Errorf("DiffSentences(%q, %q) keeps %d sentences, want %d", p.before, p.after, equal, p.common)
+		}
+	}
+}
+
+func TestDiffSentencesReplacement(t *testing.T) {
+	// At a changed position, the old sentences come before the new ones.
+	got := DiffSentences("結論です。理由です。補足です。まとめです。", "結論です。具体例です。まとめです。")
+	want := []DiffSegment{
+		{DiffOpEqual, "結論です。"},
+		{DiffOpDelete, "理由です。"},
+		{DiffOpDelete, "補足です。"},
+		{DiffOpInsert, "具体例です。"},
+		{DiffOpEqual, "まとめです。"},
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("DiffSentences = %v, want %v", got, want)
+	}
+
+	if got := DiffSentences("", " \n"); got == nil || len(got) != 0 {
+		t.Errorf("DiffSentences of two empty texts = %#v, want an empty, non-nil list", got)
+	}
+}
@@ -0,0 +1,22 @@
+package services
+
+import (
+	"log"
+	"os"
+	"strconv"
+)
+
+// EnvNonNegativeInt reads a non-negative integer from the environment, falling back to def
+// when the variable is unset or invalid.
+func EnvNonNegativeInt(key string, def int) int {
+	value := os.Getenv(key)
+	if value == "" {
+		return def
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 0 {
+		log.Printf("ignoring invalid %s=%q", key, value)
+		return def
+	}
+	return n
+}
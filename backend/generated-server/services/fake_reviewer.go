@@ -0,0 +1,196 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// FakeModelName is reported as the model of reviews produced by FakeReviewer.
+const FakeModelName = "fake"
+
+// FakeReviewer is a deterministic Reviewer for offline development and tests.
+// The same input always produces the same review, and no network access is required.
+type FakeReviewer struct{}
+
+// GetAIReview returns a review derived from a hash of the inputs and the content length.
+// It covers every viewpoint of the input rubric, so it always passes validation.
+func (f *FakeReviewer) GetAIReview(ctx context.Context, input ReviewInput) (*ReviewResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	// Longer answers get a higher base score, capped so that the hash still matters.
+	base := 40 + min(utf8.RuneCountInString(input.Content)/20, 40)
+
+	viewpoints := input.Rubric.Viewpoints
+	response := &AIReviewResponse{
+		Scores:    make(map[string]int, len(viewpoints)),
+		Feedbacks: make([]FeedbackDetail, 0, len(viewpoints)),
+	}
+	for _, vp := range viewpoints {
+		score := base + fakeOffset(vp.Key, input.ThemeTitle, input.Content)
+		score = max(0, min(score, 100))
+		response.Scores[vp.Key] = score
+		response.Feedbacks = append(response.Feedbacks, FeedbackDetail{
+			Viewpoint: vp.Name,
+			Score:     score,
+			GoodPoint: fmt.Sprintf("（fake）%sについて一定の説明ができています。", vp.Name),
+			BadPoint:  fmt.Sprintf("（fake）%sをさらに伸ばすには、具体例を一つ追加してみましょう。", vp.Name),
+		})
+	}
+	response.TotalScore = input.Rubric.WeightedTotal(response.Scores)
+	if len(viewpoints) > 0 {
+		if start, end, ok := fakeFirstSentence(input.Content); ok {
+			response.Annotations = []Annotation{{
+				Start:      start,
+				End:        end,
+				Quote:      runeSlice(input.Content, start, end),
+				Viewpoint:  viewpoints[0].Name,
+				Severity:   AnnotationSeverityInfo,
+				Suggestion: "（fake）書き出しで結論を先に述べると、読み手が全体像を掴みやすくなります。",
+			}}
+		}
+	}
+
+	// A key point counts as covered when the content mentions its term, the part before any colon.
+	for _, kp := range input.KeyPoints {
+		term, _, _ := strings.Cut(kp, ":")
+		status := CoverageStatusMissed
+		if strings.Contains(strings.ToLower(input.Content), strings.ToLower(strings.TrimSpace(term))) {
+			status = CoverageStatusCovered
+		}
+		response.Coverage = append(response.Coverage, KeyPointCoverage{KeyPoint: kp, Status: status})
+	}
+
+	raw, err := json.Marshal(response)
+	if err != nil {
+		return nil, err
+	}
+	return &ReviewResult{Review: response, Raw: string(raw), Model: FakeModelName}, nil
+}
+
+// StreamAIReview reports each feedback of the deterministic review in order.
+func (f *FakeReviewer) StreamAIReview(ctx context.Context, input ReviewInput, onFeedback func(FeedbackDetail)) (*ReviewResult, error) {
+	result, err := f.GetAIReview(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	for _, feedback := range result.Review.Feedbacks {
+		onFeedback(feedback)
+	}
+	return result, nil
+}
+
+// Rewrite returns the original sentences one per line, with the first one
+// rephrased to lead with the conclusion and a closing sentence appended.
+func (f *FakeReviewer) Rewrite(ctx context.Context, input ReviewInput, review *AIReviewResponse) (*RewriteResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	sentences := SplitSentences(input.Content)
+	if len(sentences) > 0 {
+		sentences[0] = "結論から述べると、" + sentences[0]
+	}
+	sentences = append(sentences, "（fake）以上の理由から、この考え方が重要だと考えています。")
+	content := strings.Join(sentences, "\n")
+
+	raw, err := json.Marshal(aiRewriteResponse{Rewrite: content})
+	if err != nil {
+		return nil, err
+	}
+	return &RewriteResult{Content: content, Raw: string(raw), Model: FakeModelName}, nil
+}
+
+// fakeQuestions are asked in order by FakeReviewer.AskFollowUp.
+var fakeQuestions = []string{
+	"（fake）その説明の中で、実際にあなたが判断を下した場面を一つ具体的に教えてください。",
+	"（fake）その方法を選ばなかった場合、どのような問題が起きたと考えますか？",
+	"（fake）技術に詳しくない人に同じことを説明するとしたら、どう言い換えますか？",
+}
+
+// AskFollowUp returns the next of a fixed list of questions.
+func (f *FakeReviewer) AskFollowUp(ctx context.Context, input ReviewInput, turns []InterviewTurn) (*QuestionResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return &QuestionResult{Question: fakeQuestions[len(turns)%len(fakeQuestions)], Model: FakeModelName}, nil
+}
+
+// ReviewInterview reviews the writing with the answers appended, so longer answers score higher.
+func (f *FakeReviewer) ReviewInterview(ctx context.Context, input ReviewInput, turns []InterviewTurn) (*ReviewResult, error) {
+	answers := make([]string, 0, len(turns)+1)
+	answers = append(answers, input.Content)
+	for _, turn := range turns {
+		answers = append(answers, turn.Answer)
+	}
+	input.Content = strings.Join(answers, "\n")
+
+	result, err := f.GetAIReview(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	result.Review.Annotations = nil
+	raw, err := json.Marshal(result.Review)
+	if err != nil {
+		return nil, err
+	}
+	result.Raw = string(raw)
+	return result, nil
+}
+
+// CompareAttempts lists the viewpoints whose scores went up or down.
+func (f *FakeReviewer) CompareAttempts(ctx context.Context, input ReviewInput, before, after Attempt) (*ComparisonResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	summary := ComparisonSummary{Improved: []string{}, Regressed: []string{}}
+	for _, d := range ViewpointDeltas(before.Review, after.Review, input.Rubric) {
+		switch {
+		case d.Delta == nil:
+		case *d.Delta > 0:
+			summary.Improved = append(summary.Improved, fmt.Sprintf("（fake）%sのスコアが%d点上がりました。", d.Name, *d.Delta))
+		case *d.Delta < 0:
+			summary.Regressed = append(summary.Regressed, fmt.Sprintf("（fake）%sのスコアが%d点下がりました。", d.Name, -*d.Delta))
+		}
+	}
+	summary.Summary = fmt.Sprintf("（fake）%d個の観点でスコアが上がり、%d個の観点で下がりました。", len(summary.Improved), len(summary.Regressed))
+
+	raw, err := json.Marshal(summary)
+	if err != nil {
+		return nil, err
+	}
+	return &ComparisonResult{Summary: summary, Raw: string(raw), Model: FakeModelName}, nil
+}
+
+// fakeOffset returns a stable value in [-10, 10] for the given viewpoint and inputs.
+func fakeOffset(key, themeTitle, userContent string) int {
+	h := fnv.New32a()
+	// hash.Hash never returns an error from Write.
+	_, _ = h.Write([]byte(key + "\x00" + themeTitle + "\x00" + userContent))
+	return int(h.Sum32()%21) - 10
+}
+
+// fakeFirstSentence returns the rune range of the first sentence of content,
+// capped at 40 characters.
+func fakeFirstSentence(content string) (int, int, bool) {
+	runes := []rune(content)
+	start := 0
+	for start < len(runes) && unicode.IsSpace(runes[start]) {
+		start++
+	}
+	end := start
+	for end < len(runes) && end-start < 40 && runes[end] != '\n' {
+		end++
+		if runes[end-1] == '。' || runes[end-1] == '！' || runes[end-1] == '？' {
+			break
+		}
+	}
+	return start, end, end > start
+}
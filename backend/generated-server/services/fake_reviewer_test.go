@@ -0,0 +1,79 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func fakeInterviewInput() ReviewInput {
+	return ReviewInput{ThemeTitle: "ACIDとは", Content: "ACIDはトランザクションの性質です。", Rubric: DefaultRubric()}
+}
+
+func TestFakeReviewerAskFollowUp(t *testing.T) {
+	reviewer := &FakeReviewer{}
+	var turns []InterviewTurn
+	// The questions repeat once the list runs out.
+	for i := 0; i < len(fakeQuestions)+1; i++ {
+		result, err := reviewer.AskFollowUp(context.Background(), fakeInterviewInput(), turns)
+		if err != nil {
+			t.Fatalf("question %d: %v", i+1, err)
+		}
+		if want := fakeQuestions[i%len(fakeQuestions)]; result.Question != want {
+			t.Errorf("question %d = %q, want %q", i+1, result.Question, want)
+		}
+		if result.Model != FakeModelName {
+			t.Errorf("model = %q, want %q", result.Model, FakeModelName)
+		}
+		turns = append(turns, InterviewTurn{Question: result.Question, Answer: "回答"})
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := reviewer.AskFollowUp(ctx, fakeInterviewInput(), nil); !errors.Is(err, context.Canceled) {
+		t.Errorf("err = %v, want %v", err, context.Canceled)
+	}
+}
+
+func TestFakeReviewerReviewInterview(t *testing.T) {
+	reviewer := &FakeReviewer{}
+	input := fakeInterviewInput()
+	short := []InterviewTurn{{Question: "質問", Answer: "はい。"}}
+	long := []InterviewTurn{{Question: "質問", Answer: strings.Repeat("具体的には、障害時にロールバックで整合性を保ちました。", 20)}}
+
+	shortResult, err := reviewer.ReviewInterview(context.Background(), input, short)
+	if err != nil {
+		t.Fatal(err)
+	}
+	longResult, err := reviewer.ReviewInterview(context.Background(), input, long)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if longResult.Review.TotalScore <= shortResult.Review.TotalScore {
+		t.Errorf("total with a long answer = %d, want more than with a short one (%d)", longResult.Review.TotalScore, shortResult.Review.TotalScore)
+	}
+
+	// The wrap-up is a valid review without annotations, and Raw is that same review.
+	if problems := ValidateAIReview(longResult.Review, input.Rubric); len(problems) != 0 {
+		t.Errorf("problems = %q, want none", problems)
+	}
+	if longResult.Review.Annotations != nil {
+		t.Errorf("annotations = %+v, want none", longResult.Review.Annotations)
+	}
+	var raw AIReviewResponse
+	if err := json.Unmarshal([]byte(longResult.Raw), &raw); err != nil {
+		t.Fatalf("raw is not JSON: %v", err)
+	}
+	if !reflect.DeepEqual(&raw, longResult.Review) {
+		t.Errorf("raw = %+v, want the review %+v", raw, longResult.Review)
+	}
+
+	// The same exchange is always reviewed the same way.
+	again, _ := reviewer.ReviewInterview(context.Background(), input, short)
+	if !reflect.DeepEqual(again.Review, shortResult.Review) {
+		t.Errorf("second review = %+v, want %+v", again.Review, shortResult.Review)
+	}
+}
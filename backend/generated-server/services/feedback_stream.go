@@ -0,0 +1,99 @@
+package services
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+)
+
+// feedbacksArrayPattern finds the start of the "feedbacks" array in a review response.
+var feedbacksArrayPattern = regexp.MustCompile(`"feedbacks"\s*:\s*\[`)
+
+// feedbackStreamParser accumulates a streamed review response and emits each
+// element of the "feedbacks" array as soon as its closing brace arrives.
+type feedbackStreamParser struct {
+	buf        strings.Builder
+	onFeedback func(FeedbackDetail)
+
+	pos      int  // next byte of buf to scan
+	inArray  bool // the "feedbacks" array has been found
+	done     bool // the "feedbacks" array has been closed
+	depth    int  // object nesting depth inside the array
+	inString bool
+	escaped  bool
+	objStart int
+}
+
+func newFeedbackStreamParser(onFeedback func(FeedbackDetail)) *feedbackStreamParser {
+	return &feedbackStreamParser{onFeedback: onFeedback}
+}
+
+// Write appends a chunk of the response and emits any feedback completed by it.
+func (p *feedbackStreamParser) Write(chunk string) {
+	p.buf.WriteString(chunk)
+	if p.done {
+		return
+	}
+
+	content := p.buf.String()
+	if !p.inArray {
+		loc := feedbacksArrayPattern.FindStringIndex(content)
+		if loc == nil {
+			return
+		}
+		p.inArray = true
+		p.pos = loc[1]
+	}
+
+	for ; p.pos < len(content); p.pos++ {
+		ch := content[p.pos]
+		if p.inString {
+			switch {
+			case p.escaped:
+				p.escaped = false
+			case ch == '\\':
+				p.escaped = true
+			case ch == '"':
+				p.inString = false
+			}
+			continue
+		}
+
+		switch ch {
+		case '"':
+			p.inString = true
+		case '{':
+			if p.depth == 0 {
+				p.objStart = p.pos
+			}
+			p.depth++
+		case '}':
+			p.depth--
+			if p.depth == 0 {
+				p.emit(content[p.objStart : p.pos+1])
+			}
+		case ']':
+			if p.depth == 0 {
+				p.done = true
+				return
+			}
+		}
+	}
+}
+
+// String returns the full response received so far.
+func (p *feedbackStreamParser) String() string {
+	return p.buf.String()
+}
+
+// emit parses one feedback object. Malformed objects are skipped here and
+// surface later when the complete response is parsed.
+func (p *feedbackStreamParser) emit(object string) {
+	var feedback FeedbackDetail
+	if err := json.Unmarshal([]byte(object), &feedback); err != nil {
+		return
+	}
+	if p.onFeedback != nil {
+		p.onFeedback(feedback)
+	}
+}
@@ -0,0 +1,126 @@
+package services
+
+import (
+	"reflect"
+	"testing"
+)
+
+// streamFeedbacks feeds chunks to a parser and returns the feedbacks it emitted.
+func streamFeedbacks(chunks ...string) []FeedbackDetail {
+	feedbacks := []FeedbackDetail{}
+	parser := newFeedbackStreamParser(func(feedback FeedbackDetail) {
+		feedbacks = append(feedbacks, feedback)
+	})
+	for _, chunk := range chunks {
+		parser.Write(chunk)
+	}
+	return feedbacks
+}
+
+// streamResponse has strings with escapes, braces and brackets, and multibyte characters.
+const streamResponse = `{"summary": "要約 \"feedbacks\": [ではない", "feedbacks": [` +
+	`{"viewpoint": "structure", "score": 80, "goodPoint": "結論が先にある {\"a\": [1]}", "badPoint": "理由が\\弱い"},` +
+	` {"viewpoint": "vocabulary", "score": 70, "goodPoint": "用語が正確 🚀", "badPoint": "「}」が多い"}` +
+	`], "totalScore": 75}`
+
+var streamWant = []FeedbackDetail{
+	{Viewpoint: "structure", Score: 80, GoodPoint: `結論が先にある {"a": [1]}`, BadPoint: `理由が\弱い`},
+	{Viewpoint: "vocabulary", Score: 70, GoodPoint: "用語が正確 🚀", BadPoint: "「}」が多い"},
+}
+
+func TestFeedbackStreamParserEverySplit(t *testing.T) {
+	// Splitting at every byte puts a chunk boundary inside strings, escapes and
+	// multibyte characters.
+	for i := 0; i <= len(streamResponse); i++ {
+		got := streamFeedbacks(streamResponse[:i], streamResponse[i:])
+		if !reflect.DeepEqual(got, streamWant) {
+			t.Fatalf("split at %d: feedbacks = %+v, want %+v", i, got, streamWant)
+		}
+	}
+}
+
+func TestFeedbackStreamParserByteByByte(t *testing.T) {
+	chunks := make([]string, len(streamResponse))
+	for i := 0; i < len(streamResponse); i++ {
+		chunks[i] = streamResponse[i : i+1]
+	}
+	if got := streamFeedbacks(chunks...); !reflect.DeepEqual(got, streamWant) {
+		t.Errorf("feedbacks = %+v, want %+v", got, streamWant)
+	}
+}
+
+func TestFeedbackStreamParser(t *testing.T) {
+	structure := FeedbackDetail{Viewpoint: "structure", Score: 80, GoodPoint: "良い", BadPoint: "悪い"}
+	tests := []struct {
+		name   string
+		chunks []string
+		want   []FeedbackDetail
+	}{
+		{
+			name:   "code fence",
+			chunks: []string{"```json\n{\"feedbacks\": [", `{"viewpoint": "structure", "score": 80, "goodPoint": "良い", "badPoint": "悪い"}`, "]}\n```"},
+			want:   []FeedbackDetail{structure},
+		},
+		{
+			name:   "code fence inside a string",
+			chunks: []string{"{\"feedbacks\": [{\"viewpoint\": \"structure\", \"score\": 80, \"goodPoint\": \"```go\\n}\\n```\", \"badPoint\": \"悪い\"}]}"},
+			want:   []FeedbackDetail{{Viewpoint: "structure", Score: 80, GoodPoint: "```go\n}\n```", BadPoint: "悪い"}},
+		},
+		{
+			name:   "split escaped quote",
+			chunks: []string{`{"feedbacks": [{"viewpoint": "structure", "score": 80, "goodPoint": "\`, `"}\"", "badPoint": "悪い"}]}`},
+			want:   []FeedbackDetail{{Viewpoint: "structure", Score: 80, GoodPoint: `"}"`, BadPoint: "悪い"}},
+		},
+		{
+			name:   "split key",
+			chunks: []string{`{"feed`, `backs"`, ` :`, ` [{"viewpoint": "structure", "score": 80, "goodPoint": "良い", "badPoint": "悪い"}]}`},
+			want:   []FeedbackDetail{structure},
+		},
+		{
+			name:   "split multibyte character",
+			chunks: []string{`{"feedbacks": [{"viewpoint": "structure", "score": 80, "goodPoint": "良` + "\xe3\x81", "\x84\", \"badPoint\": \"悪い\"}]}"},
+			want:   []FeedbackDetail{{Viewpoint: "structure", Score: 80, GoodPoint: "良い", BadPoint: "悪い"}},
+		},
+		{
+			name:   "truncated inside the second feedback",
+			chunks: []string{`{"feedbacks": [{"viewpoint": "structure", "score": 80, "goodPoint": "良い", "badPoint": "悪い"}, {"viewpoint": "vocab`},
+			want:   []FeedbackDetail{structure},
+		},
+		{
+			name:   "truncated inside a string",
+			chunks: []string{`{"feedbacks": [{"viewpoint": "structure", "score": 80, "goodPoint": "}]`},
+			want:   []FeedbackDetail{},
+		},
+		{
+			name:   "truncated before the array",
+			chunks: []string{`{"summary": "途中`},
+			want:   []FeedbackDetail{},
+		},
+		{
+			name:   "malformed feedback is skipped",
+			chunks: []string{`{"feedbacks": [{"viewpoint": "structure", "score": "high"}, {"viewpoint": "structure", "score": 80, "goodPoint": "良い", "badPoint": "悪い"}]}`},
+			want:   []FeedbackDetail{structure},
+		},
+		{
+			name:   "objects after the array are ignored",
+			chunks: []string{`{"feedbacks": [], "annotations": [{"viewpoint": "structure", "score": 80}]}`},
+			want:   []FeedbackDetail{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := streamFeedbacks(tt.chunks...); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("feedbacks = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFeedbackStreamParserString(t *testing.T) {
+	parser := newFeedbackStreamParser(nil)
+	parser.Write(streamResponse[:10])
+	parser.Write(streamResponse[10:])
+	if got := parser.String(); got != streamResponse {
+		t.Errorf("String() = %q, want the full response", got)
+	}
+}
@@ -0,0 +1,273 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// HeuristicModelName is reported as the model of reviews produced by HeuristicReview.
+const HeuristicModelName = "heuristic"
+
+// longSentenceLength is the length in characters above which a sentence counts as long.
+const longSentenceLength = 80
+
+// TextMetrics are measurable signals of a writing, computed without an LLM.
+type TextMetrics struct {
+	Characters        int     `json:"characters"`
+	Sentences         int     `json:"sentences"`
+	Paragraphs        int     `json:"paragraphs"`
+	AvgSentenceLength float64 `json:"avgSentenceLength"`
+	MaxSentenceLength int     `json:"maxSentenceLength"`
+	// LongSentenceRatio is the share of sentences longer than 80 characters.
+	LongSentenceRatio float64 `json:"longSentenceRatio"`
+	// Script ratios are shares of the letters, ignoring punctuation and spaces.
+	KanjiRatio    float64 `json:"kanjiRatio"`
+	KatakanaRatio float64 `json:"katakanaRatio"`
+	HiraganaRatio float64 `json:"hiraganaRatio"`
+	LatinRatio    float64 `json:"latinRatio"`
+	// StructureMarkers lists the PREP/SDS parts signalled in the text:
+	// "conclusion", "reason", "example" and "summary".
+	StructureMarkers []string `json:"structureMarkers"`
+	Connectives      int      `json:"connectives"`
+	// TechTerms counts katakana words and Latin-script words, e.g. "トランザクション" or "API".
+	TechTerms       int     `json:"techTerms"`
+	TechTermDensity float64 `json:"techTermDensity"` // per 100 characters
+	// Provisional scores for the structure and vocabulary viewpoints.
+	StructureScore  int `json:"structureScore"`
+	VocabularyScore int `json:"vocabularyScore"`
+}
+
+// structureMarkers are cue phrases for the parts of PREP (Point, Reason, Example, Point)
+// and SDS (Summary, Details, Summary), in the order they usually appear.
+var structureMarkers = []struct {
+	name  string
+	label string
+	cues  []string
+}{
+	{"conclusion", "結論", []string{"結論", "要するに", "つまり", "端的に言うと", "一言で言うと"}},
+	{"reason", "理由", []string{"理由", "なぜなら", "というのも", "からです", "ためです"}},
+	{"example", "具体例", []string{"例えば", "たとえば", "具体的には", "具体例", "実際に"}},
+	{"summary", "まとめ", []string{"まとめ", "以上のこと", "以上から", "このように", "したがって"}},
+}
+
+// connectives are conjunctions that link sentences logically.
+var connectives = []string{
+	"しかし", "一方", "また", "さらに", "そのため", "したがって", "ただし", "つまり",
+	"なぜなら", "例えば", "まず", "次に", "最後に", "加えて", "その結果", "ところが",
+}
+
+// AnalyzeText computes TextMetrics for a writing.
+func AnalyzeText(content string) TextMetrics {
+	m := TextMetrics{StructureMarkers: []string{}}
+	content = normalizeReviewContent(content)
+	m.Characters = utf8.RuneCountInString(content)
+	if m.Characters == 0 {
+		return m
+	}
+
+	for _, paragraph := range strings.Split(content, "\n\n") {
+		if strings.TrimSpace(paragraph) != "" {
+			m.Paragraphs++
+		}
+	}
+
+	sentences := SplitSentences(content)
+	m.Sentences = len(sentences)
+	var totalLength, longSentences int
+	for _, s := range sentences {
+		length := utf8.RuneCountInString(s)
+		totalLength += length
+		m.MaxSentenceLength = max(m.MaxSentenceLength, length)
+		if length > longSentenceLength {
+			longSentences++
+		}
+	}
+	if m.Sentences > 0 {
+		m.AvgSentenceLength = float64(totalLength) / float64(m.Sentences)
+		m.LongSentenceRatio = float64(longSentences) / float64(m.Sentences)
+	}
+
+	var letters, kanji, katakana, hiragana, latin int
+	for _, r := range content {
+		switch {
+		case unicode.Is(unicode.Han, r):
+			kanji++
+		case unicode.Is(unicode.Katakana, r) || r == 'ー':
+			katakana++
+		case unicode.Is(unicode.Hiragana, r):
+			hiragana++
+		case r < utf8.RuneSelf && (unicode.IsLetter(r) || unicode.IsDigit(r)):
+			latin++
+		default:
+			continue
+		}
+		letters++
+	}
+	if letters > 0 {
+		m.KanjiRatio = float64(kanji) / float64(letters)
+		m.KatakanaRatio = float64(katakana) / float64(letters)
+		m.HiraganaRatio = float64(hiragana) / float64(letters)
+		m.LatinRatio = float64(latin) / float64(letters)
+	}
+
+	for _, marker := range structureMarkers {
+		for _, cue := range marker.cues {
+			if strings.Contains(content, cue) {
+				m.StructureMarkers = append(m.StructureMarkers, marker.name)
+				break
+			}
+		}
+	}
+	for _, c := range connectives {
+		m.Connectives += strings.Count(content, c)
+	}
+
+	m.TechTerms = countTechTerms(content)
+	m.TechTermDensity = float64(m.TechTerms) * 100 / float64(m.Characters)
+
+	m.StructureScore = structureScore(m)
+	m.VocabularyScore = vocabularyScore(m)
+	return m
+}
+
+// countTechTerms counts runs of three or more katakana and runs of two or more
+// Latin letters or digits, which in Japanese technical writing are mostly terms.
+func countTechTerms(content string) int {
+	count := 0
+	var katakanaRun, latinRun int
+	flush := func() {
+		if katakanaRun >= 3 {
+			count++
+		}
+		if latinRun >= 2 {
+			count++
+		}
+		katakanaRun, latinRun = 0, 0
+	}
+	for _, r := range content {
+		switch {
+		case unicode.Is(unicode.Katakana, r) || r == 'ー':
+			if latinRun > 0 {
+				flush()
+			}
+			katakanaRun++
+		case r < utf8.RuneSelf && (unicode.IsLetter(r) || unicode.IsDigit(r)):
+			if katakanaRun > 0 {
+				flush()
+			}
+			latinRun++
+		default:
+			flush()
+		}
+	}
+	flush()
+	return count
+}
+
+// structureScore rewards PREP/SDS cues, paragraphs and readable sentence lengths.
+func structureScore(m TextMetrics) int {
+	score := 20 + 10*len(m.StructureMarkers)
+	switch {
+	case m.Paragraphs >= 3:
+		score += 15
+	case m.Paragraphs == 2:
+		score += 10
+	}
+	if m.AvgSentenceLength >= 20 && m.AvgSentenceLength <= 60 {
+		score += 10
+	}
+	if m.LongSentenceRatio > 0.3 {
+		score -= 10
+	}
+	score += min(m.Connectives, 5)
+	return max(0, min(score, 100))
+}
+
+// vocabularyScore rewards a moderate density of technical terms and a balanced use of kanji.
+func vocabularyScore(m TextMetrics) int {
+	score := 40
+	switch {
+	case m.TechTermDensity >= 2 && m.TechTermDensity <= 8:
+		score += 30
+	case m.TechTermDensity > 8:
+		// Too dense reads like a list of buzzwords.
+		score += 20
+	default:
+		score += int(m.TechTermDensity * 15)
+	}
+	if m.KanjiRatio >= 0.2 && m.KanjiRatio <= 0.45 {
+		score += 15
+	} else if m.KanjiRatio > 0.45 {
+		score += 5
+	}
+	if m.Characters >= 200 {
+		score += 10
+	}
+	return max(0, min(score, 100))
+}
+
+// ErrNoHeuristicViewpoints is returned by HeuristicReview when the rubric has none of the
+// viewpoints it can score.
+var ErrNoHeuristicViewpoints = errors.New("rubric has no viewpoint the heuristic review can score")
+
+// HeuristicReview builds a provisional review of the structure and vocabulary
+// viewpoints from TextMetrics. It is used when the AI reviewer is unavailable.
+// Only the viewpoints the rubric has, by key or name, are scored, and the total is their
+// weighted mean; with neither, it fails with ErrNoHeuristicViewpoints. The review does
+// not cover the other viewpoints and is not validated against the rubric.
+func HeuristicReview(input ReviewInput) (*ReviewResult, error) {
+	structure, hasStructure := input.Rubric.Viewpoint("structure")
+	vocabulary, hasVocabulary := input.Rubric.Viewpoint("vocabulary")
+	if !hasStructure && !hasVocabulary {
+		return nil, ErrNoHeuristicViewpoints
+	}
+
+	m := AnalyzeText(input.Content)
+
+	var found, missing []string
+	for _, marker := range structureMarkers {
+		if slices.Contains(m.StructureMarkers, marker.name) {
+			found = append(found, marker.label)
+		} else {
+			missing = append(missing, marker.label)
+		}
+	}
+	structureGood := fmt.Sprintf("（自動分析）%d段落・%d文で構成されています。", m.Paragraphs, m.Sentences)
+	if len(found) > 0 {
+		structureGood += fmt.Sprintf("%sを示す表現が使われています。", strings.Join(found, "・"))
+	}
+	structureBad := "（自動分析）段落ごとに一つの話題に絞ると、構成がさらに明確になります。"
+	switch {
+	case m.LongSentenceRatio > 0.3:
+		structureBad = fmt.Sprintf("（自動分析）%d文字を超える長い文が多いため、文を分けると読みやすくなります。", longSentenceLength)
+	case len(missing) > 0:
+		structureBad = fmt.Sprintf("（自動分析）%sを示す表現（例：「結論として」「なぜなら」「例えば」「まとめると」）を加え、PREP法の流れを明示してみましょう。", strings.Join(missing, "・"))
+	}
+
+	vocabularyGood := fmt.Sprintf("（自動分析）技術用語と思われる語が%d個（100文字あたり%.1f個）使われています。", m.TechTerms, m.TechTermDensity)
+	vocabularyBad := "（自動分析）用語の定義や、初めて出てくる用語の説明を添えると伝わりやすくなります。"
+	if m.TechTermDensity < 2 {
+		vocabularyBad = "（自動分析）技術用語が少なめです。概念を正確な用語で表現してみましょう。"
+	}
+
+	response := &AIReviewResponse{Scores: map[string]int{}, Feedbacks: []FeedbackDetail{}}
+	if hasStructure {
+		response.Scores[structure.Key] = m.StructureScore
+		response.Feedbacks = append(response.Feedbacks, FeedbackDetail{Viewpoint: structure.Name, Score: m.StructureScore, GoodPoint: structureGood, BadPoint: structureBad})
+	}
+	if hasVocabulary {
+		response.Scores[vocabulary.Key] = m.VocabularyScore
+		response.Feedbacks = append(response.Feedbacks, FeedbackDetail{Viewpoint: vocabulary.Name, Score: m.VocabularyScore, GoodPoint: vocabularyGood, BadPoint: vocabularyBad})
+	}
+	response.TotalScore = input.Rubric.WeightedTotal(response.Scores)
+	raw, err := json.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+	return &ReviewResult{Review: response, Raw: string(raw), Model: HeuristicModelName}, nil
+}
@@ -0,0 +1,165 @@
+package services
+
+import (
+	"errors"
+	"maps"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestCountTechTerms(t *testing.T) {
+	tests := map[string]int{
+		"ダム":         0, // two katakana are too short for a term
+		"データ":        1,
+		"サーバー":       1, // the long vowel mark is part of the word
+		"A":          0,
+		"v2":         1,
+		"Goルーチン":     2, // a script change ends a term
+		"ＡＰＩ":        0, // full-width letters are not Latin script
+		"DBとAPIとSQL": 3,
+		"トランザクション、ロールバック。": 2,
+	}
+	for content, want := range tests {
+		if got := countTechTerms(content); got != want {
+			t.Errorf("countTechTerms(%q) = %d, want %d", content, got, want)
+		}
+	}
+}
+
+func TestStructureScoreBoundaries(t *testing.T) {
+	base := TextMetrics{Paragraphs: 1, AvgSentenceLength: 10}
+	score := func(modify func(m *TextMetrics)) int {
+		m := base
+		modify(&m)
+		return structureScore(m)
+	}
+	if got := score(func(m *TextMetrics) {}); got != 20 {
+		t.Fatalf("base score = %d, want 20", got)
+	}
+	checks := []struct {
+		name   string
+		modify func(m *TextMetrics)
+		want   int
+	}{
+		{"sentences averaging exactly 20", func(m *TextMetrics) { m.AvgSentenceLength = 20 }, 30},
+		{"sentences averaging exactly 60", func(m *TextMetrics) { m.AvgSentenceLength = 60 }, 30},
+		{"sentences averaging just over 60", func(m *TextMetrics) { m.AvgSentenceLength = 60.1 }, 20},
+		{"long sentences at exactly 30%", func(m *TextMetrics) { m.LongSentenceRatio = 0.3 }, 20},
+		{"long sentences just over 30%", func(m *TextMetrics) { m.LongSentenceRatio = 0.31 }, 10},
+		{"two paragraphs", func(m *TextMetrics) { m.Paragraphs = 2 }, 30},
+		{"many paragraphs count as three", func(m *TextMetrics) { m.Paragraphs = 12 }, 35},
+		{"connectives count up to five", func(m *TextMetrics) { m.Connectives = 40 }, 25},
+		{"every part of PREP", func(m *TextMetrics) {
+			m.StructureMarkers = []string{"conclusion", "reason", "example", "summary"}
+			m.Paragraphs = 4
+			m.AvgSentenceLength = 40
+			m.Connectives = 5
+		}, 90},
+	}
+	for _, c := range checks {
+		if got := score(c.modify); got != c.want {
+			t.Errorf("%s: score = %d, want %d", c.name, got, c.want)
+		}
+	}
+}
+
+func TestVocabularyScoreBoundaries(t *testing.T) {
+	for _, c := range []struct {
+		density, kanji float64
+		characters     int
+		want           int
+	}{
+		{0, 0, 0, 40},
+		{1, 0, 0, 55},     // sparse terms earn a partial bonus
+		{2, 0, 0, 70},     // the recommended density starts at 2
+		{8, 0, 0, 70},     // and ends at 8
+		{8.5, 0, 0, 60},   // denser reads like a list of buzzwords
+		{2, 0.2, 0, 85},   // balanced kanji
+		{2, 0.45, 0, 85},  // still balanced
+		{2, 0.5, 0, 75},   // kanji-heavy
+		{2, 0, 199, 70},   // short
+		{8, 0.3, 200, 95}, // the best case stays under 100
+	} {
+		m := TextMetrics{TechTermDensity: c.density, KanjiRatio: c.kanji, Characters: c.characters}
+		if got := vocabularyScore(m); got != c.want {
+			t.Errorf("density %v, kanji %v, %d characters: score = %d, want %d", c.density, c.kanji, c.characters, got, c.want)
+		}
+	}
+}
+
+func TestAnalyzeText(t *testing.T) {
+	// A paragraph of spaces between two paragraphs does not count, and CRLF line endings
+	// and trailing spaces are normalized away before anything is measured.
+	m := AnalyzeText("結論として、キャッシュは有効です。なぜなら、DBへのアクセスが減るからです。  \r\n\r\n   \r\n\r\n例えば、Redisを使います。\r\n")
+	if m.Paragraphs != 2 || m.Sentences != 3 {
+		t.Errorf("paragraphs, sentences = %d, %d, want 2, 3", m.Paragraphs, m.Sentences)
+	}
+	if want := []string{"conclusion", "reason", "example"}; !slices.Equal(m.StructureMarkers, want) {
+		t.Errorf("structure markers = %v, want %v", m.StructureMarkers, want)
+	}
+	if sum := m.KanjiRatio + m.KatakanaRatio + m.HiraganaRatio + m.LatinRatio; sum < 0.999 || sum > 1.001 {
+		t.Errorf("script ratios sum to %v, want 1", sum)
+	}
+
+	long := strings.Repeat("あ", longSentenceLength) + "。"
+	m = AnalyzeText(long + long + "短い文。")
+	if m.MaxSentenceLength != longSentenceLength+1 || m.LongSentenceRatio < 0.66 || m.LongSentenceRatio > 0.67 {
+		t.Errorf("max length, long ratio = %d, %v, want %d, 2/3", m.MaxSentenceLength, m.LongSentenceRatio, longSentenceLength+1)
+	}
+
+	// Whitespace alone has no metrics, but still lists no markers rather than null.
+	if m := AnalyzeText(" \r\n\r\n\t"); m.Characters != 0 || m.StructureScore != 0 || m.StructureMarkers == nil {
+		t.Errorf("metrics of whitespace = %+v, want zero with an empty marker list", m)
+	}
+}
+
+func TestHeuristicReview(t *testing.T) {
+	content := "結論として、キャッシュは有効です。\n\nなぜなら、DBへのアクセスが減るからです。"
+	m := AnalyzeText(content)
+
+	// Viewpoints are found by key, but reported by the rubric's own name and weighted by it.
+	rubric := Rubric{Slug: "weighted", Version: 1, Viewpoints: []RubricViewpoint{
+		{Key: "vocabulary", Name: "用語", Weight: 1},
+		{Key: "accuracy", Name: "正確性", Weight: 5},
+		{Key: "structure", Name: "論理構成", Weight: 3},
+	}}
+	result, err := HeuristicReview(ReviewInput{Content: content, Rubric: rubric})
+	if err != nil {
+		t.Fatal(err)
+	}
+	review := result.Review
+	if want := map[string]int{"structure": m.StructureScore, "vocabulary": m.VocabularyScore}; !maps.Equal(review.Scores, want) {
+		t.Errorf("scores = %v, want %v", review.Scores, want)
+	}
+	if want := rubric.WeightedTotal(review.Scores); review.TotalScore != want {
+		t.Errorf("total = %d, want %d", review.TotalScore, want)
+	}
+	if len(review.Feedbacks) != 2 || review.Feedbacks[0].Viewpoint != "論理構成" || review.Feedbacks[1].Viewpoint != "用語" {
+		t.Fatalf("feedbacks = %+v, want structure then vocabulary under the rubric's names", review.Feedbacks)
+	}
+	// The missing parts of PREP are named.
+	if bad := review.Feedbacks[0].BadPoint; !strings.Contains(bad, "具体例・まとめ") {
+		t.Errorf("structure bad point = %q, want it to name the missing example and summary", bad)
+	}
+	if result.Model != HeuristicModelName {
+		t.Errorf("model = %q, want %q", result.Model, HeuristicModelName)
+	}
+
+	// Long sentences are the more pressing problem.
+	long := strings.Repeat("キャッシュは有効", 12) + "。"
+	result, _ = HeuristicReview(ReviewInput{Content: long, Rubric: DefaultRubric()})
+	if bad := result.Review.Feedbacks[0].BadPoint; !strings.Contains(bad, "長い文") {
+		t.Errorf("structure bad point = %q, want advice on long sentences", bad)
+	}
+}
+
+func TestHeuristicReviewWithoutScorableViewpoints(t *testing.T) {
+	rubric := Rubric{Slug: "star", Version: 1, Viewpoints: []RubricViewpoint{
+		{Key: "situation", Name: "状況", Weight: 1},
+		{Key: "result", Name: "結果", Weight: 1},
+	}}
+	if _, err := HeuristicReview(ReviewInput{Content: "キャッシュは速い。", Rubric: rubric}); !errors.Is(err, ErrNoHeuristicViewpoints) {
+		t.Errorf("err = %v, want %v", err, ErrNoHeuristicViewpoints)
+	}
+}
@@ -0,0 +1,153 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	openai "github.com/sashabaranov/go-openai"
+)
+
+// InterviewTurn is one question of a mock interview and the user's answer to it.
+// Answer is empty while the question is still open.
+type InterviewTurn struct {
+	Question string
+	Answer   string
+}
+
+// QuestionResult is an interviewer question together with metadata about how it was produced.
+type QuestionResult struct {
+	Question string
+	Model    string
+	Usage    TokenUsage
+}
+
+// aiQuestionResponse defines the structure for the JSON response from the AI.
+type aiQuestionResponse struct {
+	Question string `json:"question"`
+}
+
+// AskFollowUp asks the model, acting as an interviewer, for the next question
+// about the writing. turns holds the questions asked so far, all answered.
+func (s *OpenAIService) AskFollowUp(ctx context.Context, input ReviewInput, turns []InterviewTurn) (*QuestionResult, error) {
+	messages := []openai.ChatCompletionMessage{
+		{Role: openai.ChatMessageRoleSystem, Content: interviewerSystemPrompt + untrustedContentNotice},
+		{Role: openai.ChatMessageRoleUser, Content: buildInterviewOpening(input)},
+	}
+	for _, turn := range turns {
+		question, err := json.Marshal(aiQuestionResponse{Question: turn.Question})
+		if err != nil {
+			return nil, err
+		}
+		messages = append(messages,
+			openai.ChatCompletionMessage{Role: openai.ChatMessageRoleAssistant, Content: string(question)},
+			openai.ChatCompletionMessage{Role: openai.ChatMessageRoleUser, Content: fenceUserContent(turn.Answer)},
+		)
+	}
+
+	request := openai.ChatCompletionRequest{Model: s.Model, Messages: messages}
+	if s.JSONMode {
+		request.ResponseFormat = &openai.ChatCompletionResponseFormat{
+			Type: openai.ChatCompletionResponseFormatTypeJSONObject,
+		}
+	}
+
+	result := &QuestionResult{Model: s.Model}
+	content, err := s.complete(ctx, request, &result.Model, &result.Usage)
+	if err != nil {
+		return nil, withUsage(err, result.Model, result.Usage)
+	}
+
+	var parsed aiQuestionResponse
+	if err := json.Unmarshal([]byte(extractJSONObject(content)), &parsed); err != nil {
+		return nil, withUsage(fmt.Errorf("%w: question is not valid JSON: %v", ErrInvalidAIResponse, err), result.Model, result.Usage)
+	}
+	if strings.TrimSpace(parsed.Question) == "" {
+		return nil, withUsage(fmt.Errorf("%w: question is empty", ErrInvalidAIResponse), result.Model, result.Usage)
+	}
+	result.Question = strings.TrimSpace(parsed.Question)
+	return result, nil
+}
+
+// ReviewInterview scores the writing together with the interview that followed it.
+// The result is validated against the rubric in the same way as GetAIReview.
+func (s *OpenAIService) ReviewInterview(ctx context.Context, input ReviewInput, turns []InterviewTurn) (*ReviewResult, error) {
+	request := openai.ChatCompletionRequest{
+		Model: s.Model,
+		Messages: []openai.ChatCompletionMessage{
+			{Role: openai.ChatMessageRoleSystem, Content: buildReviewSystemPrompt(input.Rubric) + buildCoveragePrompt(input) + interviewReviewAddendum},
+			{Role: openai.ChatMessageRoleUser, Content: buildInterviewTranscript(input, turns)},
+		},
+	}
+	if s.JSONMode {
+		request.ResponseFormat = &openai.ChatCompletionResponseFormat{
+			Type: openai.ChatCompletionResponseFormatTypeJSONObject,
+		}
+	}
+	result := &ReviewResult{Model: s.Model}
+
+	content, err := s.complete(ctx, request, &result.Model, &result.Usage)
+	if err != nil {
+		return nil, withUsage(err, result.Model, result.Usage)
+	}
+
+	return s.validateAndRepair(ctx, request, content, input, result)
+}
+
+const interviewerSystemPrompt = `
+あなたは、Webエンジニアの技術面接を担当する経験豊富な面接官です。
+候補者が書いた説明文を読み、面接官として深掘りの質問を1つずつ行ってください。
+
+## 質問のルール
+- 説明の曖昧な点、根拠が示されていない主張、実務での経験や判断理由を掘り下げてください。
+- 1回につき質問は1つだけにし、候補者の直前の回答を踏まえて次の質問を考えてください。
+- 既に答えられた内容を繰り返し質問しないでください。
+
+## 出力形式 (JSON)
+{
+  "question": "<次の質問>"
+}
+`
+
+// interviewReviewAddendum extends the review system prompt for the wrap-up review.
+const interviewReviewAddendum = `
+## 面接の総評について
+今回は文章に加えて、その後の面接官とのやり取りも含めて評価してください。
+特に、質問の意図を汲み取って的確に答えられているか、文章で不足していた点を補えているかを各観点のスコアとフィードバックに反映してください。
+annotations は空配列にしてください。
+`
+
+// buildInterviewOpening renders the theme and writing the interview is about.
+func buildInterviewOpening(input ReviewInput) string {
+	theme := input.themeText()
+	return fmt.Sprintf(`
+以下のテーマについて、候補者が次の説明文を書きました。最初の質問をしてください。
+
+## テーマ
+%s
+
+## 説明文
+%s
+`, theme, fenceUserContent(input.Content))
+}
+
+// buildInterviewTranscript renders the writing and the interview for the wrap-up review.
+func buildInterviewTranscript(input ReviewInput, turns []InterviewTurn) string {
+	var transcript strings.Builder
+	for i, turn := range turns {
+		fmt.Fprintf(&transcript, "### 質問%d\n%s\n\n### 回答%d\n%s\n\n", i+1, turn.Question, i+1, fenceUserContent(turn.Answer))
+	}
+	theme := input.themeText()
+	return fmt.Sprintf(`
+以下のテーマについて書かれた文章と、その後の面接でのやり取りをレビューしてください。
+
+## テーマ
+%s
+
+## 文章
+%s
+
+## 面接でのやり取り
+%s`, theme, fenceUserContent(input.Content), transcript.String())
+}
@@ -3,7 +3,10 @@ package services
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"strings"
 
 	openai "github.com/sashabaranov/go-openai"
 )
@@ -16,54 +19,234 @@ type FeedbackDetail struct {
 	BadPoint  string `json:"badPoint"`
 }
 
+// ReviewInput is everything a Reviewer needs to review one writing.
+type ReviewInput struct {
+	ThemeTitle       string
+	ThemeDescription string
+	Content          string
+	Rubric           Rubric
+	// KeyPoints and Misconceptions come from the theme and may be empty.
+	KeyPoints      []string
+	Misconceptions []string
+}
+
+// themeText renders the theme title followed by its description, if any.
+func (input ReviewInput) themeText() string {
+	if input.ThemeDescription == "" {
+		return input.ThemeTitle
+	}
+	return input.ThemeTitle + "\n\n" + input.ThemeDescription
+}
+
+// TokenUsage counts the tokens consumed by one or more model calls.
+type TokenUsage struct {
+	PromptTokens     int
+	CompletionTokens int
+	TotalTokens      int
+}
+
+// add accumulates the usage reported by the OpenAI API.
+func (u *TokenUsage) add(usage openai.Usage) {
+	u.PromptTokens += usage.PromptTokens
+	u.CompletionTokens += usage.CompletionTokens
+	u.TotalTokens += usage.TotalTokens
+}
+
+// addTokens accumulates the usage of other calls.
+func (u *TokenUsage) addTokens(other TokenUsage) {
+	u.PromptTokens += other.PromptTokens
+	u.CompletionTokens += other.CompletionTokens
+	u.TotalTokens += other.TotalTokens
+}
+
+// UsageError wraps the error of a call that failed after tokens were already spent, such as
+// a review that still fails validation after its repair attempts, so the tokens can be charged.
+type UsageError struct {
+	Err   error
+	Model string
+	Usage TokenUsage
+}
+
+func (e *UsageError) Error() string { return e.Err.Error() }
+
+func (e *UsageError) Unwrap() error { return e.Err }
+
+// SpentUsage returns the tokens spent by a failed call and the model that spent them.
+// It returns false if err carries no usage.
+func SpentUsage(err error) (TokenUsage, string, bool) {
+	var usageErr *UsageError
+	if !errors.As(err, &usageErr) {
+		return TokenUsage{}, "", false
+	}
+	return usageErr.Usage, usageErr.Model, true
+}
+
+// withUsage wraps err in a UsageError, unless no tokens were spent.
+func withUsage(err error, model string, usage TokenUsage) error {
+	if usage.TotalTokens == 0 && usage.PromptTokens == 0 && usage.CompletionTokens == 0 {
+		return err
+	}
+	return &UsageError{Err: err, Model: model, Usage: usage}
+}
+
+// ReviewResult is a validated review together with metadata about how it was produced.
+type ReviewResult struct {
+	Review *AIReviewResponse
+	// Raw is the unparsed model output that was accepted.
+	Raw string
+	// Model is the name of the model that produced the review.
+	Model string
+	// Usage sums the tokens of all calls, including repair attempts.
+	Usage TokenUsage
+}
+
 // AIReviewResponse defines the structure for the JSON response from the AI.
 type AIReviewResponse struct {
 	TotalScore int              `json:"totalScore"`
 	Scores     map[string]int   `json:"scores"`
 	Feedbacks  []FeedbackDetail `json:"feedbacks"`
+	// Annotations point at the sentences the feedback is about.
+	Annotations []Annotation `json:"annotations,omitempty"`
+	// Coverage reports each of the theme's key points. Empty when the theme has none.
+	Coverage []KeyPointCoverage `json:"coverage,omitempty"`
 }
 
-// OpenAIService handles interactions with the OpenAI API.
+// OpenAIService handles interactions with the OpenAI API or any server that
+// implements the same chat completion API.
 type OpenAIService struct {
 	Client *openai.Client
+	Model  string
+	// JSONMode requests a JSON object response via response_format.
+	// Disable it for OpenAI-compatible servers that do not support the option.
+	JSONMode bool
+	// MaxRepairAttempts is how many times an invalid response is sent back
+	// to the model for repair before giving up.
+	MaxRepairAttempts int
 }
 
+// DefaultMaxRepairAttempts is the number of repair attempts used by the constructors.
+const DefaultMaxRepairAttempts = 2
+
 // GetAIReview sends the user's writing to the OpenAI API and gets structured feedback.
-func (s *OpenAIService) GetAIReview(ctx context.Context, themeTitle, userContent string) (*AIReviewResponse, error) {
-	systemPrompt := `
-あなたは、ユーザーが技術的な事柄を言語化する能力を向上させるための、世界クラスのソフトウェアエンジニアリングコーチです。
-ユーザーが入力した文章をレビューし、以下の5つの観点で評価してください。
-評価結果は必ず下記のJSON形式で返却してください。各観点のスコアと全体スコアは0-100の整数値です。フィードバックは具体的で、学習者が次何をすべきかわかるように記述してください。
-
-## 評価観点
-1. 観察・内省力：自身の経験や、その時の思考プロセスを深く掘り下げて語れているか？
-2. 具体⇄抽象力：技術的な概念と、それを説明するための具体的な実例のバランスは取れているか？
-3. 語彙・用語力：技術用語や比喩を正確かつ効果的に使えているか？
-4. 構造化力：PREP法やSDS法など、論理的で分かりやすい構造で文章が展開されているか？
-5. 他者視点力：読み手（例：面接官）が持つであろう疑問を予測し、それに答える形で説明できているか？
-
-## 出力形式 (JSON)
-{
-  "totalScore": <0-100の全体スコア>,
-  "scores": {
-    "observation": <観察・内省力のスコア>,
-    "abstraction": <具体⇄抽象力のスコア>,
-    "vocabulary": <語彙・用語力のスコア>,
-    "structure": <構造化力のスコア>,
-    "perspective": <他者視点力のスコア>
-  },
-  "feedbacks": [
-    {
-      "viewpoint": "観察・内省力",
-      "score": <スコア>,
-      "goodPoint": "<良かった点>",
-      "badPoint": "<改善点>"
-    },
-    // ... 他の4観点も同様に記述
-  ]
-}
-`
+// Responses that fail validation are sent back to the model with a repair prompt.
+func (s *OpenAIService) GetAIReview(ctx context.Context, input ReviewInput) (*ReviewResult, error) {
+	request := s.newReviewRequest(input)
+	result := &ReviewResult{Model: s.Model}
+
+	content, err := s.complete(ctx, request, &result.Model, &result.Usage)
+	if err != nil {
+		return nil, withUsage(err, result.Model, result.Usage)
+	}
+
+	return s.validateAndRepair(ctx, request, content, input, result)
+}
+
+// StreamAIReview behaves like GetAIReview but uses the streaming API.
+// onFeedback is called with each viewpoint's feedback as soon as it can be parsed
+// from the partial response. The complete review is returned at the end.
+func (s *OpenAIService) StreamAIReview(ctx context.Context, input ReviewInput, onFeedback func(FeedbackDetail)) (*ReviewResult, error) {
+	request := s.newReviewRequest(input)
+	request.StreamOptions = &openai.StreamOptions{IncludeUsage: true}
+	result := &ReviewResult{Model: s.Model}
 
+	stream, err := s.Client.CreateChatCompletionStream(ctx, request)
+	if err != nil {
+		return nil, fmt.Errorf("OpenAI API request failed: %w", err)
+	}
+	defer stream.Close()
+
+	parser := newFeedbackStreamParser(onFeedback)
+	for {
+		chunk, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, withUsage(fmt.Errorf("OpenAI stream failed: %w", err), result.Model, result.Usage)
+		}
+		if chunk.Model != "" {
+			result.Model = chunk.Model
+		}
+		// Only the last chunk carries usage, and only when the server supports stream_options.
+		if chunk.Usage != nil {
+			result.Usage.add(*chunk.Usage)
+		}
+		if len(chunk.Choices) > 0 {
+			parser.Write(chunk.Choices[0].Delta.Content)
+		}
+	}
+
+	// Repair attempts are not streamed.
+	request.StreamOptions = nil
+	return s.validateAndRepair(ctx, request, parser.String(), input, result)
+}
+
+// complete sends a chat completion request and returns the content of the first choice.
+// The name of the model that answered is stored in model, and the tokens are added to usage.
+func (s *OpenAIService) complete(ctx context.Context, request openai.ChatCompletionRequest, model *string, usage *TokenUsage) (string, error) {
+	resp, err := s.Client.CreateChatCompletion(ctx, request)
+	if err != nil {
+		return "", fmt.Errorf("OpenAI API request failed: %w", err)
+	}
+	if resp.Model != "" {
+		*model = resp.Model
+	}
+	usage.add(resp.Usage)
+	if len(resp.Choices) == 0 {
+		return "", fmt.Errorf("%w: response contained no choices", ErrInvalidAIResponse)
+	}
+	return resp.Choices[0].Message.Content, nil
+}
+
+// validateAndRepair parses and validates a review response. If it is invalid, the
+// conversation is continued with a repair prompt up to MaxRepairAttempts times.
+// Errors carry the tokens spent so far in a UsageError.
+func (s *OpenAIService) validateAndRepair(ctx context.Context, request openai.ChatCompletionRequest, content string, input ReviewInput, result *ReviewResult) (*ReviewResult, error) {
+	for attempt := 1; ; attempt++ {
+		review, problems := ParseAIReview(content, input)
+		if len(problems) == 0 {
+			result.Review = review
+			result.Raw = content
+			return result, nil
+		}
+		if attempt > s.MaxRepairAttempts {
+			return nil, withUsage(&InvalidReviewError{Attempts: attempt, Problems: problems}, result.Model, result.Usage)
+		}
+
+		request.Messages = append(request.Messages,
+			openai.ChatCompletionMessage{Role: openai.ChatMessageRoleAssistant, Content: content},
+			openai.ChatCompletionMessage{Role: openai.ChatMessageRoleUser, Content: repairPrompt(problems)},
+		)
+		var err error
+		content, err = s.complete(ctx, request, &result.Model, &result.Usage)
+		if err != nil {
+			return nil, withUsage(err, result.Model, result.Usage)
+		}
+	}
+}
+
+// ParseAIReview unmarshals a review response and validates it against the rubric
+// and, for annotations, the reviewed content. The evaluation harness uses it to
+// check the raw output of any Reviewer the same way the OpenAI reviewer does.
+func ParseAIReview(content string, input ReviewInput) (*AIReviewResponse, []string) {
+	var review AIReviewResponse
+	if err := json.Unmarshal([]byte(extractJSONObject(content)), &review); err != nil {
+		return nil, []string{fmt.Sprintf("response is not valid JSON: %v", err)}
+	}
+	alignAnnotations(review.Annotations, input.Content)
+	problems := ValidateAIReview(&review, input.Rubric)
+	problems = append(problems, ValidateAnnotations(review.Annotations, input.Content, input.Rubric)...)
+	if len(input.KeyPoints) > 0 {
+		problems = append(problems, ValidateCoverage(review.Coverage, input.KeyPoints)...)
+	} else {
+		review.Coverage = nil
+	}
+	return &review, problems
+}
+
+// newReviewRequest builds the chat completion request for reviewing a writing.
+func (s *OpenAIService) newReviewRequest(input ReviewInput) openai.ChatCompletionRequest {
+	theme := input.themeText()
 	userPrompt := fmt.Sprintf(`
 以下のテーマについて、下記の文章をレビューしてください。
 
@@ -72,30 +255,31 @@ func (s *OpenAIService) GetAIReview(ctx context.Context, themeTitle, userContent
 
 ## 文章
 %s
-`, themeTitle, userContent)
-
-	resp, err := s.Client.CreateChatCompletion(
-		ctx,
-		openai.ChatCompletionRequest{
-			Model: openai.GPT4o,
-			ResponseFormat: &openai.ChatCompletionResponseFormat{
-				Type: openai.ChatCompletionResponseFormatTypeJSONObject,
-			},
-			Messages: []openai.ChatCompletionMessage{
-				{Role: openai.ChatMessageRoleSystem, Content: systemPrompt},
-				{Role: openai.ChatMessageRoleUser, Content: userPrompt},
-			},
-		},
-	)
+`, theme, fenceUserContent(input.Content))
 
-	if err != nil {
-		return nil, fmt.Errorf("OpenAI API request failed: %w", err)
+	request := openai.ChatCompletionRequest{
+		Model: s.Model,
+		Messages: []openai.ChatCompletionMessage{
+			{Role: openai.ChatMessageRoleSystem, Content: buildReviewSystemPrompt(input.Rubric) + buildCoveragePrompt(input)},
+			{Role: openai.ChatMessageRoleUser, Content: userPrompt},
+		},
 	}
-
-	var reviewResponse AIReviewResponse
-	if err := json.Unmarshal([]byte(resp.Choices[0].Message.Content), &reviewResponse); err != nil {
-		return nil, fmt.Errorf("failed to parse AI response: %w", err)
+	if s.JSONMode {
+		request.ResponseFormat = &openai.ChatCompletionResponseFormat{
+			Type: openai.ChatCompletionResponseFormatTypeJSONObject,
+		}
 	}
 
-	return &reviewResponse, nil
+	return request
+}
+
+// extractJSONObject trims any text around the outermost JSON object.
+// Models without JSON mode often wrap their answer in a markdown code fence.
+func extractJSONObject(content string) string {
+	start := strings.Index(content, "{")
+	end := strings.LastIndex(content, "}")
+	if start < 0 || end < start {
+		return content
+	}
+	return content[start : end+1]
 }
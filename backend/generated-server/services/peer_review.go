@@ -0,0 +1,29 @@
+package services
+
+import (
+	"strings"
+)
+
+// BuildPeerReview turns the feedback a user wrote about someone else's writing into a review
+// on the same rubric as the AI review: viewpoints are normalized to their rubric names, scores
+// are keyed by viewpoint and the total is weighted by the rubric. It returns the problems
+// found by ValidateAIReview; an empty list means the peer review is valid.
+func BuildPeerReview(feedbacks []FeedbackDetail, rubric Rubric) (*AIReviewResponse, []string) {
+	review := &AIReviewResponse{
+		Scores:    make(map[string]int, len(feedbacks)),
+		Feedbacks: make([]FeedbackDetail, len(feedbacks)),
+	}
+	for i, feedback := range feedbacks {
+		feedback.GoodPoint = strings.TrimSpace(feedback.GoodPoint)
+		feedback.BadPoint = strings.TrimSpace(feedback.BadPoint)
+		if vp, ok := rubric.Viewpoint(feedback.Viewpoint); ok {
+			feedback.Viewpoint = vp.Name
+			if _, duplicate := review.Scores[vp.Key]; !duplicate {
+				review.Scores[vp.Key] = feedback.Score
+			}
+		}
+		review.Feedbacks[i] = feedback
+	}
+	review.TotalScore = rubric.WeightedTotal(review.Scores)
+	return review, ValidateAIReview(review, rubric)
+}
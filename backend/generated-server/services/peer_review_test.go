@@ -0,0 +1,79 @@
+package services
+
+import (
+	"maps"
+	"slices"
+	"testing"
+)
+
+// peerRubric weighs structure three times as much as vocabulary.
+var peerRubric = Rubric{Slug: "peer", Version: 2, Viewpoints: []RubricViewpoint{
+	{Key: "structure", Name: "論理構成", Weight: 3},
+	{Key: "vocabulary", Name: "用語", Weight: 1},
+}}
+
+func TestBuildPeerReview(t *testing.T) {
+	// Viewpoints may be given by key or by name, and the points are trimmed.
+	review, problems := BuildPeerReview([]FeedbackDetail{
+		{Viewpoint: "用語", Score: 40, GoodPoint: " 正確 ", BadPoint: "\n少ない\n"},
+		{Viewpoint: "structure", Score: 80, GoodPoint: "結論が先", BadPoint: "例がない"},
+	}, peerRubric)
+	if len(problems) != 0 {
+		t.Fatalf("problems = %q, want none", problems)
+	}
+	if want := map[string]int{"structure": 80, "vocabulary": 40}; !maps.Equal(review.Scores, want) {
+		t.Errorf("scores = %v, want %v", review.Scores, want)
+	}
+	if review.TotalScore != 70 {
+		t.Errorf("total = %d, want the weighted mean 70", review.TotalScore)
+	}
+	want := []FeedbackDetail{
+		{Viewpoint: "用語", Score: 40, GoodPoint: "正確", BadPoint: "少ない"},
+		{Viewpoint: "論理構成", Score: 80, GoodPoint: "結論が先", BadPoint: "例がない"},
+	}
+	if !slices.Equal(review.Feedbacks, want) {
+		t.Errorf("feedbacks = %+v, want %+v in the given order", review.Feedbacks, want)
+	}
+}
+
+func TestBuildPeerReviewDuplicateViewpoint(t *testing.T) {
+	// The first score of a viewpoint counts; the second feedback is only reported.
+	review, problems := BuildPeerReview([]FeedbackDetail{
+		{Viewpoint: "structure", Score: 80, GoodPoint: "良い", BadPoint: "悪い"},
+		{Viewpoint: "vocabulary", Score: 60, GoodPoint: "良い", BadPoint: "悪い"},
+		{Viewpoint: "論理構成", Score: 20, GoodPoint: "良い", BadPoint: "悪い"},
+	}, peerRubric)
+	if review.Scores["structure"] != 80 || review.TotalScore != 75 {
+		t.Errorf("structure score, total = %d, %d, want 80, 75", review.Scores["structure"], review.TotalScore)
+	}
+	if want := []string{`feedbacks contains "論理構成" more than once`}; !slices.Equal(problems, want) {
+		t.Errorf("problems = %q, want %q", problems, want)
+	}
+}
+
+func TestBuildPeerReviewProblems(t *testing.T) {
+	review, problems := BuildPeerReview([]FeedbackDetail{
+		{Viewpoint: "humor", Score: 50, GoodPoint: "良い", BadPoint: "悪い"},
+		{Viewpoint: "structure", Score: 101, GoodPoint: "　", BadPoint: "悪い"},
+	}, peerRubric)
+	// An unknown viewpoint is kept as written, without a score.
+	if review.Feedbacks[0].Viewpoint != "humor" || len(review.Scores) != 1 {
+		t.Errorf("feedbacks, scores = %+v, %v, want humor kept without a score", review.Feedbacks, review.Scores)
+	}
+	want := []string{
+		"scores.structure must be between 0 and 100, got 101",
+		"scores.vocabulary is missing",
+		"totalScore must be between 0 and 100, got 101",
+		`feedbacks[0].viewpoint "humor" is not a rubric viewpoint`,
+		"feedbacks[1].score must be between 0 and 100, got 101",
+		"feedbacks[1] must have both goodPoint and badPoint",
+		`feedbacks is missing "用語"`,
+	}
+	if !slices.Equal(problems, want) {
+		t.Errorf("problems = %q, want %q", problems, want)
+	}
+
+	if _, problems := BuildPeerReview(nil, peerRubric); len(problems) != 4 {
+		t.Errorf("problems of an empty review = %q, want every score and feedback missing", problems)
+	}
+}
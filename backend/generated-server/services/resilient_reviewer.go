@@ -0,0 +1,273 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"time"
+
+	openai "github.com/sashabaranov/go-openai"
+)
+
+// ResilienceConfig controls the timeouts, retries and circuit breaker of a ResilientReviewer.
+type ResilienceConfig struct {
+	// CallTimeout limits each attempt. 0 leaves only the caller's deadline.
+	CallTimeout time.Duration
+	// MaxRetries is how many times a retryable failure is retried after the first attempt.
+	MaxRetries int
+	// BaseDelay is the backoff before the first retry; it doubles up to MaxDelay.
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+	// FailureThreshold is the number of consecutive failures that opens the circuit breaker.
+	// 0 disables the breaker.
+	FailureThreshold int
+	// OpenDuration is how long the breaker fails fast before letting a trial call through.
+	OpenDuration time.Duration
+}
+
+// DefaultResilienceConfig returns the settings used when no environment variable overrides them.
+func DefaultResilienceConfig() ResilienceConfig {
+	return ResilienceConfig{
+		CallTimeout:      60 * time.Second,
+		MaxRetries:       3,
+		BaseDelay:        500 * time.Millisecond,
+		MaxDelay:         10 * time.Second,
+		FailureThreshold: 5,
+		OpenDuration:     30 * time.Second,
+	}
+}
+
+// LoadResilienceConfig reads AI_CALL_TIMEOUT_SECONDS, AI_MAX_RETRIES, AI_RETRY_BASE_DELAY_MS,
+// AI_RETRY_MAX_DELAY_MS, AI_CIRCUIT_FAILURE_THRESHOLD and AI_CIRCUIT_OPEN_SECONDS, falling
+// back to the defaults.
+func LoadResilienceConfig() ResilienceConfig {
+	cfg := DefaultResilienceConfig()
+	cfg.CallTimeout = time.Duration(EnvNonNegativeInt("AI_CALL_TIMEOUT_SECONDS", int(cfg.CallTimeout/time.Second))) * time.Second
+	cfg.MaxRetries = EnvNonNegativeInt("AI_MAX_RETRIES", cfg.MaxRetries)
+	cfg.BaseDelay = time.Duration(EnvNonNegativeInt("AI_RETRY_BASE_DELAY_MS", int(cfg.BaseDelay/time.Millisecond))) * time.Millisecond
+	cfg.MaxDelay = time.Duration(EnvNonNegativeInt("AI_RETRY_MAX_DELAY_MS", int(cfg.MaxDelay/time.Millisecond))) * time.Millisecond
+	cfg.FailureThreshold = EnvNonNegativeInt("AI_CIRCUIT_FAILURE_THRESHOLD", cfg.FailureThreshold)
+	cfg.OpenDuration = time.Duration(EnvNonNegativeInt("AI_CIRCUIT_OPEN_SECONDS", int(cfg.OpenDuration/time.Second))) * time.Second
+	return cfg
+}
+
+// noRetriesKey is the context key set by WithoutRetries.
+type noRetriesKey struct{}
+
+// WithoutRetries returns a context in which a ResilientReviewer makes a single attempt per
+// call, for callers that retry on their own, such as review jobs. The call timeout and the
+// circuit breaker still apply.
+func WithoutRetries(ctx context.Context) context.Context {
+	return context.WithValue(ctx, noRetriesKey{}, true)
+}
+
+// ResilientReviewer wraps a Reviewer with a per-call timeout, retries with exponential
+// backoff and jitter for rate limits, server errors and timeouts, and a circuit breaker.
+// Other errors, such as invalid requests or reviews that fail validation, are returned at once.
+type ResilientReviewer struct {
+	Reviewer Reviewer
+	config   ResilienceConfig
+	breaker  *CircuitBreaker
+}
+
+// NewResilientReviewer wraps reviewer with the given settings.
+func NewResilientReviewer(reviewer Reviewer, cfg ResilienceConfig) *ResilientReviewer {
+	return &ResilientReviewer{
+		Reviewer: reviewer,
+		config:   cfg,
+		breaker:  NewCircuitBreaker(cfg.FailureThreshold, cfg.OpenDuration),
+	}
+}
+
+// CircuitState returns a snapshot of the circuit breaker for health checks.
+func (r *ResilientReviewer) CircuitState() CircuitBreakerState {
+	return r.breaker.State()
+}
+
+// GetAIReview calls the wrapped reviewer with retries.
+func (r *ResilientReviewer) GetAIReview(ctx context.Context, input ReviewInput) (*ReviewResult, error) {
+	var result *ReviewResult
+	spent, err := r.do(ctx, func(ctx context.Context) (err error) {
+		result, err = r.Reviewer.GetAIReview(ctx, input)
+		return err
+	}, nil)
+	if err == nil {
+		result.Usage.addTokens(spent)
+	}
+	return result, err
+}
+
+// StreamAIReview calls the wrapped reviewer with retries until the first feedback has
+// been reported. After that a retry would report the feedback twice, so it fails instead.
+func (r *ResilientReviewer) StreamAIReview(ctx context.Context, input ReviewInput, onFeedback func(FeedbackDetail)) (*ReviewResult, error) {
+	var result *ReviewResult
+	reported := false
+	spent, err := r.do(ctx, func(ctx context.Context) (err error) {
+		result, err = r.Reviewer.StreamAIReview(ctx, input, func(feedback FeedbackDetail) {
+			reported = true
+			onFeedback(feedback)
+		})
+		return err
+	}, func() bool { return !reported })
+	if err == nil {
+		result.Usage.addTokens(spent)
+	}
+	return result, err
+}
+
+// Rewrite calls the wrapped reviewer with retries.
+func (r *ResilientReviewer) Rewrite(ctx context.Context, input ReviewInput, review *AIReviewResponse) (*RewriteResult, error) {
+	var result *RewriteResult
+	spent, err := r.do(ctx, func(ctx context.Context) (err error) {
+		result, err = r.Reviewer.Rewrite(ctx, input, review)
+		return err
+	}, nil)
+	if err == nil {
+		result.Usage.addTokens(spent)
+	}
+	return result, err
+}
+
+// AskFollowUp calls the wrapped reviewer with retries.
+func (r *ResilientReviewer) AskFollowUp(ctx context.Context, input ReviewInput, turns []InterviewTurn) (*QuestionResult, error) {
+	var result *QuestionResult
+	spent, err := r.do(ctx, func(ctx context.Context) (err error) {
+		result, err = r.Reviewer.AskFollowUp(ctx, input, turns)
+		return err
+	}, nil)
+	if err == nil {
+		result.Usage.addTokens(spent)
+	}
+	return result, err
+}
+
+// ReviewInterview calls the wrapped reviewer with retries.
+func (r *ResilientReviewer) ReviewInterview(ctx context.Context, input ReviewInput, turns []InterviewTurn) (*ReviewResult, error) {
+	var result *ReviewResult
+	spent, err := r.do(ctx, func(ctx context.Context) (err error) {
+		result, err = r.Reviewer.ReviewInterview(ctx, input, turns)
+		return err
+	}, nil)
+	if err == nil {
+		result.Usage.addTokens(spent)
+	}
+	return result, err
+}
+
+// CompareAttempts calls the wrapped reviewer with retries.
+func (r *ResilientReviewer) CompareAttempts(ctx context.Context, input ReviewInput, before, after Attempt) (*ComparisonResult, error) {
+	var result *ComparisonResult
+	spent, err := r.do(ctx, func(ctx context.Context) (err error) {
+		result, err = r.Reviewer.CompareAttempts(ctx, input, before, after)
+		return err
+	}, nil)
+	if err == nil {
+		result.Usage.addTokens(spent)
+	}
+	return result, err
+}
+
+// do runs call until it succeeds, fails with an error that is not retryable, or runs out
+// of retries. canRetry, if not nil, can veto further attempts.
+//
+// Failed attempts may have spent tokens too. On success do returns their usage, to be
+// added to the result's; on failure the returned error carries it in a UsageError.
+func (r *ResilientReviewer) do(ctx context.Context, call func(context.Context) error, canRetry func() bool) (TokenUsage, error) {
+	maxRetries := r.config.MaxRetries
+	if ctx.Value(noRetriesKey{}) != nil {
+		maxRetries = 0
+	}
+	var lastErr error
+	var spent TokenUsage
+	var model string
+	fail := func(err error) (TokenUsage, error) {
+		return TokenUsage{}, withUsage(err, model, spent)
+	}
+	for attempt := 0; ; attempt++ {
+		if err := r.breaker.Allow(); err != nil {
+			// The breaker opened while retrying: the last real error explains more.
+			if lastErr != nil {
+				return fail(lastErr)
+			}
+			return fail(err)
+		}
+
+		callCtx, cancel := ctx, context.CancelFunc(func() {})
+		if r.config.CallTimeout > 0 {
+			callCtx, cancel = context.WithTimeout(ctx, r.config.CallTimeout)
+		}
+		err := call(callCtx)
+		cancel()
+
+		if err == nil {
+			r.breaker.Record(true)
+			return spent, nil
+		}
+		// The usage is summed over the attempts and attached again when giving up.
+		var usageErr *UsageError
+		if errors.As(err, &usageErr) {
+			spent.addTokens(usageErr.Usage)
+			model = usageErr.Model
+			err = usageErr.Err
+		}
+		if ctx.Err() != nil {
+			// The caller gave up; that says nothing about the backend.
+			r.breaker.Abandon()
+			return fail(err)
+		}
+		retryable := isRetryableAIError(err)
+		r.breaker.Record(!retryable)
+		if !retryable || attempt >= maxRetries || (canRetry != nil && !canRetry()) {
+			return fail(err)
+		}
+		lastErr = err
+
+		delay := r.backoff(attempt)
+		log.Printf("AI call failed (attempt %d of %d), retrying in %v: %v", attempt+1, maxRetries+1, delay, err)
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return fail(err)
+		}
+	}
+}
+
+// backoff returns the delay before retry number attempt+1: BaseDelay doubled per attempt,
+// capped at MaxDelay, with jitter so that concurrent callers do not retry in lockstep.
+func (r *ResilientReviewer) backoff(attempt int) time.Duration {
+	delay := r.config.BaseDelay << min(attempt, 16)
+	if r.config.MaxDelay > 0 && (delay > r.config.MaxDelay || delay <= 0) {
+		delay = r.config.MaxDelay
+	}
+	if delay <= 0 {
+		return 0
+	}
+	// Equal jitter: half of the delay is fixed, the other half random.
+	return delay/2 + rand.N(delay/2+1)
+}
+
+// isRetryableAIError reports whether err is worth retrying: rate limits, server errors,
+// per-call timeouts and network errors.
+func isRetryableAIError(err error) bool {
+	var apiErr *openai.APIError
+	if errors.As(err, &apiErr) {
+		return isRetryableStatus(apiErr.HTTPStatusCode)
+	}
+	var requestErr *openai.RequestError
+	if errors.As(err, &requestErr) {
+		return isRetryableStatus(requestErr.HTTPStatusCode)
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+// isRetryableStatus reports whether an HTTP status means the request may succeed later.
+func isRetryableStatus(status int) bool {
+	return status == http.StatusRequestTimeout || status == http.StatusTooManyRequests || status >= http.StatusInternalServerError
+}
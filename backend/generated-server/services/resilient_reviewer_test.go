@@ -0,0 +1,266 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	openai "github.com/sashabaranov/go-openai"
+)
+
+// stubResponse is what the stub server does for one request.
+type stubResponse struct {
+	status int
+	delay  time.Duration
+}
+
+// newStubServer starts an OpenAI-compatible server that answers the n-th request with
+// responses[n], repeating the last one, and replies with content and 15 tokens of usage on 200.
+func newStubServer(t *testing.T, content string, responses ...stubResponse) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := int(requests.Add(1)) - 1
+		resp := responses[min(n, len(responses)-1)]
+		if resp.delay > 0 {
+			select {
+			case <-time.After(resp.delay):
+			case <-r.Context().Done():
+				return
+			}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if resp.status != http.StatusOK {
+			w.WriteHeader(resp.status)
+			_ = json.NewEncoder(w).Encode(map[string]any{"error": map[string]any{"message": http.StatusText(resp.status), "type": "stub"}})
+			return
+		}
+		_ = json.NewEncoder(w).Encode(openai.ChatCompletionResponse{
+			Model: "stub",
+			Usage: openai.Usage{PromptTokens: 10, CompletionTokens: 5, TotalTokens: 15},
+			Choices: []openai.ChatCompletionChoice{{
+				Message:      openai.ChatCompletionMessage{Role: openai.ChatMessageRoleAssistant, Content: content},
+				FinishReason: openai.FinishReasonStop,
+			}},
+		})
+	}))
+	t.Cleanup(server.Close)
+	return server, &requests
+}
+
+// stubReviewInput returns an input and a review the stub can return for it.
+func stubReviewInput(t *testing.T) (ReviewInput, string) {
+	t.Helper()
+	input := ReviewInput{ThemeTitle: "キャッシュ戦略", Content: "Write-Throughはキャッシュとデータベースに同時に書き込みます。", Rubric: DefaultRubric()}
+	result, err := (&FakeReviewer{}).GetAIReview(context.Background(), input)
+	if err != nil {
+		t.Fatalf("FakeReviewer: %v", err)
+	}
+	return input, result.Raw
+}
+
+func testResilienceConfig() ResilienceConfig {
+	return ResilienceConfig{
+		CallTimeout:      200 * time.Millisecond,
+		MaxRetries:       3,
+		BaseDelay:        time.Millisecond,
+		MaxDelay:         5 * time.Millisecond,
+		FailureThreshold: 10,
+		OpenDuration:     time.Minute,
+	}
+}
+
+func TestResilientReviewerRetriesRetryableErrors(t *testing.T) {
+	input, content := stubReviewInput(t)
+	tests := []struct {
+		name      string
+		responses []stubResponse
+	}{
+		{"rate limited", []stubResponse{{status: http.StatusTooManyRequests}, {status: http.StatusTooManyRequests}, {status: http.StatusOK}}},
+		{"server error", []stubResponse{{status: http.StatusInternalServerError}, {status: http.StatusBadGateway}, {status: http.StatusOK}}},
+		{"slow reply", []stubResponse{{status: http.StatusOK, delay: 400 * time.Millisecond}, {status: http.StatusOK}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, requests := newStubServer(t, content, tt.responses...)
+			reviewer := NewResilientReviewer(NewOpenAICompatibleService(server.URL, "", "stub"), testResilienceConfig())
+
+			result, err := reviewer.GetAIReview(context.Background(), input)
+			if err != nil {
+				t.Fatalf("GetAIReview: %v", err)
+			}
+			if result.Review.TotalScore == 0 {
+				t.Error("review has no total score")
+			}
+			if got, want := int(requests.Load()), len(tt.responses); got != want {
+				t.Errorf("requests = %d, want %d", got, want)
+			}
+			if state := reviewer.CircuitState().State; state != CircuitClosed {
+				t.Errorf("circuit = %s, want %s", state, CircuitClosed)
+			}
+		})
+	}
+}
+
+func TestResilientReviewerGivesUpAfterMaxRetries(t *testing.T) {
+	input, content := stubReviewInput(t)
+	server, requests := newStubServer(t, content, stubResponse{status: http.StatusServiceUnavailable})
+	reviewer := NewResilientReviewer(NewOpenAICompatibleService(server.URL, "", "stub"), testResilienceConfig())
+
+	_, err := reviewer.GetAIReview(context.Background(), input)
+	var apiErr *openai.APIError
+	if !errors.As(err, &apiErr) || apiErr.HTTPStatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("err = %v, want a 503 API error", err)
+	}
+	if got := requests.Load(); got != 4 {
+		t.Errorf("requests = %d, want 4", got)
+	}
+}
+
+func TestResilientReviewerWithoutRetries(t *testing.T) {
+	input, content := stubReviewInput(t)
+	server, requests := newStubServer(t, content, stubResponse{status: http.StatusServiceUnavailable}, stubResponse{status: http.StatusOK})
+	reviewer := NewResilientReviewer(NewOpenAICompatibleService(server.URL, "", "stub"), testResilienceConfig())
+
+	// A review job retries on its own, so it makes a single call per attempt.
+	if _, err := reviewer.GetAIReview(WithoutRetries(context.Background()), input); err == nil {
+		t.Fatal("GetAIReview succeeded, want the 503 of the only attempt")
+	}
+	if got := requests.Load(); got != 1 {
+		t.Errorf("requests = %d, want 1", got)
+	}
+	// The failure still counts towards the breaker shared with retrying callers.
+	if failures := reviewer.CircuitState().ConsecutiveFailures; failures != 1 {
+		t.Errorf("consecutive failures = %d, want 1", failures)
+	}
+}
+
+func TestLoadResilienceConfig(t *testing.T) {
+	t.Setenv("AI_CALL_TIMEOUT_SECONDS", "20")
+	t.Setenv("AI_MAX_RETRIES", "0")
+	t.Setenv("AI_RETRY_BASE_DELAY_MS", "-1")
+	t.Setenv("AI_RETRY_MAX_DELAY_MS", "2500")
+	t.Setenv("AI_CIRCUIT_FAILURE_THRESHOLD", "many")
+
+	cfg := LoadResilienceConfig()
+	defaults := DefaultResilienceConfig()
+	want := ResilienceConfig{
+		CallTimeout:      20 * time.Second,
+		MaxRetries:       0,
+		BaseDelay:        defaults.BaseDelay,
+		MaxDelay:         2500 * time.Millisecond,
+		FailureThreshold: defaults.FailureThreshold,
+		OpenDuration:     defaults.OpenDuration,
+	}
+	if cfg != want {
+		t.Errorf("config = %+v, want %+v", cfg, want)
+	}
+}
+
+func TestResilientReviewerReportsUsageOfInvalidReviews(t *testing.T) {
+	input, _ := stubReviewInput(t)
+	server, requests := newStubServer(t, `{"totalScore": 50}`, stubResponse{status: http.StatusOK})
+	reviewer := NewResilientReviewer(NewOpenAICompatibleService(server.URL, "", "stub"), testResilienceConfig())
+
+	_, err := reviewer.GetAIReview(context.Background(), input)
+	if !errors.Is(err, ErrInvalidAIResponse) {
+		t.Fatalf("err = %v, want ErrInvalidAIResponse", err)
+	}
+	usage, model, ok := SpentUsage(err)
+	if !ok {
+		t.Fatal("error carries no usage")
+	}
+	// The first call and every repair attempt are charged.
+	calls := int(requests.Load())
+	if calls != DefaultMaxRepairAttempts+1 {
+		t.Errorf("requests = %d, want %d", calls, DefaultMaxRepairAttempts+1)
+	}
+	if usage.TotalTokens != 15*calls || model != "stub" {
+		t.Errorf("usage = %+v by %q, want %d tokens by stub", usage, model, 15*calls)
+	}
+}
+
+func TestResilientReviewerDoesNotRetryClientErrors(t *testing.T) {
+	input, content := stubReviewInput(t)
+	server, requests := newStubServer(t, content, stubResponse{status: http.StatusBadRequest})
+	reviewer := NewResilientReviewer(NewOpenAICompatibleService(server.URL, "", "stub"), testResilienceConfig())
+
+	if _, err := reviewer.GetAIReview(context.Background(), input); err == nil {
+		t.Fatal("expected an error")
+	}
+	if got := requests.Load(); got != 1 {
+		t.Errorf("requests = %d, want 1", got)
+	}
+	// A bad request means the backend is up, so it must not count towards opening the breaker.
+	if failures := reviewer.CircuitState().ConsecutiveFailures; failures != 0 {
+		t.Errorf("consecutive failures = %d, want 0", failures)
+	}
+}
+
+func TestResilientReviewerCircuitBreaker(t *testing.T) {
+	input, content := stubReviewInput(t)
+	server, requests := newStubServer(t, content,
+		stubResponse{status: http.StatusInternalServerError},
+		stubResponse{status: http.StatusInternalServerError},
+		stubResponse{status: http.StatusOK},
+	)
+	cfg := testResilienceConfig()
+	cfg.MaxRetries = 0
+	cfg.FailureThreshold = 2
+	cfg.OpenDuration = 50 * time.Millisecond
+	reviewer := NewResilientReviewer(NewOpenAICompatibleService(server.URL, "", "stub"), cfg)
+
+	for i := 0; i < 2; i++ {
+		if _, err := reviewer.GetAIReview(context.Background(), input); err == nil {
+			t.Fatalf("call %d: expected an error", i+1)
+		}
+	}
+	state := reviewer.CircuitState()
+	if state.State != CircuitOpen || state.RetryAt == nil {
+		t.Fatalf("circuit = %+v, want open with a retry time", state)
+	}
+
+	// While open, calls fail fast without reaching the server.
+	if _, err := reviewer.GetAIReview(context.Background(), input); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("err = %v, want ErrCircuitOpen", err)
+	}
+	if got := requests.Load(); got != 2 {
+		t.Errorf("requests = %d, want 2", got)
+	}
+
+	// After OpenDuration a trial call goes through, and its success closes the circuit.
+	time.Sleep(cfg.OpenDuration)
+	if state := reviewer.CircuitState().State; state != CircuitHalfOpen {
+		t.Errorf("circuit = %s, want %s", state, CircuitHalfOpen)
+	}
+	if _, err := reviewer.GetAIReview(context.Background(), input); err != nil {
+		t.Fatalf("trial call: %v", err)
+	}
+	if state := reviewer.CircuitState(); state.State != CircuitClosed || state.ConsecutiveFailures != 0 {
+		t.Errorf("circuit = %+v, want closed", state)
+	}
+}
+
+func TestResilientReviewerIgnoresCallerCancellation(t *testing.T) {
+	input, content := stubReviewInput(t)
+	server, _ := newStubServer(t, content, stubResponse{status: http.StatusOK, delay: 400 * time.Millisecond})
+	cfg := testResilienceConfig()
+	cfg.CallTimeout = 0
+	cfg.FailureThreshold = 1
+	reviewer := NewResilientReviewer(NewOpenAICompatibleService(server.URL, "", "stub"), cfg)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := reviewer.GetAIReview(ctx, input); err == nil {
+		t.Fatal("expected an error")
+	}
+	if state := reviewer.CircuitState(); state.State != CircuitClosed || state.ConsecutiveFailures != 0 {
+		t.Errorf("circuit = %+v, want closed without failures", state)
+	}
+}
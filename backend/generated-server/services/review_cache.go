@@ -0,0 +1,43 @@
+package services
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+)
+
+// ReviewCacheKey returns a hash identifying a review input, including the theme's
+// key points and misconceptions. Two inputs with the same key would be sent to the
+// model with the same prompt, so a stored review for one can be reused for the other.
+//
+// The content is normalized first so that edits that do not change the text
+// (line endings, trailing spaces, surrounding blank lines) still hit the cache.
+func ReviewCacheKey(input ReviewInput) string {
+	h := sha256.New()
+	for _, part := range []string{
+		normalizeReviewContent(input.Content),
+		strings.TrimSpace(input.ThemeTitle),
+		strings.TrimSpace(input.ThemeDescription),
+		input.Rubric.PromptVersion(),
+		strings.Join(input.KeyPoints, "\n"),
+		strings.Join(input.Misconceptions, "\n"),
+	} {
+		// hash.Hash never returns an error from Write.
+		_, _ = h.Write([]byte(part))
+		_, _ = h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// normalizeReviewContent unifies line endings and strips trailing whitespace from
+// each line and blank lines around the text. Paragraph breaks are kept because
+// they affect the structure score.
+func normalizeReviewContent(content string) string {
+	content = strings.ReplaceAll(content, "\r\n", "\n")
+	content = strings.ReplaceAll(content, "\r", "\n")
+	lines := strings.Split(content, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " \t　")
+	}
+	return strings.Trim(strings.Join(lines, "\n"), "\n")
+}
@@ -0,0 +1,104 @@
+package services
+
+import (
+	"encoding/hex"
+	"fmt"
+	"testing"
+)
+
+func cacheInput() ReviewInput {
+	return ReviewInput{
+		ThemeTitle:       "キャッシュ戦略",
+		ThemeDescription: "Write-ThroughとWrite-Backを比較してください。",
+		Content:          "キャッシュは速い。\n\n整合性が難しい。",
+		Rubric:           DefaultRubric(),
+		KeyPoints:        []string{"書き込みの順序"},
+		Misconceptions:   []string{"常に速い"},
+	}
+}
+
+func TestReviewCacheKeyFormat(t *testing.T) {
+	// The key is stored in a 64-character column.
+	key := ReviewCacheKey(cacheInput())
+	if _, err := hex.DecodeString(key); err != nil || len(key) != 64 {
+		t.Errorf("key = %q, want 64 hex characters", key)
+	}
+}
+
+func TestReviewCacheKeyIgnoresInvisibleEdits(t *testing.T) {
+	key := ReviewCacheKey(cacheInput())
+	for _, content := range []string{
+		"キャッシュは速い。\r\n\r\n整合性が難しい。",
+		"キャッシュは速い。\r\r整合性が難しい。",
+		"キャッシュは速い。 \t　\n\n整合性が難しい。　",
+		"\n\nキャッシュは速い。\n\n整合性が難しい。\n\n",
+		// The blank line between paragraphs may hold spaces.
+		"キャッシュは速い。\n   \n整合性が難しい。",
+	} {
+		input := cacheInput()
+		input.Content = content
+		if ReviewCacheKey(input) != key {
+			t.Errorf("content %q: key changed, want the same key", content)
+		}
+	}
+}
+
+func TestReviewCacheKeyKeepsLayout(t *testing.T) {
+	// Paragraphs and indentation can change the structure score, so they are part of the key.
+	key := ReviewCacheKey(cacheInput())
+	for _, content := range []string{
+		"キャッシュは速い。\n整合性が難しい。",
+		"キャッシュは速い。\n\n\n整合性が難しい。",
+		"　キャッシュは速い。\n\n整合性が難しい。",
+		"キャッシュは速い。\n\n  整合性が難しい。",
+	} {
+		input := cacheInput()
+		input.Content = content
+		if ReviewCacheKey(input) == key {
+			t.Errorf("content %q: key did not change, want a different key", content)
+		}
+	}
+}
+
+func TestReviewCacheKeySeparatesFields(t *testing.T) {
+	// Moving text from one field to its neighbour changes the prompt, so it must change the key.
+	base := cacheInput()
+	key := ReviewCacheKey(base)
+
+	joined := cacheInput()
+	joined.ThemeTitle = base.ThemeTitle + base.ThemeDescription
+	joined.ThemeDescription = ""
+
+	moved := cacheInput()
+	moved.KeyPoints = nil
+	moved.Misconceptions = append([]string{"書き込みの順序"}, base.Misconceptions...)
+
+	for name, input := range map[string]ReviewInput{"title and description": joined, "key point to misconceptions": moved} {
+		if ReviewCacheKey(input) == key {
+			t.Errorf("%s: key did not change", name)
+		}
+	}
+}
+
+func TestReviewCacheKeyRubricVersion(t *testing.T) {
+	input := cacheInput()
+	key := ReviewCacheKey(input)
+
+	// Editing a rubric creates a new version, whose reviews are not interchangeable.
+	input.Rubric.Version++
+	if ReviewCacheKey(input) == key {
+		t.Error("new rubric version: key did not change")
+	}
+}
+
+func TestReviewCacheKeyPromptTemplate(t *testing.T) {
+	// The prompt version, which is stored with reviews and part of the cache key, changes
+	// with the prompt templates as well as with the rubric.
+	want := fmt.Sprintf("default@v1/p%d", PromptTemplateVersion)
+	if got := DefaultRubric().PromptVersion(); got != want {
+		t.Errorf("PromptVersion() = %q, want %q", got, want)
+	}
+	if got := DefaultRubric().VersionName(); got != "default@v1" {
+		t.Errorf("VersionName() = %q, want %q", got, "default@v1")
+	}
+}
@@ -0,0 +1,103 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// totalScoreTolerance is how far totalScore may drift from the weighted mean of the viewpoint scores.
+const totalScoreTolerance = 5
+
+// ErrInvalidAIResponse is returned (wrapped) when the AI keeps producing a review
+// that fails validation even after repair attempts.
+var ErrInvalidAIResponse = errors.New("AI response failed validation")
+
+// InvalidReviewError describes why an AI review was rejected.
+type InvalidReviewError struct {
+	Attempts int
+	Problems []string
+}
+
+func (e *InvalidReviewError) Error() string {
+	return fmt.Sprintf("%v after %d attempt(s): %s", ErrInvalidAIResponse, e.Attempts, strings.Join(e.Problems, "; "))
+}
+
+// Is reports whether target is ErrInvalidAIResponse, so callers can use errors.Is.
+func (e *InvalidReviewError) Is(target error) bool {
+	return target == ErrInvalidAIResponse
+}
+
+// ValidateAIReview checks a parsed review against the rubric and returns
+// a list of problems. An empty list means the review is valid.
+func ValidateAIReview(review *AIReviewResponse, rubric Rubric) []string {
+	var problems []string
+
+	// Scores: exactly the rubric keys, each in range.
+	for _, vp := range rubric.Viewpoints {
+		score, ok := review.Scores[vp.Key]
+		if !ok {
+			problems = append(problems, fmt.Sprintf("scores.%s is missing", vp.Key))
+			continue
+		}
+		if score < 0 || score > 100 {
+			problems = append(problems, fmt.Sprintf("scores.%s must be between 0 and 100, got %d", vp.Key, score))
+		}
+	}
+	for key := range review.Scores {
+		if vp, ok := rubric.Viewpoint(key); !ok || vp.Key != key {
+			problems = append(problems, fmt.Sprintf("scores.%s is not a rubric viewpoint", key))
+		}
+	}
+
+	// Total score: in range and consistent with the viewpoint scores.
+	if review.TotalScore < 0 || review.TotalScore > 100 {
+		problems = append(problems, fmt.Sprintf("totalScore must be between 0 and 100, got %d", review.TotalScore))
+	} else if len(review.Scores) > 0 {
+		expected := rubric.WeightedTotal(review.Scores)
+		if diff := review.TotalScore - expected; diff > totalScoreTolerance || diff < -totalScoreTolerance {
+			problems = append(problems, fmt.Sprintf("totalScore %d does not match the weighted mean of scores (%d)", review.TotalScore, expected))
+		}
+	}
+
+	// Feedbacks: one per rubric viewpoint, matching the corresponding score.
+	seen := make(map[string]bool, len(rubric.Viewpoints))
+	for i, feedback := range review.Feedbacks {
+		vp, ok := rubric.Viewpoint(feedback.Viewpoint)
+		if !ok {
+			problems = append(problems, fmt.Sprintf("feedbacks[%d].viewpoint %q is not a rubric viewpoint", i, feedback.Viewpoint))
+			continue
+		}
+		if seen[vp.Key] {
+			problems = append(problems, fmt.Sprintf("feedbacks contains %q more than once", vp.Name))
+			continue
+		}
+		seen[vp.Key] = true
+		if feedback.Score < 0 || feedback.Score > 100 {
+			problems = append(problems, fmt.Sprintf("feedbacks[%d].score must be between 0 and 100, got %d", i, feedback.Score))
+		} else if score, ok := review.Scores[vp.Key]; ok && score != feedback.Score {
+			problems = append(problems, fmt.Sprintf("feedbacks[%d].score %d does not match scores.%s (%d)", i, feedback.Score, vp.Key, score))
+		}
+		if strings.TrimSpace(feedback.GoodPoint) == "" || strings.TrimSpace(feedback.BadPoint) == "" {
+			problems = append(problems, fmt.Sprintf("feedbacks[%d] must have both goodPoint and badPoint", i))
+		}
+	}
+	for _, vp := range rubric.Viewpoints {
+		if !seen[vp.Key] {
+			problems = append(problems, fmt.Sprintf("feedbacks is missing %q", vp.Name))
+		}
+	}
+
+	return problems
+}
+
+// repairPrompt asks the model to fix a rejected review.
+func repairPrompt(problems []string) string {
+	return fmt.Sprintf(`
+直前の回答は以下の理由で出力形式の要件を満たしていません。
+
+- %s
+
+指定したJSON形式に厳密に従い、問題を修正したレビュー全体をJSONのみで再出力してください。
+`, strings.Join(problems, "\n- "))
+}
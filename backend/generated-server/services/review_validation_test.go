@@ -0,0 +1,175 @@
+package services
+
+import (
+	"slices"
+	"testing"
+)
+
+// validReview returns a review that passes ValidateAIReview with the default rubric.
+func validReview() *AIReviewResponse {
+	review := &AIReviewResponse{TotalScore: 80, Scores: map[string]int{}}
+	for _, vp := range DefaultRubric().Viewpoints {
+		review.Scores[vp.Key] = 80
+		review.Feedbacks = append(review.Feedbacks, FeedbackDetail{Viewpoint: vp.Key, Score: 80, GoodPoint: "良い点", BadPoint: "改善点"})
+	}
+	return review
+}
+
+func TestValidateAIReview(t *testing.T) {
+	// Feedbacks follow the rubric order: observation, abstraction, vocabulary, structure, perspective.
+	tests := []struct {
+		name   string
+		modify func(r *AIReviewResponse)
+		want   []string
+	}{
+		{"valid", func(r *AIReviewResponse) {}, nil},
+		{"feedback by viewpoint name", func(r *AIReviewResponse) { r.Feedbacks[0].Viewpoint = " 観察・内省力 " }, nil},
+		{"scores at both ends of the range", func(r *AIReviewResponse) {
+			for i, key := range []string{"observation", "abstraction", "vocabulary", "structure", "perspective"} {
+				r.Scores[key] = []int{0, 100, 0, 100, 100}[i]
+				r.Feedbacks[i].Score = r.Scores[key]
+			}
+			r.TotalScore = 60
+		}, nil},
+		{
+			// Feedbacks may name a viewpoint, but scores must be keyed by the rubric key.
+			"score keyed by viewpoint name",
+			func(r *AIReviewResponse) {
+				delete(r.Scores, "observation")
+				r.Scores["観察・内省力"] = 80
+			},
+			[]string{"scores.observation is missing", "scores.観察・内省力 is not a rubric viewpoint"},
+		},
+		{
+			"missing viewpoint score",
+			func(r *AIReviewResponse) { delete(r.Scores, "perspective") },
+			[]string{"scores.perspective is missing"},
+		},
+		{
+			"extra viewpoint score",
+			func(r *AIReviewResponse) { r.Scores["humor"] = 80 },
+			[]string{"scores.humor is not a rubric viewpoint"},
+		},
+		{
+			"score above range",
+			func(r *AIReviewResponse) {
+				r.Scores["structure"] = 120
+				r.Feedbacks[3].Score = 120
+				r.TotalScore = 88
+			},
+			[]string{"scores.structure must be between 0 and 100, got 120", "feedbacks[3].score must be between 0 and 100, got 120"},
+		},
+		{
+			"negative total",
+			func(r *AIReviewResponse) { r.TotalScore = -1 },
+			[]string{"totalScore must be between 0 and 100, got -1"},
+		},
+		{
+			"total mismatch",
+			func(r *AIReviewResponse) { r.TotalScore = 90 },
+			[]string{"totalScore 90 does not match the weighted mean of scores (80)"},
+		},
+		{
+			"feedback score mismatch",
+			func(r *AIReviewResponse) { r.Feedbacks[0].Score = 70 },
+			[]string{"feedbacks[0].score 70 does not match scores.observation (80)"},
+		},
+		{
+			"missing feedback",
+			func(r *AIReviewResponse) { r.Feedbacks = r.Feedbacks[:4] },
+			[]string{`feedbacks is missing "他者視点力"`},
+		},
+		{
+			"duplicate feedback",
+			func(r *AIReviewResponse) { r.Feedbacks = append(r.Feedbacks, r.Feedbacks[0]) },
+			[]string{`feedbacks contains "観察・内省力" more than once`},
+		},
+		{
+			"same feedback by key and by name",
+			func(r *AIReviewResponse) { r.Feedbacks[4].Viewpoint = "観察・内省力" },
+			[]string{`feedbacks contains "観察・内省力" more than once`, `feedbacks is missing "他者視点力"`},
+		},
+		{
+			// A duplicate is reported once and not checked against the score.
+			"duplicate feedback with another score",
+			func(r *AIReviewResponse) {
+				r.Feedbacks = append(r.Feedbacks, FeedbackDetail{Viewpoint: "structure", Score: 10})
+			},
+			[]string{`feedbacks contains "構造化力" more than once`},
+		},
+		{
+			"unknown feedback viewpoint",
+			func(r *AIReviewResponse) { r.Feedbacks[4].Viewpoint = "humor" },
+			[]string{`feedbacks[4].viewpoint "humor" is not a rubric viewpoint`, `feedbacks is missing "他者視点力"`},
+		},
+		{
+			"blank bad point",
+			func(r *AIReviewResponse) { r.Feedbacks[1].BadPoint = " " },
+			[]string{"feedbacks[1] must have both goodPoint and badPoint"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			review := validReview()
+			tt.modify(review)
+			if got := ValidateAIReview(review, DefaultRubric()); !slices.Equal(got, tt.want) {
+				t.Errorf("problems = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateAIReviewTotalTolerance(t *testing.T) {
+	// Every viewpoint scores 80, so the total may be anywhere in 75..85.
+	for total := 70; total <= 90; total++ {
+		review := validReview()
+		review.TotalScore = total
+		problems := ValidateAIReview(review, DefaultRubric())
+		if valid := total >= 75 && total <= 85; valid != (len(problems) == 0) {
+			t.Errorf("totalScore %d: problems = %q, want valid = %v", total, problems, valid)
+		}
+	}
+}
+
+func TestValidateAIReviewWithoutScores(t *testing.T) {
+	// With no scores at all the total cannot be checked, but every score is reported missing.
+	review := validReview()
+	review.Scores = nil
+	review.TotalScore = 3
+	got := ValidateAIReview(review, DefaultRubric())
+	want := []string{
+		"scores.observation is missing",
+		"scores.abstraction is missing",
+		"scores.vocabulary is missing",
+		"scores.structure is missing",
+		"scores.perspective is missing",
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("problems = %q, want %q", got, want)
+	}
+}
+
+func TestValidateAIReviewWeights(t *testing.T) {
+	rubric := Rubric{Slug: "weighted", Version: 1, Viewpoints: []RubricViewpoint{
+		{Key: "structure", Name: "構造化力", Weight: 3},
+		{Key: "vocabulary", Name: "語彙・用語力", Weight: 1},
+	}}
+	review := &AIReviewResponse{
+		TotalScore: 85,
+		Scores:     map[string]int{"structure": 100, "vocabulary": 40},
+		Feedbacks: []FeedbackDetail{
+			{Viewpoint: "structure", Score: 100, GoodPoint: "良い点", BadPoint: "改善点"},
+			{Viewpoint: "vocabulary", Score: 40, GoodPoint: "良い点", BadPoint: "改善点"},
+		},
+	}
+	if problems := ValidateAIReview(review, rubric); len(problems) != 0 {
+		t.Errorf("problems = %q, want none", problems)
+	}
+
+	// The unweighted mean (70) is too far from the weighted one.
+	review.TotalScore = 70
+	want := []string{"totalScore 70 does not match the weighted mean of scores (85)"}
+	if got := ValidateAIReview(review, rubric); !slices.Equal(got, want) {
+		t.Errorf("problems = %q, want %q", got, want)
+	}
+}
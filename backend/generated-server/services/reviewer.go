@@ -0,0 +1,145 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	openai "github.com/sashabaranov/go-openai"
+)
+
+// Reviewer produces structured feedback for a user's writing.
+// Handlers depend on this interface rather than on a concrete AI backend.
+type Reviewer interface {
+	GetAIReview(ctx context.Context, input ReviewInput) (*ReviewResult, error)
+	// StreamAIReview reports each viewpoint's feedback through onFeedback while
+	// the review is being generated, then returns the complete review.
+	StreamAIReview(ctx context.Context, input ReviewInput, onFeedback func(FeedbackDetail)) (*ReviewResult, error)
+	// Rewrite produces an improved version of the writing that addresses the review
+	// while keeping the author's voice and facts. review may be nil.
+	Rewrite(ctx context.Context, input ReviewInput, review *AIReviewResponse) (*RewriteResult, error)
+	// AskFollowUp acts as an interviewer and asks the next question about the writing,
+	// given the questions asked and answered so far.
+	AskFollowUp(ctx context.Context, input ReviewInput, turns []InterviewTurn) (*QuestionResult, error)
+	// ReviewInterview scores the writing together with the interview that followed it.
+	ReviewInterview(ctx context.Context, input ReviewInput, turns []InterviewTurn) (*ReviewResult, error)
+	// CompareAttempts summarizes what improved and what regressed between two writings on the same theme.
+	CompareAttempts(ctx context.Context, input ReviewInput, before, after Attempt) (*ComparisonResult, error)
+}
+
+// Supported values for the AI_REVIEWER environment variable.
+const (
+	ReviewerBackendOpenAI           = "openai"
+	ReviewerBackendOpenAICompatible = "openai-compatible"
+	ReviewerBackendFake             = "fake"
+)
+
+// ReviewerConfig holds the settings used to build a Reviewer.
+type ReviewerConfig struct {
+	Backend string // one of the ReviewerBackend* constants
+	APIKey  string
+	BaseURL string // only used by the OpenAI-compatible backend
+	Model   string
+	// Resilience configures the timeouts, retries and circuit breaker wrapped around the backend.
+	Resilience ResilienceConfig
+}
+
+// LoadReviewerConfig reads the reviewer settings from environment variables.
+//
+// When AI_REVIEWER is not set, the OpenAI backend is used if OPENAI_API_KEY is
+// present, and the fake backend otherwise, so the stack can run without a paid key.
+func LoadReviewerConfig() ReviewerConfig {
+	cfg := ReviewerConfig{
+		Backend: strings.ToLower(strings.TrimSpace(os.Getenv("AI_REVIEWER"))),
+		APIKey:  os.Getenv("OPENAI_API_KEY"),
+		BaseURL: os.Getenv("OPENAI_BASE_URL"),
+		Model:   os.Getenv("OPENAI_MODEL"),
+
+		Resilience: LoadResilienceConfig(),
+	}
+	if cfg.Backend == "" {
+		if cfg.APIKey != "" {
+			cfg.Backend = ReviewerBackendOpenAI
+		} else {
+			log.Println("AI_REVIEWER and OPENAI_API_KEY are not set, falling back to the fake reviewer")
+			cfg.Backend = ReviewerBackendFake
+		}
+	}
+	return cfg
+}
+
+// ModelName is the model name that reviews by the configured backend are recorded with.
+func (cfg ReviewerConfig) ModelName() string {
+	switch cfg.Backend {
+	case ReviewerBackendFake:
+		return FakeModelName
+	case ReviewerBackendOpenAI:
+		if cfg.Model == "" {
+			return openai.GPT4o
+		}
+	}
+	return cfg.Model
+}
+
+// NewReviewer builds the Reviewer selected by the given configuration,
+// wrapped in a ResilientReviewer.
+func NewReviewer(cfg ReviewerConfig) (Reviewer, error) {
+	reviewer, err := newBackendReviewer(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return NewResilientReviewer(reviewer, cfg.Resilience), nil
+}
+
+// newBackendReviewer builds the bare Reviewer for cfg.Backend.
+func newBackendReviewer(cfg ReviewerConfig) (Reviewer, error) {
+	switch cfg.Backend {
+	case ReviewerBackendOpenAI:
+		if cfg.APIKey == "" {
+			return nil, fmt.Errorf("OPENAI_API_KEY environment variable is required for the %q reviewer", cfg.Backend)
+		}
+		return NewOpenAIService(cfg.APIKey, cfg.Model), nil
+	case ReviewerBackendOpenAICompatible:
+		if cfg.BaseURL == "" {
+			return nil, fmt.Errorf("OPENAI_BASE_URL environment variable is required for the %q reviewer", cfg.Backend)
+		}
+		if cfg.Model == "" {
+			return nil, fmt.Errorf("OPENAI_MODEL environment variable is required for the %q reviewer", cfg.Backend)
+		}
+		return NewOpenAICompatibleService(cfg.BaseURL, cfg.APIKey, cfg.Model), nil
+	case ReviewerBackendFake:
+		return &FakeReviewer{}, nil
+	default:
+		return nil, fmt.Errorf("unknown AI_REVIEWER backend %q", cfg.Backend)
+	}
+}
+
+// NewOpenAIService returns a reviewer backed by the official OpenAI API.
+// An empty model falls back to GPT-4o.
+func NewOpenAIService(apiKey, model string) *OpenAIService {
+	if model == "" {
+		model = openai.GPT4o
+	}
+	return &OpenAIService{
+		Client:            openai.NewClient(apiKey),
+		Model:             model,
+		JSONMode:          true,
+		MaxRepairAttempts: DefaultMaxRepairAttempts,
+	}
+}
+
+// NewOpenAICompatibleService returns a reviewer for any server that speaks the
+// OpenAI chat completion API, such as a self-hosted model server.
+// Many of those servers do not support response_format, so JSON mode is left off
+// and the prompt alone asks for JSON output.
+func NewOpenAICompatibleService(baseURL, apiKey, model string) *OpenAIService {
+	config := openai.DefaultConfig(apiKey)
+	config.BaseURL = strings.TrimRight(baseURL, "/")
+	return &OpenAIService{
+		Client:            openai.NewClientWithConfig(config),
+		Model:             model,
+		MaxRepairAttempts: DefaultMaxRepairAttempts,
+	}
+}
@@ -0,0 +1,20 @@
+package services
+
+import "testing"
+
+func TestReviewerConfigModelName(t *testing.T) {
+	tests := []struct {
+		cfg  ReviewerConfig
+		want string
+	}{
+		{ReviewerConfig{Backend: ReviewerBackendFake, Model: "gpt-4o-mini"}, FakeModelName},
+		{ReviewerConfig{Backend: ReviewerBackendOpenAI}, "gpt-4o"},
+		{ReviewerConfig{Backend: ReviewerBackendOpenAI, Model: "gpt-4o-mini"}, "gpt-4o-mini"},
+		{ReviewerConfig{Backend: ReviewerBackendOpenAICompatible, Model: "llama3"}, "llama3"},
+	}
+	for _, tt := range tests {
+		if got := tt.cfg.ModelName(); got != tt.want {
+			t.Errorf("%s backend with model %q: ModelName() = %q, want %q", tt.cfg.Backend, tt.cfg.Model, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	openai "github.com/sashabaranov/go-openai"
+)
+
+// RewriteResult is an improved version of a writing together with metadata about how it was produced.
+type RewriteResult struct {
+	Content string
+	// Raw is the unparsed model output.
+	Raw   string
+	Model string
+	Usage TokenUsage
+}
+
+// aiRewriteResponse defines the structure for the JSON response from the AI.
+type aiRewriteResponse struct {
+	Rewrite string `json:"rewrite"`
+}
+
+// Rewrite asks the model for an improved version of the writing that addresses the review.
+// review may be nil when the writing has not been reviewed yet.
+func (s *OpenAIService) Rewrite(ctx context.Context, input ReviewInput, review *AIReviewResponse) (*RewriteResult, error) {
+	request := openai.ChatCompletionRequest{
+		Model: s.Model,
+		Messages: []openai.ChatCompletionMessage{
+			{Role: openai.ChatMessageRoleSystem, Content: rewriteSystemPrompt + untrustedContentNotice},
+			{Role: openai.ChatMessageRoleUser, Content: buildRewriteUserPrompt(input, review)},
+		},
+	}
+	if s.JSONMode {
+		request.ResponseFormat = &openai.ChatCompletionResponseFormat{
+			Type: openai.ChatCompletionResponseFormatTypeJSONObject,
+		}
+	}
+
+	result := &RewriteResult{Model: s.Model}
+	content, err := s.complete(ctx, request, &result.Model, &result.Usage)
+	if err != nil {
+		return nil, withUsage(err, result.Model, result.Usage)
+	}
+
+	var parsed aiRewriteResponse
+	if err := json.Unmarshal([]byte(extractJSONObject(content)), &parsed); err != nil {
+		return nil, withUsage(fmt.Errorf("%w: rewrite is not valid JSON: %v", ErrInvalidAIResponse, err), result.Model, result.Usage)
+	}
+	if strings.TrimSpace(parsed.Rewrite) == "" {
+		return nil, withUsage(fmt.Errorf("%w: rewrite is empty", ErrInvalidAIResponse), result.Model, result.Usage)
+	}
+	result.Content = strings.TrimSpace(parsed.Rewrite)
+	result.Raw = content
+	return result, nil
+}
+
+const rewriteSystemPrompt = `
+あなたは、ユーザーが技術的な事柄を言語化する能力を向上させるための、世界クラスのソフトウェアエンジニアリングコーチです。
+ユーザーの文章を、レビューでの指摘を踏まえてより良い回答に書き直してください。
+
+## 書き直しのルール
+- ユーザー自身の経験・事実・主張は変えず、書かれていない経験や数値を創作しないでください。
+- 文体や一人称など、ユーザーの語り口をできるだけ残してください。
+- 構成の整理、具体例と抽象化のつなぎ、用語の正確さ、読み手の疑問への先回りを改善してください。
+- 元の文章と比較しやすいよう、変える必要のない文はそのまま残してください。
+
+## 出力形式 (JSON)
+{
+  "rewrite": "<書き直した文章>"
+}
+`
+
+// buildRewriteUserPrompt renders the writing and its review for a rewrite request.
+func buildRewriteUserPrompt(input ReviewInput, review *AIReviewResponse) string {
+	theme := input.themeText()
+
+	var feedback strings.Builder
+	if review != nil {
+		for _, fb := range review.Feedbacks {
+			fmt.Fprintf(&feedback, "- %s（%d点）: 良い点「%s」／改善点「%s」\n", fb.Viewpoint, fb.Score, fb.GoodPoint, fb.BadPoint)
+		}
+		for _, a := range review.Annotations {
+			fmt.Fprintf(&feedback, "- 「%s」: %s\n", a.Quote, a.Suggestion)
+		}
+	}
+	if feedback.Len() == 0 {
+		feedback.WriteString("（レビューはまだありません）\n")
+	}
+
+	return fmt.Sprintf(`
+以下のテーマについて書かれた文章を、レビュー結果を踏まえて書き直してください。
+
+## テーマ
+%s
+
+## 文章
+%s
+
+## レビュー結果
+%s`, theme, fenceUserContent(input.Content), feedback.String())
+}
@@ -0,0 +1,200 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+)
+
+// RubricViewpoint is one evaluation viewpoint of a rubric.
+type RubricViewpoint struct {
+	Key         string  // score key in the AI response, e.g. "structure"
+	Name        string  // display name used in feedbacks, e.g. "構造化力"
+	Description string  // what the reviewer should look for
+	Weight      float64 // relative weight in the total score
+}
+
+// Rubric defines how a writing is evaluated: its viewpoints and their weights.
+type Rubric struct {
+	ID         uint // database ID; 0 for the built-in default rubric
+	Slug       string
+	Version    int
+	Name       string
+	Viewpoints []RubricViewpoint
+}
+
+// DefaultRubric returns the built-in rubric for technical explanations.
+// It is seeded into the database and used when no rubric is stored.
+func DefaultRubric() Rubric {
+	return Rubric{
+		Slug:    "default",
+		Version: 1,
+		Name:    "技術説明（標準）",
+		Viewpoints: []RubricViewpoint{
+			{Key: "observation", Name: "観察・内省力", Description: "自身の経験や、その時の思考プロセスを深く掘り下げて語れているか？", Weight: 1},
+			{Key: "abstraction", Name: "具体⇄抽象力", Description: "技術的な概念と、それを説明するための具体的な実例のバランスは取れているか？", Weight: 1},
+			{Key: "vocabulary", Name: "語彙・用語力", Description: "技術用語や比喩を正確かつ効果的に使えているか？", Weight: 1},
+			{Key: "structure", Name: "構造化力", Description: "PREP法やSDS法など、論理的で分かりやすい構造で文章が展開されているか？", Weight: 1},
+			{Key: "perspective", Name: "他者視点力", Description: "読み手（例：面接官）が持つであろう疑問を予測し、それに答える形で説明できているか？", Weight: 1},
+		},
+	}
+}
+
+// PromptTemplateVersion is the version of the prompt templates that rubrics are filled into.
+// Bump it whenever a prompt changes in a way that can change reviews, such as how user
+// content is fenced, so that cached reviews made with the old prompts are not reused.
+const PromptTemplateVersion = 2
+
+// VersionName identifies the rubric version, e.g. "default@v1".
+func (r Rubric) VersionName() string {
+	return fmt.Sprintf("%s@v%d", r.Slug, r.Version)
+}
+
+// PromptVersion identifies the rubric version and prompt templates a review was produced
+// with, e.g. "default@v1/p2".
+func (r Rubric) PromptVersion() string {
+	return fmt.Sprintf("%s/p%d", r.VersionName(), PromptTemplateVersion)
+}
+
+// Viewpoint looks up a viewpoint by its key or display name.
+func (r Rubric) Viewpoint(keyOrName string) (RubricViewpoint, bool) {
+	keyOrName = strings.TrimSpace(keyOrName)
+	for _, vp := range r.Viewpoints {
+		if vp.Key == keyOrName || vp.Name == keyOrName {
+			return vp, true
+		}
+	}
+	return RubricViewpoint{}, false
+}
+
+// WeightedTotal computes the total score from per-viewpoint scores using the rubric weights.
+func (r Rubric) WeightedTotal(scores map[string]int) int {
+	var sum, weights float64
+	for _, vp := range r.Viewpoints {
+		score, ok := scores[vp.Key]
+		if !ok {
+			continue
+		}
+		weight := vp.Weight
+		if weight <= 0 {
+			weight = 1
+		}
+		sum += float64(score) * weight
+		weights += weight
+	}
+	if weights == 0 {
+		return 0
+	}
+	return int(sum/weights + 0.5)
+}
+
+// Validate checks that the rubric can be used to build a prompt and validate reviews.
+func (r Rubric) Validate() error {
+	if strings.TrimSpace(r.Name) == "" {
+		return fmt.Errorf("rubric name is required")
+	}
+	if len(r.Viewpoints) == 0 {
+		return fmt.Errorf("rubric must have at least one viewpoint")
+	}
+	keys := make(map[string]bool, len(r.Viewpoints))
+	names := make(map[string]bool, len(r.Viewpoints))
+	for i, vp := range r.Viewpoints {
+		if !isRubricKey(vp.Key) {
+			return fmt.Errorf("viewpoints[%d].key must be lower snake case, got %q", i, vp.Key)
+		}
+		if strings.TrimSpace(vp.Name) == "" {
+			return fmt.Errorf("viewpoints[%d].name is required", i)
+		}
+		if keys[vp.Key] || names[vp.Name] {
+			return fmt.Errorf("viewpoints[%d] duplicates another viewpoint", i)
+		}
+		if vp.Weight < 0 {
+			return fmt.Errorf("viewpoints[%d].weight must not be negative", i)
+		}
+		keys[vp.Key] = true
+		names[vp.Name] = true
+	}
+	return nil
+}
+
+// isRubricKey reports whether s is a lower snake case identifier.
+func isRubricKey(s string) bool {
+	if s == "" || s[0] < 'a' || s[0] > 'z' {
+		return false
+	}
+	for _, ch := range s {
+		if (ch < 'a' || ch > 'z') && (ch < '0' || ch > '9') && ch != '_' {
+			return false
+		}
+	}
+	return true
+}
+
+// buildReviewSystemPrompt renders the system prompt for a rubric.
+func buildReviewSystemPrompt(rubric Rubric) string {
+	// Weights are only spelled out when they differ between viewpoints.
+	weighted := false
+	for _, vp := range rubric.Viewpoints {
+		if vp.Weight != rubric.Viewpoints[0].Weight {
+			weighted = true
+		}
+	}
+
+	var viewpoints, scores, feedbackNames strings.Builder
+	for i, vp := range rubric.Viewpoints {
+		if weighted {
+			fmt.Fprintf(&viewpoints, "%d. %s（重み %g）：%s\n", i+1, vp.Name, vp.Weight, vp.Description)
+		} else {
+			fmt.Fprintf(&viewpoints, "%d. %s：%s\n", i+1, vp.Name, vp.Description)
+		}
+		separator := ","
+		if i == len(rubric.Viewpoints)-1 {
+			separator = ""
+		}
+		fmt.Fprintf(&scores, "    \"%s\": <%sのスコア>%s\n", vp.Key, vp.Name, separator)
+		if i > 0 {
+			feedbackNames.WriteString("、")
+		}
+		feedbackNames.WriteString(vp.Name)
+	}
+
+	return fmt.Sprintf(`
+あなたは、ユーザーが技術的な事柄を言語化する能力を向上させるための、世界クラスのソフトウェアエンジニアリングコーチです。
+ユーザーが入力した文章をレビューし、以下の%dつの観点で評価してください。
+評価結果は必ず下記のJSON形式で返却してください。各観点のスコアと全体スコアは0-100の整数値で、全体スコアは各観点のスコアの（重み付き）平均です。フィードバックは具体的で、学習者が次何をすべきかわかるように記述してください。
+
+## 評価観点
+%s
+## 出力形式 (JSON)
+{
+  "totalScore": <0-100の全体スコア>,
+  "scores": {
+%s  },
+  "feedbacks": [
+    {
+      "viewpoint": "%s",
+      "score": <スコア>,
+      "goodPoint": "<良かった点>",
+      "badPoint": "<改善点>"
+    },
+    // ... 全ての観点（%s）について同様に記述
+  ],
+  "annotations": [
+    {
+      "start": <指摘箇所の開始位置>,
+      "end": <指摘箇所の終了位置>,
+      "quote": "<指摘箇所の文章をそのまま引用>",
+      "viewpoint": "<観点名>",
+      "severity": "<info | warning | critical>",
+      "suggestion": "<具体的な書き換え案や改善方法>"
+    }
+  ]
+}
+
+## annotations について
+- 改善点や良い点が特定の文・語句に関するものである場合、その箇所を annotations に挙げてください（最大%d件、該当がなければ空配列）。
+- start と end は文章の先頭を0とする文字数で、end の位置の文字は含みません。
+- quote は文章中の該当箇所を一字一句そのまま引用してください。
+- severity は info（参考）、warning（改善推奨）、critical（誤りや重大な問題）のいずれかです。
+- quote と start・end には <user_writing> タグを含めず、タグの内側の文章だけを数えてください。
+`, len(rubric.Viewpoints), viewpoints.String(), scores.String(), rubric.Viewpoints[0].Name, feedbackNames.String(), maxAnnotations) + untrustedContentNotice
+}
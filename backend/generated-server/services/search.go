@@ -0,0 +1,132 @@
+package services
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// TextRange is a span of text in character (rune) offsets; End is exclusive.
+type TextRange struct {
+	Start int
+	End   int
+}
+
+// Snippet is an excerpt of a text around the matches of a search query.
+type Snippet struct {
+	Text string
+	// Highlights are the matches within Text.
+	Highlights []TextRange
+}
+
+// FeedbackSearchText flattens the good and bad points of a review into one line per
+// point, prefixed with the viewpoint, so that feedback can be searched as text.
+func FeedbackSearchText(review *AIReviewResponse) string {
+	if review == nil {
+		return ""
+	}
+	var lines []string
+	for _, f := range review.Feedbacks {
+		for _, point := range []string{f.GoodPoint, f.BadPoint} {
+			if point = strings.TrimSpace(point); point != "" {
+				lines = append(lines, f.Viewpoint+": "+point)
+			}
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// HighlightSnippet finds the terms of query in text, case-insensitively, and cuts a snippet
+// of about radius characters on each side of the first match. A term that does not occur
+// as a whole is matched by its two-character pieces, like an ngram full-text index does.
+// It returns false if nothing matches.
+func HighlightSnippet(text, query string, radius int) (Snippet, bool) {
+	runes := []rune(text)
+	folded := foldRunes(runes)
+
+	var matches []TextRange
+	for _, term := range strings.Fields(query) {
+		termRunes := foldRunes([]rune(term))
+		found := findAll(folded, termRunes)
+		if len(found) == 0 && len(termRunes) > 2 {
+			for i := 0; i+2 <= len(termRunes); i++ {
+				found = append(found, findAll(folded, termRunes[i:i+2])...)
+			}
+		}
+		matches = append(matches, found...)
+	}
+	if len(matches) == 0 {
+		return Snippet{}, false
+	}
+	matches = mergeRanges(matches)
+
+	first := matches[0]
+	start := max(0, first.Start-radius)
+	end := min(len(runes), first.End+radius)
+
+	var b strings.Builder
+	offset := -start
+	if start > 0 {
+		b.WriteString("…")
+		offset++
+	}
+	b.WriteString(string(runes[start:end]))
+	if end < len(runes) {
+		b.WriteString("…")
+	}
+
+	snippet := Snippet{Text: b.String()}
+	for _, m := range matches {
+		if m.Start >= end {
+			break
+		}
+		snippet.Highlights = append(snippet.Highlights, TextRange{
+			Start: max(m.Start, start) + offset,
+			End:   min(m.End, end) + offset,
+		})
+	}
+	return snippet, true
+}
+
+// foldRunes lower-cases runes one by one, so that offsets stay the same.
+func foldRunes(runes []rune) []rune {
+	folded := make([]rune, len(runes))
+	for i, r := range runes {
+		folded[i] = unicode.ToLower(r)
+	}
+	return folded
+}
+
+// findAll returns the non-overlapping occurrences of term in text.
+func findAll(text, term []rune) []TextRange {
+	if len(term) == 0 {
+		return nil
+	}
+	var found []TextRange
+	for i := 0; i+len(term) <= len(text); {
+		if slices.Equal(text[i:i+len(term)], term) {
+			found = append(found, TextRange{Start: i, End: i + len(term)})
+			i += len(term)
+			continue
+		}
+		i++
+	}
+	return found
+}
+
+// mergeRanges sorts ranges and merges the ones that overlap or touch.
+func mergeRanges(ranges []TextRange) []TextRange {
+	sorted := slices.Clone(ranges)
+	slices.SortFunc(sorted, func(a, b TextRange) int { return cmp.Compare(a.Start, b.Start) })
+	merged := sorted[:1]
+	for _, r := range sorted[1:] {
+		last := &merged[len(merged)-1]
+		if r.Start <= last.End {
+			last.End = max(last.End, r.End)
+			continue
+		}
+		merged = append(merged, r)
+	}
+	return merged
+}
@@ -0,0 +1,96 @@
+package services
+
+import (
+	"slices"
+	"testing"
+)
+
+// highlighted returns the highlighted parts of a snippet.
+func highlighted(s Snippet) []string {
+	runes := []rune(s.Text)
+	var parts []string
+	for _, h := range s.Highlights {
+		parts = append(parts, string(runes[h.Start:h.End]))
+	}
+	return parts
+}
+
+func TestHighlightSnippetOffsets(t *testing.T) {
+	// Offsets count runes, and the leading ellipsis shifts them by one.
+	s, ok := HighlightSnippet("あいうえおかきくけこRedisさしすせそ", "REDIS", 3)
+	if !ok || s.Text != "…くけこRedisさしす…" {
+		t.Fatalf("snippet = %q, %v, want it cut 3 characters around the match", s.Text, ok)
+	}
+	if want := []TextRange{{4, 9}}; !slices.Equal(s.Highlights, want) {
+		t.Errorf("highlights = %v, want %v", s.Highlights, want)
+	}
+
+	// A match at either end of the text gets no ellipsis on that side.
+	s, _ = HighlightSnippet("Redisを使う", "redis", 0)
+	if s.Text != "Redis…" || !slices.Equal(s.Highlights, []TextRange{{0, 5}}) {
+		t.Errorf("snippet at the start = %+v", s)
+	}
+	s, _ = HighlightSnippet("使うのはRedis", "redis", 1)
+	if s.Text != "…はRedis" || !slices.Equal(highlighted(s), []string{"Redis"}) {
+		t.Errorf("snippet at the end = %+v", s)
+	}
+}
+
+func TestHighlightSnippetMergesMatches(t *testing.T) {
+	for query, want := range map[string][]string{
+		"cache ache":   {"cache"},       // overlapping terms
+		"write back":   {"writeback"},   // touching terms
+		"back write":   {"writeback"},   // in any order
+		"write ch":     {"write", "ch"}, // apart
+		"writeback wr": {"writeback"},   // contained
+	} {
+		s, ok := HighlightSnippet("writeback cache", query, 100)
+		if got := highlighted(s); !ok || !slices.Equal(got, want) {
+			t.Errorf("query %q: highlighted %q, want %q", query, got, want)
+		}
+	}
+}
+
+func TestHighlightSnippetTwoCharacterPieces(t *testing.T) {
+	// Only a term that does not occur as a whole is split.
+	s, _ := HighlightSnippet("キャッシュとキャッシング", "キャッシュ", 100)
+	if got := highlighted(s); !slices.Equal(got, []string{"キャッシュ"}) {
+		t.Errorf("whole term: highlighted %q, want only the whole match", got)
+	}
+	s, _ = HighlightSnippet("キャッシュ戦略", "キャッシング", 100)
+	if got := highlighted(s); !slices.Equal(got, []string{"キャッシ"}) {
+		t.Errorf("split term: highlighted %q, want the matching pieces merged", got)
+	}
+
+	// Pieces of a term of two characters or less would be single characters.
+	for _, query := range []string{"キン", "ン", "ュン ャン", "  "} {
+		if s, ok := HighlightSnippet("キャッシュ", query, 100); ok {
+			t.Errorf("query %q: snippet %+v, want no match", query, s)
+		}
+	}
+}
+
+func TestHighlightSnippetClipsAtTheEnd(t *testing.T) {
+	// Matches past the snippet are left out, and one across its end is clipped.
+	s, ok := HighlightSnippet("キャッシュとデータベースとキャッシュ", "キャッシュ データベース", 2)
+	if !ok || s.Text != "キャッシュとデ…" {
+		t.Fatalf("snippet = %q, %v", s.Text, ok)
+	}
+	if want := []TextRange{{0, 5}, {6, 7}}; !slices.Equal(s.Highlights, want) {
+		t.Errorf("highlights = %v, want %v", s.Highlights, want)
+	}
+}
+
+func TestFeedbackSearchText(t *testing.T) {
+	if got := FeedbackSearchText(nil); got != "" {
+		t.Errorf("FeedbackSearchText(nil) = %q, want empty", got)
+	}
+	review := &AIReviewResponse{Feedbacks: []FeedbackDetail{
+		{Viewpoint: "論理構成", GoodPoint: " 結論が先にある \n", BadPoint: "  "},
+		{Viewpoint: "用語", BadPoint: "ACIDの説明がない"},
+	}}
+	want := "論理構成: 結論が先にある\n用語: ACIDの説明がない"
+	if got := FeedbackSearchText(review); got != want {
+		t.Errorf("FeedbackSearchText = %q, want %q", got, want)
+	}
+}
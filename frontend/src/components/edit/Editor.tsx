@@ -3,9 +3,9 @@
 import { useState } from 'react';
 import { useRouter } from 'next/navigation';
 import { useAuthStore } from '@/store/auth';
-import { createWriting } from '@/lib/api/writings.client';
+import { createWriting, startWritingSession } from '@/lib/api/writings.client';
 import { useTimer } from '@/hooks/useTimer';
-import { Pause, Play } from 'lucide-react';
+import { Play } from 'lucide-react';
 import Button from '../atoms/Button';
 
 interface EditorProps {
@@ -24,17 +24,40 @@ export default function Editor({
   const [content, setContent] = useState('');
   const [isLoading, setIsLoading] = useState(false);
   const [error, setError] = useState<string | null>(null);
+  const [sessionId, setSessionId] = useState<number | null>(null);
   const {
     timerState,
-    elapsedSeconds,
     remainingSeconds,
     formattedTime,
     start,
-    pause,
-    resume,
     reset,
   } = useTimer({ timeLimitInSeconds });
 
+  // The server times the writing from the start of its session, so a session is
+  // started together with the timer, which cannot be paused. A reset starts a new
+  // session next time.
+  const handleStart = async () => {
+    setError(null);
+    if (!token) {
+      setError('Authentication error. Please log in again.');
+      return;
+    }
+    try {
+      const session = await startWritingSession(themeId, token);
+      setSessionId(session.id);
+      start();
+    } catch (err) {
+      setError(
+        err instanceof Error ? err.message : 'An unexpected error occurred.'
+      );
+    }
+  };
+
+  const handleReset = () => {
+    setSessionId(null);
+    reset();
+  };
+
   const handleSubmit = async (e: React.FormEvent) => {
     e.preventDefault();
     setError(null);
@@ -45,14 +68,19 @@ export default function Editor({
       setIsLoading(false);
       return;
     }
+    if (sessionId === null) {
+      setError('Start the timer before writing.');
+      setIsLoading(false);
+      return;
+    }
 
     try {
       // 記述内容を記録として保存する
       const newWriting = await createWriting(
         {
           themeId: themeId,
+          sessionId: sessionId,
           content: content,
-          durationSeconds: elapsedSeconds,
         },
         token
       );
@@ -70,42 +98,17 @@ export default function Editor({
   const renderTimerControls = () => {
     switch (timerState) {
       case 'running':
-        return (
-          <Button
-            onClick={pause}
-            variant="secondary"
-            className="rounded-full p-2"
-            aria-label="一時停止"
-          >
-            <Pause className="h-5 w-5" />
-          </Button>
-        );
-      case 'paused':
-        return (
-          <div className="flex items-center space-x-2">
-            <Button
-              onClick={resume}
-              variant="primary"
-              className="rounded-full p-2"
-              aria-label="再開"
-            >
-              <Play className="h-5 w-5" />
-            </Button>
-            <Button onClick={reset} variant="outline">
-              リセット
-            </Button>
-          </div>
-        );
+        return null;
       case 'finished':
         return (
-          <Button onClick={reset} variant="outline">
+          <Button onClick={handleReset} variant="outline">
             リセット
           </Button>
         );
       default: // idle
         return (
           <Button
-            onClick={start}
+            onClick={handleStart}
             variant="primary"
             className="rounded-full p-2"
             aria-label="開始"
@@ -155,7 +158,7 @@ export default function Editor({
       <div className="mt-6 flex justify-end">
         <Button
           type="submit"
-          disabled={isLoading || !content.trim() || timerState === 'running'}
+          disabled={isLoading || !content.trim() || timerState === 'idle'}
           className="rounded-md bg-blue-600 px-6 py-3 font-semibold text-white shadow-sm hover:bg-blue-700 disabled:cursor-not-allowed disabled:bg-blue-400"
         >
           {isLoading ? '保存中...' : 'この内容で記録する'}
@@ -2,8 +2,43 @@ import {
   NewReviewRequest,
   NewWritingRequest,
   Writing,
+  WritingSession,
 } from '@/types/generated/api';
 import { PUBLIC_API_BASE_URL } from './config';
+
+/**
+ * Starts a timed writing session on a theme. The server records the start
+ * time and measures the duration of the writing created with the session.
+ * Requires authentication.
+ * @param themeId - The ID of the theme to write on.
+ * @param token - The user's JWT for authorization.
+ * @returns A promise that resolves to the started session.
+ */
+export async function startWritingSession(
+  themeId: number,
+  token: string
+): Promise<WritingSession> {
+  const res = await fetch(
+    `${PUBLIC_API_BASE_URL}/themes/${themeId}/sessions`,
+    {
+      method: 'POST',
+      headers: {
+        Authorization: `Bearer ${token}`,
+      },
+    }
+  );
+
+  const data = await res.json();
+
+  if (!res.ok) {
+    throw new Error(
+      (data as { message: string }).message || 'Failed to start session'
+    );
+  }
+
+  return data as WritingSession;
+}
+
 /**
  * Submits a new writing to the backend.
  * This function is designed to be called from the client-side and requires authentication.
@@ -205,6 +205,12 @@ export interface NewWritingRequest {
      * @memberof NewWritingRequest
      */
     'themeId': number;
+    /**
+     * The session started with POST /themes/{themeId}/sessions.
+     * @type {number}
+     * @memberof NewWritingRequest
+     */
+    'sessionId': number;
     /**
      * 
      * @type {string}
@@ -212,11 +218,11 @@ export interface NewWritingRequest {
      */
     'content': string;
     /**
-     * 
+     * Ignored. The server measures the duration from the session start.
      * @type {number}
      * @memberof NewWritingRequest
      */
-    'durationSeconds': number;
+    'durationSeconds'?: number;
 }
 /**
  * 
@@ -423,6 +429,49 @@ export interface User {
      */
     'updatedAt': string;
 }
+/**
+ * 
+ * @export
+ * @interface WritingSession
+ */
+export interface WritingSession {
+    /**
+     * 
+     * @type {number}
+     * @memberof WritingSession
+     */
+    'id': number;
+    /**
+     * 
+     * @type {number}
+     * @memberof WritingSession
+     */
+    'themeId': number;
+    /**
+     * 
+     * @type {string}
+     * @memberof WritingSession
+     */
+    'startedAt': string;
+    /**
+     * The theme\'s time limit; 0 means no limit.
+     * @type {number}
+     * @memberof WritingSession
+     */
+    'timeLimitSeconds': number;
+    /**
+     * The deadline for creating the writing of this session.
+     * @type {string}
+     * @memberof WritingSession
+     */
+    'expiresAt': string;
+    /**
+     * 
+     * @type {number}
+     * @memberof WritingSession
+     */
+    'writingId'?: number;
+}
 /**
  * 
  * @export